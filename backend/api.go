@@ -7,6 +7,7 @@ import (
 
 	"log"
 	"strings"
+	"time"
 
 	"github.com/alice-lg/alice-lg/backend/api"
 
@@ -22,16 +23,39 @@ import (
 //
 //   Config
 //     Show         /api/v1/config
+//     Communities  /api/v1/communities?q=<label>
+//     Sources      /api/v1/sources
 //
 //   Routeservers
 //     List         /api/v1/routeservers
 //     Status       /api/v1/routeservers/:id/status
 //     Neighbors    /api/v1/routeservers/:id/neighbors
 //     Routes       /api/v1/routeservers/:id/neighbors/:neighborId/routes
+//     RoutesDiff   /api/v1/routeservers/:id/neighbors/:neighborId/routes/diff
+//     AllRoutes    /api/v1/routeservers/:id/routes
+//     Covered      /api/v1/routeservers/:id/covered-routes
+//     ExportMrt    /api/v1/routeservers/:id/export/mrt
+//     Empty        /api/v1/routeservers/:id/empty-neighbors
+//     Duplicates   /api/v1/routeservers/:id/duplicate-paths
+//     Candidates   /api/v1/routeservers/:id/reject-candidates
+//     Rpki         /api/v1/routeservers/:id/rpki-summary
+//     Moas         /api/v1/routeservers/:id/moas-prefixes
+//     OriginAsns   /api/v1/routeservers/:id/origin-asns?expand=<asn>
+//     Origins      /api/v1/routeservers/:id/origin-changes
 //
 //   Querying
-//     LookupPrefix   /api/v1/lookup/prefix?q=<prefix>
-//     LookupNeighbor /api/v1/lookup/neighbor?asn=1235
+//     LookupPrefix      /api/v1/lookup/prefix?q=<prefix>
+//     StreamPrefix      /api/v1/lookup/prefix/stream?q=<prefix>
+//     LookupNeighbor    /api/v1/lookup/neighbor?asn=1235
+//     LookupDestination /api/v1/lookup/destination?q=<ip>
+//     LookupOrigin      /api/v1/lookup/origin?asn=<asn>
+//     LookupNextHop     /api/v1/lookup/next-hop?q=<ip or cidr>
+//     EmptyNeighbors    /api/v1/lookup/neighbors/empty
+//     NeighborsStates   /api/v1/lookup/neighbors/states
+//     NeighborsGroups   /api/v1/lookup/neighbors/groups?group=<group>
+//     RpkiSummary       /api/v1/lookup/rpki-summary
+//     AfiCounts         /api/v1/lookup/afi-counts
+//     MoasPrefixes      /api/v1/lookup/moas-prefixes
 
 type apiEndpoint func(*http.Request, httprouter.Params) (api.Response, error)
 
@@ -40,23 +64,37 @@ func endpoint(wrapped apiEndpoint) httprouter.Handle {
 	return func(res http.ResponseWriter,
 		req *http.Request,
 		params httprouter.Params) {
-
-		// Get result from handler
-		result, err := wrapped(req, params)
+		t0 := time.Now()
+
+		// Reject unknown query parameters in strict mode,
+		// otherwise get result from handler
+		var result api.Response
+		var err error
+		if AliceConfig.Server.StrictParams {
+			err = validateQueryParams(req)
+		}
+		if err == nil {
+			err = apiLimitRequestBody(
+				req, AliceConfig.Server.MaxRequestBodySize)
+		}
+		if err == nil {
+			timeout := time.Duration(
+				AliceConfig.Server.RequestTimeout) * time.Second
+			result, err = apiHandleWithTimeout(
+				wrapped, timeout, req, params)
+		}
 		if err != nil {
-			// Get affected rs id
-			rsId, paramErr := validateSourceId(params.ByName("id"))
-			if paramErr != nil {
-				rsId = "unknown"
+			status := apiWriteError(res, params, err)
+			if AliceConfig.Server.AccessLog {
+				apiLogAccess(req, status, time.Since(t0))
 			}
-
-			// Make error response
-			result, status := apiErrorResponse(rsId, err)
-			payload, _ := json.Marshal(result)
-			http.Error(res, string(payload), status)
 			return
 		}
 
+		if AliceConfig.Server.AccessLog {
+			apiLogAccess(req, http.StatusOK, time.Since(t0))
+		}
+
 		// Encode json
 		payload, err := json.Marshal(result)
 		if err != nil {
@@ -70,6 +108,9 @@ func endpoint(wrapped apiEndpoint) httprouter.Handle {
 
 		// Set response header
 		res.Header().Set("Content-Type", "application/json")
+		if AliceConfig.Server.IncludeConfigHash {
+			res.Header().Set(CONFIG_HASH_HEADER, AliceConfig.SourcesHash)
+		}
 
 		// Check if compression is supported
 		if strings.Contains(req.Header.Get("Accept-Encoding"), "gzip") {
@@ -84,35 +125,124 @@ func endpoint(wrapped apiEndpoint) httprouter.Handle {
 	}
 }
 
+// Write the error response of a request, the
+// status of the response is returned.
+func apiWriteError(
+	res http.ResponseWriter,
+	params httprouter.Params,
+	err error,
+) int {
+	// Get affected rs id
+	rsId, paramErr := validateSourceId(params.ByName("id"))
+	if paramErr != nil {
+		rsId = "unknown"
+	}
+
+	// Clients may retry when the service is available
+	if e, ok := err.(*ServiceUnavailableError); ok {
+		res.Header().Set("Retry-After", e.RetryAfterSeconds())
+	}
+
+	// Make error response
+	result, status := apiErrorResponse(rsId, err)
+	payload, _ := json.Marshal(result)
+	http.Error(res, string(payload), status)
+
+	return status
+}
+
 // Register api endpoints
 func apiRegisterEndpoints(router *httprouter.Router) error {
+	// Expensive endpoints share a limit of concurrent requests
+	limiter := newApiRequestLimiter(AliceConfig.Server)
+
+	// Responses are cached with a TTL per endpoint group
+	cache := newResponseCache(AliceConfig.ResponseCache)
+	status := func(h apiEndpoint) apiEndpoint {
+		return cachedEndpoint(cache, RESPONSE_CACHE_STATUS, h)
+	}
+	neighbours := func(h apiEndpoint) apiEndpoint {
+		return cachedEndpoint(cache, RESPONSE_CACHE_NEIGHBOURS, h)
+	}
+	routes := func(h apiEndpoint) apiEndpoint {
+		return cachedEndpoint(cache, RESPONSE_CACHE_ROUTES, h)
+	}
+	lookup := func(h apiEndpoint) apiEndpoint {
+		return cachedEndpoint(cache, RESPONSE_CACHE_LOOKUP, h)
+	}
 
 	// Meta
-	router.GET("/api/v1/status", endpoint(apiStatusShow))
+	router.GET("/api/v1/status", endpoint(status(apiStatusShow)))
 	router.GET("/api/v1/config", endpoint(apiConfigShow))
+	router.GET("/api/v1/communities", endpoint(apiCommunitiesSearch))
+	router.GET("/api/v1/sources", endpoint(apiSourcesList))
 
 	// Routeservers
 	router.GET("/api/v1/routeservers",
 		endpoint(apiRouteserversList))
 	router.GET("/api/v1/routeservers/:id/status",
-		endpoint(apiStatus))
+		endpoint(status(apiStatus)))
 	router.GET("/api/v1/routeservers/:id/neighbors",
-		endpoint(apiNeighborsList))
+		endpoint(neighbours(apiNeighborsList)))
 	router.GET("/api/v1/routeservers/:id/neighbors/:neighborId/routes",
-		endpoint(apiRoutesList))
+		endpoint(routes(limitedEndpoint(limiter, apiRoutesList))))
 	router.GET("/api/v1/routeservers/:id/neighbors/:neighborId/routes/received",
-		endpoint(apiRoutesListReceived))
+		endpoint(routes(limitedEndpoint(limiter, apiRoutesListReceived))))
 	router.GET("/api/v1/routeservers/:id/neighbors/:neighborId/routes/filtered",
-		endpoint(apiRoutesListFiltered))
+		endpoint(routes(limitedEndpoint(limiter, apiRoutesListFiltered))))
 	router.GET("/api/v1/routeservers/:id/neighbors/:neighborId/routes/not-exported",
-		endpoint(apiRoutesListNotExported))
+		endpoint(routes(limitedEndpoint(limiter, apiRoutesListNotExported))))
 
 	// Querying
 	if AliceConfig.Server.EnablePrefixLookup == true {
 		router.GET("/api/v1/lookup/prefix",
-			endpoint(apiLookupPrefixGlobal))
+			endpoint(lookup(limitedEndpoint(limiter, apiLookupPrefixGlobal))))
+		router.GET("/api/v1/lookup/prefix/stream",
+			streamEndpoint(limiter, apiLookupPrefixStream))
 		router.GET("/api/v1/lookup/neighbors",
-			endpoint(apiLookupNeighborsGlobal))
+			endpoint(neighbours(apiLookupNeighborsGlobal)))
+		router.GET("/api/v1/lookup/destination",
+			endpoint(lookup(limitedEndpoint(limiter, apiLookupDestinationGlobal))))
+		router.GET("/api/v1/lookup/origin",
+			endpoint(lookup(limitedEndpoint(limiter, apiLookupOriginGlobal))))
+		router.GET("/api/v1/lookup/next-hop",
+			endpoint(lookup(limitedEndpoint(limiter, apiLookupNextHopGlobal))))
+		router.GET("/api/v1/lookup/neighbors/empty",
+			endpoint(neighbours(apiLookupEmptyNeighborsGlobal)))
+		router.GET("/api/v1/lookup/neighbors/states",
+			endpoint(neighbours(apiLookupNeighborsStatesGlobal)))
+		router.GET("/api/v1/lookup/neighbors/groups",
+			endpoint(neighbours(apiLookupNeighborsGroupsGlobal)))
+		router.GET("/api/v1/routeservers/:id/empty-neighbors",
+			endpoint(neighbours(apiNeighborsListEmpty)))
+
+		// The diff is computed from the routes store
+		router.GET("/api/v1/routeservers/:id/neighbors/:neighborId/routes/diff",
+			endpoint(routes(limitedEndpoint(limiter, apiRoutesListDiff))))
+		router.GET("/api/v1/routeservers/:id/duplicate-paths",
+			endpoint(routes(limitedEndpoint(limiter, apiRoutesDuplicatePaths))))
+		router.GET("/api/v1/routeservers/:id/reject-candidates",
+			endpoint(routes(limitedEndpoint(limiter, apiRoutesRejectCandidates))))
+		router.GET("/api/v1/routeservers/:id/rpki-summary",
+			endpoint(routes(apiRoutesRpkiSummary)))
+		router.GET("/api/v1/lookup/rpki-summary",
+			endpoint(lookup(apiRpkiSummaryGlobal)))
+		router.GET("/api/v1/lookup/afi-counts",
+			endpoint(lookup(apiAfiCountsGlobal)))
+		router.GET("/api/v1/routeservers/:id/moas-prefixes",
+			endpoint(routes(limitedEndpoint(limiter, apiRoutesMoasPrefixes))))
+		router.GET("/api/v1/lookup/moas-prefixes",
+			endpoint(lookup(limitedEndpoint(limiter, apiMoasPrefixesGlobal))))
+		router.GET("/api/v1/routeservers/:id/origin-asns",
+			endpoint(routes(limitedEndpoint(limiter, apiRoutesOriginAsnGroups))))
+		router.GET("/api/v1/routeservers/:id/origin-changes",
+			endpoint(routes(apiRoutesOriginChanges)))
+		router.GET("/api/v1/routeservers/:id/covered-routes",
+			endpoint(routes(limitedEndpoint(limiter, apiRoutesCoveredByAggregates))))
+		router.GET("/api/v1/routeservers/:id/routes",
+			streamEndpoint(limiter, apiRoutesAll))
+		router.GET("/api/v1/routeservers/:id/export/mrt",
+			streamEndpoint(limiter, apiRoutesExportMrt))
 	}
 
 	return nil
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"strconv"
+)
+
+// Notations of ASNs (RFC 5396)
+const (
+	ASN_NOTATION_ASPLAIN = "asplain"
+	ASN_NOTATION_ASDOT   = "asdot"
+)
+
+// Format an ASN in the notation. In asdot notation,
+// 4-byte ASNs are rendered as <high>.<low>, while
+// 2-byte ASNs are unchanged.
+func FormatAsn(asn int, notation string) string {
+	if notation != ASN_NOTATION_ASDOT || asn <= 0xffff {
+		return strconv.Itoa(asn)
+	}
+	return strconv.Itoa(asn>>16) + "." + strconv.Itoa(asn&0xffff)
+}
+
+// Format the community with the global administrator
+// (the first component) in the notation.
+func (com Community) Format(notation string) string {
+	if len(com) == 0 || com[0] == COMMUNITY_WILDCARD {
+		return com.String()
+	}
+	res := FormatAsn(com[0], notation)
+	if len(com) > 1 {
+		res += ":" + com[1:].String()
+	}
+	return res
+}
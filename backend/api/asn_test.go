@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+)
+
+func TestFormatAsn(t *testing.T) {
+	tests := []struct {
+		asn      int
+		notation string
+		expected string
+	}{
+		{65537, ASN_NOTATION_ASPLAIN, "65537"},
+		{65537, ASN_NOTATION_ASDOT, "1.1"},
+		{4200000000, ASN_NOTATION_ASDOT, "64086.59904"},
+		{65535, ASN_NOTATION_ASDOT, "65535"},
+		{2342, ASN_NOTATION_ASDOT, "2342"},
+	}
+
+	for _, test := range tests {
+		res := FormatAsn(test.asn, test.notation)
+		if res != test.expected {
+			t.Error("Expected", test.expected, "got:", res)
+		}
+	}
+}
+
+func TestCommunityFormat(t *testing.T) {
+	com := Community{65537, 23, 42}
+	if com.Format(ASN_NOTATION_ASPLAIN) != "65537:23:42" {
+		t.Error("Unexpected asplain:", com.Format(ASN_NOTATION_ASPLAIN))
+	}
+	if com.Format(ASN_NOTATION_ASDOT) != "1.1:23:42" {
+		t.Error("Unexpected asdot:", com.Format(ASN_NOTATION_ASDOT))
+	}
+
+	com = Community{65535, 666}
+	if com.Format(ASN_NOTATION_ASDOT) != "65535:666" {
+		t.Error("Unexpected asdot:", com.Format(ASN_NOTATION_ASDOT))
+	}
+}
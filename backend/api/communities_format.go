@@ -0,0 +1,34 @@
+package api
+
+// Serialization of communities and large communities:
+// As arrays of numbers, as colon-joined strings, or both.
+const (
+	COMMUNITIES_FORMAT_ARRAY  = "array"
+	COMMUNITIES_FORMAT_STRING = "string"
+	COMMUNITIES_FORMAT_BOTH   = "both"
+)
+
+// Get the communities as colon-joined strings
+func (communities Communities) Strings() []string {
+	res := make([]string, 0, len(communities))
+	for _, com := range communities {
+		res = append(res, com.String())
+	}
+	return res
+}
+
+// Format the communities of the bgp info. A copy is
+// returned, the original communities are not changed.
+func (bgp BgpInfo) FormatCommunities(format string) BgpInfo {
+	switch format {
+	case COMMUNITIES_FORMAT_STRING:
+		bgp.CommunitiesStr = bgp.Communities.Strings()
+		bgp.LargeCommunitiesStr = bgp.LargeCommunities.Strings()
+		bgp.Communities = nil
+		bgp.LargeCommunities = nil
+	case COMMUNITIES_FORMAT_BOTH:
+		bgp.CommunitiesStr = bgp.Communities.Strings()
+		bgp.LargeCommunitiesStr = bgp.LargeCommunities.Strings()
+	}
+	return bgp
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func makeTestCommunitiesBgpInfo() BgpInfo {
+	return BgpInfo{
+		Communities:      Communities{Community{65000, 1}},
+		LargeCommunities: Communities{Community{4200000000, 1, 2}},
+	}
+}
+
+func TestFormatCommunitiesArray(t *testing.T) {
+	bgp := makeTestCommunitiesBgpInfo().FormatCommunities(
+		COMMUNITIES_FORMAT_ARRAY)
+
+	payload, _ := json.Marshal(bgp)
+	if !strings.Contains(string(payload),
+		`"large_communities":[[4200000000,1,2]]`) {
+		t.Error("Expected numeric large communities in:", string(payload))
+	}
+	if strings.Contains(string(payload), "communities_str") {
+		t.Error("Unexpected string communities in:", string(payload))
+	}
+}
+
+func TestFormatCommunitiesString(t *testing.T) {
+	orig := makeTestCommunitiesBgpInfo()
+	bgp := orig.FormatCommunities(COMMUNITIES_FORMAT_STRING)
+
+	payload, _ := json.Marshal(bgp)
+	if !strings.Contains(string(payload),
+		`"large_communities_str":["4200000000:1:2"]`) {
+		t.Error("Expected string large communities in:", string(payload))
+	}
+	if !strings.Contains(string(payload), `"communities_str":["65000:1"]`) {
+		t.Error("Expected string communities in:", string(payload))
+	}
+	if bgp.Communities != nil || bgp.LargeCommunities != nil {
+		t.Error("Expected numeric communities to be omitted")
+	}
+
+	// The original is not modified
+	if len(orig.LargeCommunities) != 1 || orig.LargeCommunitiesStr != nil {
+		t.Error("Unexpected modification of the original:", orig)
+	}
+}
+
+func TestFormatCommunitiesBoth(t *testing.T) {
+	bgp := makeTestCommunitiesBgpInfo().FormatCommunities(
+		COMMUNITIES_FORMAT_BOTH)
+	if len(bgp.LargeCommunities) != 1 || len(bgp.LargeCommunitiesStr) != 1 {
+		t.Error("Expected both forms, got:", bgp)
+	}
+}
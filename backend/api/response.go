@@ -17,6 +17,8 @@ type ErrorResponse struct {
 	Code          int    `json:"code"`
 	Tag           string `json:"tag"`
 	RouteserverId string `json:"routeserver_id"`
+
+	Connection *ConnectionStatus `json:"connection,omitempty"`
 }
 
 // Cache aware api response
@@ -24,11 +26,22 @@ type CacheableResponse interface {
 	CacheTtl() time.Duration
 }
 
+// A community with its label
+type CommunityLabel struct {
+	Community string `json:"community"`
+	Label     string `json:"label"`
+}
+
+type CommunityLabelsResponse struct {
+	Communities []*CommunityLabel `json:"communities"`
+}
+
 // Config
 type ConfigResponse struct {
 	Asn int `json:"asn"`
 
-	RejectReasons map[string]interface{} `json:"reject_reasons"`
+	RejectReasons        map[string]interface{} `json:"reject_reasons"`
+	RejectReasonsDetails map[string]interface{} `json:"reject_reasons_details"`
 
 	Noexport        Noexport               `json:"noexport"`
 	NoexportReasons map[string]interface{} `json:"noexport_reasons"`
@@ -73,6 +86,22 @@ type ApiStatus struct {
 	CacheStatus     CacheStatus `json:"cache_status"`
 	ResultFromCache bool        `json:"result_from_cache"`
 	Ttl             time.Time   `json:"ttl"`
+
+	// The store was not refreshed yet: An empty
+	// result does not mean there is no data.
+	Loading bool `json:"loading"`
+
+	// The refresh of the source failed: The data
+	// of the last successful refresh is served.
+	Stale bool `json:"stale"`
+
+	Timing *ApiTiming `json:"timing,omitempty"`
+}
+
+// Timing of the call to the source
+type ApiTiming struct {
+	SourceLatency float64 `json:"source_latency_ms"`
+	CacheHit      bool    `json:"cache_hit"`
 }
 
 type CacheStatus struct {
@@ -86,13 +115,25 @@ type Status struct {
 	LastReconfig time.Time `json:"last_reconfig"`
 	Message      string    `json:"message"`
 	RouterId     string    `json:"router_id"`
+	LocalAs      int       `json:"local_as"`
 	Version      string    `json:"version"`
 	Backend      string    `json:"backend"`
+	Maintenance  bool      `json:"maintenance"`
+
+	Connection *ConnectionStatus `json:"connection,omitempty"`
+}
+
+// Diagnostics of the connection to the source backend
+type ConnectionStatus struct {
+	State      string `json:"state"`
+	Reconnects int    `json:"reconnects"`
+	LastError  string `json:"last_error"`
 }
 
 type StatusResponse struct {
-	Api    ApiStatus `json:"api"`
-	Status Status    `json:"status"`
+	Api           ApiStatus     `json:"api"`
+	SchemaVersion SchemaVersion `json:"schema_version"`
+	Status        Status        `json:"status"`
 }
 
 // Routeservers
@@ -102,9 +143,29 @@ type Routeserver struct {
 	Group      string   `json:"group"`
 	Blackholes []string `json:"blackholes"`
 
+	TimeConfig *TimeConfig `json:"time_config,omitempty"`
+
+	// Lookups are served from the store or live
+	LookupMode string `json:"lookup_mode,omitempty"`
+
+	// Default sort of routes and neighbours
+	RoutesSort     string `json:"routes_sort,omitempty"`
+	NeighboursSort string `json:"neighbours_sort,omitempty"`
+
+	// Route tabs meaningful for the source
+	RouteTabs []string `json:"route_tabs"`
+
 	Order int `json:"-"`
 }
 
+// Time formatting used by the source
+type TimeConfig struct {
+	Timezone        string `json:"timezone"`
+	ServerTime      string `json:"server_time"`
+	ServerTimeShort string `json:"server_time_short"`
+	ServerTimeExt   string `json:"server_time_ext"`
+}
+
 type Routeservers []Routeserver
 
 // Implement sorting interface for routeservers
@@ -124,12 +185,33 @@ type RouteserversResponse struct {
 	Routeservers []Routeserver `json:"routeservers"`
 }
 
+// Sources layout: Only the metadata required
+// for building the navigation
+type Source struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Group string `json:"group"`
+	Order int    `json:"order"`
+	Type  string `json:"type"`
+}
+
+type SourcesResponse struct {
+	Sources []Source `json:"sources"`
+}
+
 // BGP
 type Community []int
 
+// Wildcard component in large community filters: 23:*:42
+const COMMUNITY_WILDCARD = -1
+
 func (com Community) String() string {
 	res := ""
 	for _, v := range com {
+		if v == COMMUNITY_WILDCARD {
+			res += ":*"
+			continue
+		}
 		res += fmt.Sprintf(":%d", v)
 	}
 	return res[1:]
@@ -187,12 +269,17 @@ func (communities ExtCommunities) Unique() ExtCommunities {
 type BgpInfo struct {
 	Origin           string         `json:"origin"`
 	AsPath           []int          `json:"as_path"`
+	AsPathAsdot      []string       `json:"as_path_asdot,omitempty"`
 	NextHop          string         `json:"next_hop"`
 	Communities      Communities    `json:"communities"`
 	LargeCommunities Communities    `json:"large_communities"`
 	ExtCommunities   ExtCommunities `json:"ext_communities"`
 	LocalPref        int            `json:"local_pref"`
 	Med              int            `json:"med"`
+
+	// Communities as strings, if requested
+	CommunitiesStr      []string `json:"communities_str,omitempty"`
+	LargeCommunitiesStr []string `json:"large_communities_str,omitempty"`
 }
 
 func (bgp BgpInfo) HasCommunity(community Community) bool {
@@ -245,12 +332,18 @@ func (bgp BgpInfo) HasLargeCommunity(community Community) bool {
 			continue // This can't match.
 		}
 
-		if com[0] == community[0] &&
-			com[1] == community[1] &&
-			com[2] == community[2] {
+		if matchCommunityComponent(com[0], community[0]) &&
+			matchCommunityComponent(com[1], community[1]) &&
+			matchCommunityComponent(com[2], community[2]) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// Check a community component. A wildcard matches
+// any value.
+func matchCommunityComponent(value int, pattern int) bool {
+	return pattern == COMMUNITY_WILDCARD || value == pattern
+}
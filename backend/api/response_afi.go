@@ -0,0 +1,34 @@
+package api
+
+// Address families
+const (
+	AFI_IPV4 = "ipv4"
+	AFI_IPV6 = "ipv6"
+)
+
+// Number of routes and distinct prefixes
+type AfiCount struct {
+	Routes   int `json:"routes"`
+	Prefixes int `json:"prefixes"`
+}
+
+// Route counts per address family
+type AfiCounts struct {
+	Ipv4 AfiCount `json:"ipv4"`
+	Ipv6 AfiCount `json:"ipv6"`
+}
+
+// Add the counts of another source
+func (self *AfiCounts) Merge(other *AfiCounts) {
+	self.Ipv4.Routes += other.Ipv4.Routes
+	self.Ipv4.Prefixes += other.Ipv4.Prefixes
+	self.Ipv6.Routes += other.Ipv6.Routes
+	self.Ipv6.Prefixes += other.Ipv6.Prefixes
+}
+
+type AfiCountsResponse struct {
+	Api           ApiStatus             `json:"api"`
+	SchemaVersion SchemaVersion         `json:"schema_version"`
+	Total         *AfiCounts            `json:"total"`
+	Sources       map[string]*AfiCounts `json:"sources"`
+}
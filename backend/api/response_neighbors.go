@@ -14,7 +14,9 @@ type Neighbour struct {
 	// Mandatory fields
 	Address         string        `json:"address"`
 	Asn             int           `json:"asn"`
+	AsnAsdot        string        `json:"asn_asdot,omitempty"`
 	State           string        `json:"state"`
+	StateRaw        string        `json:"state_raw"` // As reported by the backend
 	Description     string        `json:"description"`
 	RoutesReceived  int           `json:"routes_received"`
 	RoutesFiltered  int           `json:"routes_filtered"`
@@ -25,6 +27,16 @@ type Neighbour struct {
 	LastError       string        `json:"last_error"`
 	RouteServerId   string        `json:"routeserver_id"`
 
+	// The uptime in seconds or human readable, if requested
+	UptimeSeconds *int64 `json:"uptime_seconds,omitempty"`
+	UptimeHuman   string `json:"uptime_human,omitempty"`
+
+	// The description was not provided by the source
+	DescriptionFallback bool `json:"description_fallback"`
+
+	// Number of distinct next hops of the received routes
+	DistinctNextHops int `json:"distinct_next_hops"`
+
 	// Original response
 	Details map[string]interface{} `json:"details"`
 }
@@ -43,8 +55,9 @@ func (neighbours Neighbours) Swap(i, j int) {
 }
 
 type NeighboursResponse struct {
-	Api        ApiStatus  `json:"api"`
-	Neighbours Neighbours `json:"neighbours"`
+	Api           ApiStatus     `json:"api"`
+	SchemaVersion SchemaVersion `json:"schema_version"`
+	Neighbours    Neighbours    `json:"neighbours"`
 }
 
 // Implement Filterable interface
@@ -85,6 +98,32 @@ type NeighboursLookupResults map[string]Neighbours
 
 type NeighboursStatus []*NeighbourStatus
 
+// Neighbours of all route servers in a group
+type NeighboursGroup struct {
+	Group   string   `json:"group"`
+	Sources []string `json:"sources"`
+
+	TotalNeighbours int `json:"total_neighbours"`
+	NeighboursUp    int `json:"neighbours_up"`
+	RoutesReceived  int `json:"routes_received"`
+	RoutesFiltered  int `json:"routes_filtered"`
+	RoutesExported  int `json:"routes_exported"`
+	RoutesAccepted  int `json:"routes_accepted"`
+
+	Neighbours Neighbours `json:"neighbours"`
+}
+
+type NeighboursGroups []*NeighboursGroup
+
+type NeighboursGroupsResponse struct {
+	Api           ApiStatus        `json:"api"`
+	SchemaVersion SchemaVersion    `json:"schema_version"`
+	Groups        NeighboursGroups `json:"groups"`
+}
+
+// Compact neighbour states: {sourceId: {neighbourId: state}}
+type NeighboursStatesMap map[string]map[string]string
+
 type NeighbourStatus struct {
 	Id    string        `json:"id"`
 	State string        `json:"state"`
@@ -105,6 +144,7 @@ func (neighbours NeighboursStatus) Swap(i, j int) {
 }
 
 type NeighboursStatusResponse struct {
-	Api        ApiStatus        `json:"api"`
-	Neighbours NeighboursStatus `json:"neighbours"`
+	Api           ApiStatus        `json:"api"`
+	SchemaVersion SchemaVersion    `json:"schema_version"`
+	Neighbours    NeighboursStatus `json:"neighbours"`
 }
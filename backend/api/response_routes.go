@@ -4,6 +4,80 @@ import (
 	"time"
 )
 
+// A prefix originated by an ASN and the
+// sources carrying it
+type OriginatedPrefix struct {
+	Network string   `json:"network"`
+	Sources []string `json:"sources"`
+	Paths   int      `json:"paths"`
+}
+
+type OriginatedPrefixesResponse struct {
+	Api           ApiStatus           `json:"api"`
+	SchemaVersion SchemaVersion       `json:"schema_version"`
+	Asn           int                 `json:"asn"`
+	Prefixes      []*OriginatedPrefix `json:"prefixes"`
+}
+
+// Routes of a source with the same origin ASN.
+// The networks are only included when expanded.
+type OriginAsnGroup struct {
+	Asn      int      `json:"asn"`
+	Prefixes int      `json:"prefixes"`
+	Routes   int      `json:"routes"`
+	Networks []string `json:"networks,omitempty"`
+}
+
+type OriginAsnGroupsResponse struct {
+	Api           ApiStatus         `json:"api"`
+	SchemaVersion SchemaVersion     `json:"schema_version"`
+	Groups        []*OriginAsnGroup `json:"groups"`
+	Pagination    Pagination        `json:"pagination"`
+}
+
+// A prefix originated by multiple ASNs
+type MoasPrefix struct {
+	Network string   `json:"network"`
+	Origins []int    `json:"origins"`
+	Sources []string `json:"sources"`
+}
+
+type MoasPrefixesResponse struct {
+	Api           ApiStatus     `json:"api"`
+	SchemaVersion SchemaVersion `json:"schema_version"`
+	Prefixes      []*MoasPrefix `json:"prefixes"`
+}
+
+// A prefix with different origin ASNs than
+// on the previous refresh
+type OriginChange struct {
+	Network         string `json:"network"`
+	PreviousOrigins []int  `json:"previous_origins"`
+	Origins         []int  `json:"origins"`
+}
+
+type OriginChangesResponse struct {
+	Api           ApiStatus       `json:"api"`
+	SchemaVersion SchemaVersion   `json:"schema_version"`
+	Changes       []*OriginChange `json:"changes"`
+}
+
+// A source with routes via a next hop
+type NextHopSource struct {
+	SourceId   string `json:"source_id"`
+	SourceName string `json:"source_name"`
+	Routes     int    `json:"routes"`
+	Imported   int    `json:"imported"`
+	Filtered   int    `json:"filtered"`
+}
+
+type NextHopSourcesResponse struct {
+	Api           ApiStatus        `json:"api"`
+	SchemaVersion SchemaVersion    `json:"schema_version"`
+	NextHop       string           `json:"next_hop"`
+	Sources       []*NextHopSource `json:"sources"`
+}
+
 // Prefixes
 type Route struct {
 	Id          string `json:"id"`
@@ -18,6 +92,24 @@ type Route struct {
 	Type      []string      `json:"type"` // [BGP, unicast, univ]
 	Primary   bool          `json:"primary"`
 
+	Blackholed bool `json:"blackholed"`
+	Bogon      bool `json:"bogon"`
+
+	AsPathLength  int  `json:"as_path_length"`
+	AsPathTooLong bool `json:"as_path_too_long"`
+	PrependCount  int  `json:"prepend_count"`
+
+	// Labels of the communities, only resolved on request
+	CommunityLabels map[string]string `json:"community_labels,omitempty"`
+
+	// The aggregate covering the route, if requested
+	CoveringAggregate string `json:"covering_aggregate,omitempty"`
+
+	// Number and size of the path attributes,
+	// if the raw attributes are provided by the source
+	AttributeCount int `json:"attribute_count,omitempty"`
+	AttributeBytes int `json:"attribute_bytes,omitempty"`
+
 	Details Details `json:"details"`
 }
 
@@ -59,10 +151,11 @@ func (routes Routes) Swap(i, j int) {
 }
 
 type RoutesResponse struct {
-	Api         ApiStatus `json:"api"`
-	Imported    Routes    `json:"imported"`
-	Filtered    Routes    `json:"filtered"`
-	NotExported Routes    `json:"not_exported"`
+	Api           ApiStatus     `json:"api"`
+	SchemaVersion SchemaVersion `json:"schema_version"`
+	Imported      Routes        `json:"imported"`
+	Filtered      Routes        `json:"filtered"`
+	NotExported   Routes        `json:"not_exported"`
 }
 
 func (self *RoutesResponse) CacheTtl() time.Duration {
@@ -70,6 +163,32 @@ func (self *RoutesResponse) CacheTtl() time.Duration {
 	return self.Api.Ttl.Sub(now)
 }
 
+// Routes received from a neighbour, which were filtered or
+// are no longer filtered since the previous refresh
+type RoutesDiffResponse struct {
+	Api              ApiStatus     `json:"api"`
+	SchemaVersion    SchemaVersion `json:"schema_version"`
+	Received         int           `json:"received"`
+	Accepted         int           `json:"accepted"`
+	Since            time.Time     `json:"since"`
+	NewlyFiltered    Routes        `json:"newly_filtered"`
+	NoLongerFiltered Routes        `json:"no_longer_filtered"`
+}
+
+// Paths with the same AS path and next hop
+// received from a neighbour
+type NeighbourDuplicatePaths struct {
+	NeighbourId    string `json:"neighbour_id"`
+	Routes         int    `json:"routes"`
+	DuplicatePaths int    `json:"duplicate_paths"`
+}
+
+type DuplicatePathsResponse struct {
+	Api           ApiStatus                  `json:"api"`
+	SchemaVersion SchemaVersion              `json:"schema_version"`
+	Neighbours    []*NeighbourDuplicatePaths `json:"neighbours"`
+}
+
 type TimedResponse struct {
 	RequestDuration float64 `json:"request_duration_ms"`
 }
@@ -116,6 +235,19 @@ type LookupRoute struct {
 	Type      []string      `json:"type"` // [BGP, unicast, univ]
 	Primary   bool          `json:"primary"`
 
+	Blackholed bool `json:"blackholed"`
+	Bogon      bool `json:"bogon"`
+
+	AsPathLength  int  `json:"as_path_length"`
+	AsPathTooLong bool `json:"as_path_too_long"`
+	PrependCount  int  `json:"prepend_count"`
+
+	// Not all paths for the prefix are included
+	PathsTruncated bool `json:"paths_truncated"`
+
+	// Labels of the communities, only resolved on request
+	CommunityLabels map[string]string `json:"community_labels,omitempty"`
+
 	Details Details `json:"details"`
 }
 
@@ -164,8 +296,12 @@ type LookupRoutesResponse struct {
 
 // TODO: Refactor this (might be legacy)
 type RoutesLookupResponse struct {
-	Api    ApiStatus    `json:"api"`
-	Routes LookupRoutes `json:"routes"`
+	Api           ApiStatus     `json:"api"`
+	SchemaVersion SchemaVersion `json:"schema_version"`
+	Routes        LookupRoutes  `json:"routes"`
+
+	// Sources not queried due to the lookup sources limit
+	SkippedSources []string `json:"skipped_sources,omitempty"`
 }
 
 type RoutesLookupResponseGlobal struct {
@@ -184,7 +320,12 @@ type PaginatedRoutesLookupResponse struct {
 	TimedResponse
 	FilterableResponse
 
-	Api ApiStatus `json:"api"` // Add to provide cache status information
+	Api           ApiStatus     `json:"api"` // Add to provide cache status information
+	SchemaVersion SchemaVersion `json:"schema_version"`
+
+	// Sources not queried due to the lookup sources limit
+	SourcesSkipped bool     `json:"sources_skipped"`
+	SkippedSources []string `json:"skipped_sources"`
 
 	Imported *LookupRoutesResponse `json:"imported"`
 	Filtered *LookupRoutesResponse `json:"filtered"`
@@ -0,0 +1,73 @@
+package api
+
+// Validation states of routes
+const (
+	RPKI_STATE_VALID       = "valid"
+	RPKI_STATE_INVALID     = "invalid"
+	RPKI_STATE_UNKNOWN     = "unknown"
+	RPKI_STATE_NOT_CHECKED = "not_checked"
+)
+
+// RPKI origin validation summary
+type RpkiSummary struct {
+	Total      int `json:"total"`
+	Valid      int `json:"valid"`
+	Invalid    int `json:"invalid"`
+	Unknown    int `json:"unknown"`
+	NotChecked int `json:"not_checked"`
+	Untagged   int `json:"untagged"`
+
+	// Percentages of the total routes
+	ValidPercent      float64 `json:"valid_percent"`
+	InvalidPercent    float64 `json:"invalid_percent"`
+	UnknownPercent    float64 `json:"unknown_percent"`
+	NotCheckedPercent float64 `json:"not_checked_percent"`
+}
+
+// Count a route with the validation state
+func (self *RpkiSummary) Add(state string) {
+	self.Total++
+	switch state {
+	case RPKI_STATE_VALID:
+		self.Valid++
+	case RPKI_STATE_INVALID:
+		self.Invalid++
+	case RPKI_STATE_UNKNOWN:
+		self.Unknown++
+	case RPKI_STATE_NOT_CHECKED:
+		self.NotChecked++
+	default:
+		self.Untagged++
+	}
+}
+
+// Add the counts of another summary
+func (self *RpkiSummary) Merge(other *RpkiSummary) {
+	self.Total += other.Total
+	self.Valid += other.Valid
+	self.Invalid += other.Invalid
+	self.Unknown += other.Unknown
+	self.NotChecked += other.NotChecked
+	self.Untagged += other.Untagged
+}
+
+// Calculate the percentages from the counts
+func (self *RpkiSummary) UpdatePercentages() {
+	percent := func(n int) float64 {
+		if self.Total == 0 {
+			return 0
+		}
+		return float64(n) * 100.0 / float64(self.Total)
+	}
+	self.ValidPercent = percent(self.Valid)
+	self.InvalidPercent = percent(self.Invalid)
+	self.UnknownPercent = percent(self.Unknown)
+	self.NotCheckedPercent = percent(self.NotChecked)
+}
+
+type RpkiSummaryResponse struct {
+	Api           ApiStatus               `json:"api"`
+	SchemaVersion SchemaVersion           `json:"schema_version"`
+	Summary       *RpkiSummary            `json:"summary"`
+	Sources       map[string]*RpkiSummary `json:"sources,omitempty"`
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"strconv"
+)
+
+// The version of the shape of the response envelopes,
+// independent of the api version. Increment it when the
+// serialized shape of a response changes.
+const SCHEMA_VERSION = 1
+
+// SchemaVersion is included in the response envelopes
+// and is always serialized as the current SCHEMA_VERSION.
+type SchemaVersion struct{}
+
+func (self SchemaVersion) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(SCHEMA_VERSION)), nil
+}
+
+// The schema version of a decoded response is not retained
+func (self *SchemaVersion) UnmarshalJSON(data []byte) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaVersionInEnvelopes(t *testing.T) {
+	envelopes := []Response{
+		&StatusResponse{},
+		&RoutesResponse{},
+		&PaginatedRoutesResponse{RoutesResponse: &RoutesResponse{}},
+		&PaginatedRoutesLookupResponse{},
+		&NeighboursResponse{},
+		&NeighboursStatusResponse{},
+	}
+
+	for _, envelope := range envelopes {
+		payload, err := json.Marshal(envelope)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := map[string]interface{}{}
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		version, ok := decoded["schema_version"].(float64)
+		if !ok || int(version) != SCHEMA_VERSION {
+			t.Errorf("%T: expected schema_version %d, got: %v",
+				envelope, SCHEMA_VERSION, decoded["schema_version"])
+		}
+
+		// Decoding the envelope must not fail
+		if err := json.Unmarshal(payload, envelope); err != nil {
+			t.Errorf("%T: %s", envelope, err)
+		}
+	}
+}
@@ -458,7 +458,7 @@ func FiltersFromQuery(query url.Values) (*SearchFilters, error) {
 			break
 
 		case SEARCH_KEY_LARGE_COMMUNITIES:
-			filters, err := parseQueryValueList(parseCommunityValue, value)
+			filters, err := parseQueryValueList(parseLargeCommunityValue, value)
 			if err != nil {
 				return nil, err
 			}
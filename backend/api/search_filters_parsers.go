@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -60,6 +61,32 @@ func parseCommunityValue(value string) (*SearchFilter, error) {
 	}, nil
 }
 
+// Large communities may contain wildcards: 9033:*:1
+func parseLargeCommunityValue(value string) (*SearchFilter, error) {
+	components := strings.Split(value, ":")
+	if len(components) != 3 {
+		return nil, fmt.Errorf("malformed large community: %s", value)
+	}
+
+	community := make(Community, len(components))
+	for i, c := range components {
+		if c == "*" {
+			community[i] = COMMUNITY_WILDCARD
+			continue
+		}
+		v, err := strconv.Atoi(c)
+		if err != nil {
+			return nil, err
+		}
+		community[i] = v
+	}
+
+	return &SearchFilter{
+		Name:  community.String(),
+		Value: community,
+	}, nil
+}
+
 func parseExtCommunityValue(value string) (*SearchFilter, error) {
 	components := strings.Split(value, ":")
 	community := make(ExtCommunity, len(components))
@@ -59,3 +59,23 @@ func TestParseExtCommunityValue(t *testing.T) {
 	}
 
 }
+
+func TestParseLargeCommunityValue(t *testing.T) {
+	filter, err := parseLargeCommunityValue("9033:*:1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if filter.Name != "9033:*:1" {
+		t.Error("Expected name: '9033:*:1', but got:", filter.Name)
+	}
+	com := filter.Value.(Community)
+	if com[0] != 9033 || com[1] != COMMUNITY_WILDCARD || com[2] != 1 {
+		t.Error("Unexpected community:", com)
+	}
+
+	_, err = parseLargeCommunityValue("9033:foo:1")
+	if err == nil {
+		t.Error("Expected error for invalid component")
+	}
+}
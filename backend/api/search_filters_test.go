@@ -491,6 +491,61 @@ func TestSearchFilterLookupRouteLargeCommunities(t *testing.T) {
 	testSearchFilterLargeCommunities(route, t)
 }
 
+// Check partial large communities
+func testSearchFilterPartialLargeCommunities(route Filterable, t *testing.T) {
+	matching := []string{
+		"1000:23:*",
+		"*:23:*",
+		"*:*:42",
+		"1000:*:42",
+	}
+	for _, q := range matching {
+		values, _ := url.ParseQuery("large_communities=" + q)
+		filters, err := FiltersFromQuery(values)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if filters.MatchRoute(route) == false {
+			t.Error("Route should have matched filter:", q)
+		}
+	}
+
+	notMatching := []string{
+		"1000:42:*",
+		"*:*:23",
+		"2342:*:*",
+	}
+	for _, q := range notMatching {
+		values, _ := url.ParseQuery("large_communities=" + q)
+		filters, err := FiltersFromQuery(values)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if filters.MatchRoute(route) != false {
+			t.Error("Route should not have matched filter:", q)
+		}
+	}
+
+	// Partial communities require all three components
+	values, _ := url.ParseQuery("large_communities=1000:*")
+	_, err := FiltersFromQuery(values)
+	if err == nil {
+		t.Error("Expected error for malformed large community")
+	}
+}
+
+func TestSearchFilterRoutePartialLargeCommunities(t *testing.T) {
+	route := makeTestRoute()
+	testSearchFilterPartialLargeCommunities(route, t)
+}
+
+func TestSearchFilterLookupRoutePartialLargeCommunities(t *testing.T) {
+	route := makeTestLookupRoute()
+	testSearchFilterPartialLargeCommunities(route, t)
+}
+
 // Subtract other
 func TestSearchFiltersSub(t *testing.T) {
 	query := "asns=2342,23042&communities=23:42&large_communities=42:23:42&sources=1,2,3&q=foo"
@@ -646,7 +701,7 @@ func TestNeighborFilterFromQuery(t *testing.T) {
 		t.Error("Unexpected name filter:", filter.name)
 	}
 
-	filter = NeighborFilterFromQueryString(values)
+	filter = NeighborFilterFromQueryString("")
 	if filter.asn != 0 {
 		t.Error("Unexpected asn:", filter.asn)
 	}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"strconv"
+	"time"
+)
+
+// Serialization of the neighbour uptime. The uptime
+// in nanoseconds is always included.
+const (
+	UPTIME_FORMAT_NANOSECONDS = "nanoseconds"
+	UPTIME_FORMAT_SECONDS     = "seconds"
+	UPTIME_FORMAT_HUMAN       = "human"
+)
+
+var uptimeUnits = []struct {
+	duration time.Duration
+	suffix   string
+}{
+	{24 * time.Hour, "d"},
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+	{time.Second, "s"},
+}
+
+// Format the uptime human readable, rounded down
+// to the two most significant units, e.g. 3d4h or 12m5s.
+func FormatUptimeHuman(uptime time.Duration) string {
+	res := ""
+	units := 0
+	for _, unit := range uptimeUnits {
+		if units == 2 {
+			break
+		}
+		value := uptime / unit.duration
+		if value == 0 && units == 0 {
+			continue // Skip leading units
+		}
+		uptime -= value * unit.duration
+		units++
+		if value > 0 {
+			res += strconv.FormatInt(int64(value), 10) + unit.suffix
+		}
+	}
+	if res == "" {
+		return "0s"
+	}
+	return res
+}
+
+// Format the uptime of the neighbour
+func (self *Neighbour) FormatUptime(format string) {
+	switch format {
+	case UPTIME_FORMAT_SECONDS:
+		seconds := int64(self.Uptime / time.Second)
+		self.UptimeSeconds = &seconds
+	case UPTIME_FORMAT_HUMAN:
+		self.UptimeHuman = FormatUptimeHuman(self.Uptime)
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatUptimeHuman(t *testing.T) {
+	tests := []struct {
+		uptime   time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{5 * time.Second, "5s"},
+		{12*time.Minute + 5*time.Second + 300*time.Millisecond, "12m5s"},
+		{4*time.Hour + 12*time.Minute + 5*time.Second, "4h12m"},
+		{76*time.Hour + 30*time.Minute, "3d4h"},
+		{72*time.Hour + 5*time.Minute, "3d"},
+	}
+
+	for _, test := range tests {
+		res := FormatUptimeHuman(test.uptime)
+		if res != test.expected {
+			t.Error("Expected", test.expected, "got:", res)
+		}
+	}
+}
+
+func TestNeighbourFormatUptime(t *testing.T) {
+	uptime := 76*time.Hour + 30*time.Minute
+
+	n := &Neighbour{Uptime: uptime}
+	n.FormatUptime(UPTIME_FORMAT_NANOSECONDS)
+	if n.UptimeSeconds != nil || n.UptimeHuman != "" {
+		t.Error("Expected only the uptime in nanoseconds")
+	}
+
+	n.FormatUptime(UPTIME_FORMAT_SECONDS)
+	if n.UptimeSeconds == nil || *n.UptimeSeconds != 275400 {
+		t.Error("Unexpected uptime in seconds:", n.UptimeSeconds)
+	}
+
+	n = &Neighbour{Uptime: uptime}
+	n.FormatUptime(UPTIME_FORMAT_HUMAN)
+	if n.UptimeHuman != "3d4h" {
+		t.Error("Unexpected human readable uptime:", n.UptimeHuman)
+	}
+	if n.Uptime != uptime {
+		t.Error("The uptime in nanoseconds must be kept")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+/*
+Limit the size of request bodies.
+
+Requests with a body (POST, PUT, PATCH) are rejected
+if the announced content length exceeds the limit.
+Otherwise the body is wrapped by a reader failing
+when reading beyond the limit.
+*/
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Check if the request method carries a body
+func requestHasBody(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+// A request body failing with a RequestBodyTooLargeError
+// when reading beyond the limit
+type limitedRequestBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func (self *limitedRequestBody) Read(p []byte) (int, error) {
+	if self.remaining < 0 {
+		return 0, &RequestBodyTooLargeError{Limit: self.limit}
+	}
+	// Read one byte more than allowed to detect
+	// bodies exceeding the limit
+	if int64(len(p)) > self.remaining+1 {
+		p = p[:self.remaining+1]
+	}
+	n, err := self.ReadCloser.Read(p)
+	if int64(n) <= self.remaining {
+		self.remaining -= int64(n)
+		return n, err
+	}
+	n = int(self.remaining)
+	self.remaining = -1
+	return n, &RequestBodyTooLargeError{Limit: self.limit}
+}
+
+// Limit the request body, a limit of 0 disables the limit
+func apiLimitRequestBody(req *http.Request, limit int64) error {
+	if limit <= 0 || !requestHasBody(req) || req.Body == nil {
+		return nil
+	}
+	if req.ContentLength > limit {
+		return &RequestBodyTooLargeError{Limit: limit}
+	}
+	req.Body = &limitedRequestBody{
+		ReadCloser: req.Body,
+		limit:      limit,
+		remaining:  limit,
+	}
+	return nil
+}
+
+// Decode a json request body. Exceeding the body
+// size limit results in a RequestBodyTooLargeError.
+func apiDecodeRequestBody(req *http.Request, v interface{}) error {
+	return json.NewDecoder(req.Body).Decode(v)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+func bodyEndpoint(
+	req *http.Request, _ httprouter.Params,
+) (api.Response, error) {
+	query := struct {
+		Prefixes []string `json:"prefixes"`
+	}{}
+	if err := apiDecodeRequestBody(req, &query); err != nil {
+		return nil, err
+	}
+	return &api.StatusResponse{}, nil
+}
+
+func TestApiLimitRequestBody(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Server: ServerConfig{MaxRequestBodySize: 64},
+	}
+	handler := endpoint(bodyEndpoint)
+
+	// Acceptable body
+	body := `{"prefixes": ["10.0.0.0/8"]}`
+	req := httptest.NewRequest("POST", "/api/v1/lookup",
+		strings.NewReader(body))
+	res := httptest.NewRecorder()
+	handler(res, req, nil)
+	if res.Code != http.StatusOK {
+		t.Error("Expected status 200, got:", res.Code, res.Body.String())
+	}
+
+	// Oversized body with content length
+	body = `{"prefixes": ["` + strings.Repeat("1", 128) + `"]}`
+	req = httptest.NewRequest("POST", "/api/v1/lookup",
+		strings.NewReader(body))
+	res = httptest.NewRecorder()
+	handler(res, req, nil)
+	if res.Code != http.StatusRequestEntityTooLarge {
+		t.Error("Expected status 413, got:", res.Code)
+	}
+
+	// Oversized body without content length
+	req = httptest.NewRequest("POST", "/api/v1/lookup",
+		io.MultiReader(strings.NewReader(body)))
+	req.ContentLength = -1
+	res = httptest.NewRecorder()
+	handler(res, req, nil)
+	if res.Code != http.StatusRequestEntityTooLarge {
+		t.Error("Expected status 413, got:", res.Code)
+	}
+	if !strings.Contains(res.Body.String(), TOO_LARGE_TAG) {
+		t.Error("Unexpected error response:", res.Body.String())
+	}
+}
+
+func TestLimitedRequestBody(t *testing.T) {
+	// A body of exactly the limit is accepted
+	req := httptest.NewRequest("POST", "/api/v1/lookup",
+		io.MultiReader(strings.NewReader("1234")))
+	req.ContentLength = -1
+	if err := apiLimitRequestBody(req, 4); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil || string(data) != "1234" {
+		t.Error("Unexpected body:", string(data), err)
+	}
+
+	// Reading beyond the limit fails
+	req = httptest.NewRequest("POST", "/api/v1/lookup",
+		io.MultiReader(strings.NewReader("12345")))
+	req.ContentLength = -1
+	if err := apiLimitRequestBody(req, 4); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadAll(req.Body)
+	if _, ok := err.(*RequestBodyTooLargeError); !ok {
+		t.Error("Expected RequestBodyTooLargeError, got:", err)
+	}
+	if string(data) != "1234" {
+		t.Error("Unexpected body:", string(data))
+	}
+}
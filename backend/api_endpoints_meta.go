@@ -2,9 +2,11 @@ package main
 
 import (
 	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/alice-lg/alice-lg/backend/sources"
 	"github.com/julienschmidt/httprouter"
 
 	"net/http"
+	"time"
 )
 
 // Handle Status Endpoint, this is intended for
@@ -26,9 +28,37 @@ func apiStatus(_req *http.Request, params httprouter.Params) (api.Response, erro
 		return nil, SOURCE_NOT_FOUND_ERROR
 	}
 
+	// The source is not queried during maintenance
+	if AliceConfig.SourceById(rsId).inMaintenance(time.Now()) {
+		return &api.StatusResponse{
+			Api: api.ApiStatus{
+				Version: version,
+			},
+			Status: api.Status{
+				Message:     "The route server is in maintenance",
+				Maintenance: true,
+			},
+		}, nil
+	}
+
+	t0 := time.Now()
 	result, err := source.Status()
 	if err != nil {
 		apiLogSourceError("status", rsId, err)
+	} else {
+		result.Api = apiSourceTiming(result.Api, time.Since(t0))
+	}
+
+	// Add connection diagnostics if available
+	if connSource, ok := source.(sources.ConnectionStatusSource); ok {
+		connection := connSource.ConnectionStatus()
+		if err != nil {
+			return nil, &SourceConnectionError{
+				Err:        err,
+				Connection: connection,
+			}
+		}
+		result.Status.Connection = connection
 	}
 
 	return result, err
@@ -37,9 +67,10 @@ func apiStatus(_req *http.Request, params httprouter.Params) (api.Response, erro
 // Handle Config Endpoint
 func apiConfigShow(_req *http.Request, _params httprouter.Params) (api.Response, error) {
 	result := api.ConfigResponse{
-		Asn:            AliceConfig.Server.Asn,
-		BgpCommunities: AliceConfig.Ui.BgpCommunities,
-		RejectReasons:  AliceConfig.Ui.RoutesRejections.Reasons,
+		Asn:                  AliceConfig.Server.Asn,
+		BgpCommunities:       AliceConfig.Ui.BgpCommunities,
+		RejectReasons:        AliceConfig.Ui.RoutesRejections.Reasons,
+		RejectReasonsDetails: AliceConfig.Ui.RoutesRejections.Details,
 		Noexport: api.Noexport{
 			LoadOnDemand: AliceConfig.Ui.RoutesNoexports.LoadOnDemand,
 		},
@@ -58,3 +89,16 @@ func apiConfigShow(_req *http.Request, _params httprouter.Params) (api.Response,
 	}
 	return result, nil
 }
+
+// Handle community labels search: Find communities
+// by a substring of their label.
+func apiCommunitiesSearch(
+	req *http.Request,
+	_params httprouter.Params,
+) (api.Response, error) {
+	query := req.URL.Query().Get("q")
+	response := api.CommunityLabelsResponse{
+		Communities: AliceConfig.Ui.BgpCommunities.SearchLabels(query),
+	}
+	return response, nil
+}
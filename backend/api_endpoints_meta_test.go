@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// A source failing to connect to its backend
+type unreachableSource struct {
+	expireCountingSource
+}
+
+func (self *unreachableSource) Status() (*api.StatusResponse, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func (self *unreachableSource) ConnectionStatus() *api.ConnectionStatus {
+	return &api.ConnectionStatus{
+		State:      "TRANSIENT_FAILURE",
+		Reconnects: 3,
+		LastError:  "connection refused",
+	}
+}
+
+func TestApiStatusConnectionFailed(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:       "rs1",
+				Name:     "rs1",
+				instance: &unreachableSource{},
+			},
+		},
+	}
+
+	params := httprouter.Params{httprouter.Param{Key: "id", Value: "rs1"}}
+	_, err := apiStatus(nil, params)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	response, _ := apiErrorResponse("rs1", err)
+	if response.Connection == nil {
+		t.Fatal("Expected connection status in error response")
+	}
+	if response.Connection.State != "TRANSIENT_FAILURE" ||
+		response.Connection.Reconnects != 3 {
+		t.Error("Unexpected connection status:", response.Connection)
+	}
+	if response.Message != "connection refused" {
+		t.Error("Unexpected message:", response.Message)
+	}
+}
+
+func TestApiStatusMaintenance(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:   "rs1",
+				Name: "rs1",
+				MaintenanceWindow: &MaintenanceWindow{
+					End:      24 * time.Hour,
+					Location: time.UTC,
+				},
+				instance: &unreachableSource{},
+			},
+		},
+	}
+
+	// The source is not queried
+	params := httprouter.Params{httprouter.Param{Key: "id", Value: "rs1"}}
+	result, err := apiStatus(nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.(*api.StatusResponse).Status.Maintenance {
+		t.Error("Expected source to be in maintenance")
+	}
+}
@@ -6,11 +6,12 @@ import (
 
 	"net/http"
 	"sort"
+	"time"
 )
 
 // Handle get neighbors on routeserver
 func apiNeighborsList(
-	_req *http.Request,
+	req *http.Request,
 	params httprouter.Params,
 ) (api.Response, error) {
 	rsId, err := validateSourceId(params.ByName("id"))
@@ -23,11 +24,12 @@ func apiNeighborsList(
 	// Try to fetch neighbors from store, only fall back
 	// to RS query if store is not ready yet
 	sourceStatus := AliceNeighboursStore.SourceStatus(rsId)
-	if sourceStatus.State == STATE_READY {
+	if sourceStatus.IsAvailable() {
+		t0 := time.Now()
 		neighbors := AliceNeighboursStore.GetNeighborsAt(rsId)
 		// Make response
 		neighborsResponse = &api.NeighboursResponse{
-			Api: api.ApiStatus{
+			Api: apiSourceTiming(api.ApiStatus{
 				Version: version,
 				CacheStatus: api.CacheStatus{
 					OrigTtl:  0,
@@ -35,8 +37,9 @@ func apiNeighborsList(
 				},
 				ResultFromCache: true, // you bet!
 				Ttl: sourceStatus.LastRefresh.Add(
-					AliceNeighboursStore.refreshInterval),
-			},
+					AliceNeighboursStore.SourceRefreshInterval(rsId)),
+				Stale: sourceStatus.Stale,
+			}, time.Since(t0)),
 			Neighbours: neighbors,
 		}
 	} else {
@@ -45,15 +48,139 @@ func apiNeighborsList(
 			return nil, SOURCE_NOT_FOUND_ERROR
 		}
 
-		neighborsResponse, err = source.Neighbours()
+		t0 := time.Now()
+		result, err := source.Neighbours()
 		if err != nil {
 			apiLogSourceError("neighbors", rsId, err)
 			return nil, err
 		}
+		annotateNeighboursResponse(AliceConfig.SourceById(rsId), result)
+
+		response := *result
+		response.Api = apiSourceTiming(result.Api, time.Since(t0))
+		neighborsResponse = &response
 	}
 
-	// Sort result
-	sort.Sort(&neighborsResponse.Neighbours)
+	// Count the distinct next hops from the routes store
+	if AliceRoutesStore != nil {
+		neighborsResponse.Neighbours = annotateNeighboursNextHops(
+			neighborsResponse.Neighbours,
+			AliceRoutesStore.DistinctNextHopsAt(rsId))
+	}
+	neighborsResponse.Neighbours = apiQueryFilterDistinctNextHops(
+		req, neighborsResponse.Neighbours)
+
+	// Sort result as requested or by the default of the source
+	defaultSort := ""
+	if source := AliceConfig.SourceById(rsId); source != nil {
+		defaultSort = source.NeighboursSort
+	}
+	err = apiQuerySortNeighbours(
+		req, neighborsResponse.Neighbours, defaultSort)
+	if err != nil {
+		return nil, err
+	}
+
+	neighborsResponse.Neighbours, err = apiQueryFormatUptime(
+		req, neighborsResponse.Neighbours)
+	if err != nil {
+		return nil, err
+	}
 
 	return neighborsResponse, nil
 }
+
+// Handle empty neighbours: List established sessions
+// without any received routes from the neighbours store.
+func apiNeighborsListEmpty(
+	_req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	sourceStatus := AliceNeighboursStore.SourceStatus(rsId)
+	neighbors := AliceNeighboursStore.EmptyNeighboursAt(rsId)
+	sort.Sort(neighbors)
+
+	response := &api.NeighboursResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: sourceStatus.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: sourceStatus.LastRefresh.Add(
+				AliceNeighboursStore.SourceRefreshInterval(rsId)),
+			Stale:   sourceStatus.Stale,
+			Loading: sourceStatus.IsLoading(),
+		},
+		Neighbours: neighbors,
+	}
+
+	return response, nil
+}
+
+// Handle empty neighbours from all sources
+func apiLookupEmptyNeighborsGlobal(
+	_req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	neighbors := AliceNeighboursStore.EmptyNeighbours()
+	sort.Sort(neighbors)
+
+	response := &api.NeighboursResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: AliceNeighboursStore.CachedAt(),
+			},
+			ResultFromCache: true,
+			Ttl:             AliceNeighboursStore.CacheTtl(),
+			Loading:         AliceNeighboursStore.IsLoading(),
+		},
+		Neighbours: neighbors,
+	}
+
+	return response, nil
+}
+
+// Handle neighbour states: Get a compact map of the
+// states of all neighbours from the neighbours store.
+func apiLookupNeighborsStatesGlobal(
+	_req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	return AliceNeighboursStore.NeighboursStates(), nil
+}
+
+// Handle neighbours groups: Get the neighbours of
+// all route servers in a group with aggregated counts.
+func apiLookupNeighborsGroupsGlobal(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	groups := AliceNeighboursStore.NeighboursGroups(
+		req.URL.Query().Get("group"))
+
+	response := &api.NeighboursGroupsResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: AliceNeighboursStore.CachedAt(),
+			},
+			ResultFromCache: true,
+			Ttl:             AliceNeighboursStore.CacheTtl(),
+			Loading:         AliceNeighboursStore.IsLoading(),
+		},
+		Groups: groups,
+	}
+
+	return response, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/alice-lg/alice-lg/backend/api"
 	"github.com/julienschmidt/httprouter"
 
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -21,12 +22,20 @@ func apiRoutesList(_req *http.Request, params httprouter.Params) (api.Response,
 		return nil, SOURCE_NOT_FOUND_ERROR
 	}
 
+	t0 := time.Now()
 	result, err := source.Routes(neighborId)
 	if err != nil {
 		apiLogSourceError("routes", rsId, neighborId, err)
+		return nil, err
 	}
+	latency := time.Since(t0)
+
+	result = annotateRoutesResponse(AliceConfig.SourceById(rsId), result)
+
+	response := *result
+	response.Api = apiSourceTiming(result.Api, latency)
 
-	return result, err
+	return &response, nil
 }
 
 // Paginated Routes Respponse: Received routes
@@ -48,14 +57,21 @@ func apiRoutesListReceived(
 		return nil, SOURCE_NOT_FOUND_ERROR
 	}
 
+	t1 := time.Now()
 	result, err := source.RoutesReceived(neighborId)
 	if err != nil {
 		apiLogSourceError("routes_received", rsId, neighborId, err)
 		return nil, err
 	}
+	latency := time.Since(t1)
+
+	result = annotateRoutesResponse(AliceConfig.SourceById(rsId), result)
 
 	// Filter routes based on criteria if present
-	allRoutes := apiQueryFilterNextHopGateway(req, "q", result.Imported)
+	allRoutes, err := applyRouteFilters(req, result.Imported)
+	if err != nil {
+		return nil, err
+	}
 	routes := api.Routes{}
 
 	// Apply other (commmunity) filters
@@ -77,10 +93,27 @@ func apiRoutesListReceived(
 	filtersApplied.MergeProperties(filtersAvailable)
 	filtersAvailable = filtersAvailable.Sub(filtersApplied)
 
+	// Sort as requested or by the default of the source
+	err = apiQuerySortRoutes(
+		req, routes, AliceConfig.SourceById(rsId).RoutesSort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Featured routes go first
+	sortFeaturedRoutes(routes, AliceConfig.FeaturedRoutes.Communities)
+
 	// Paginate results
 	page := apiQueryMustInt(req, "page", 0)
-	pageSize := AliceConfig.Ui.Pagination.RoutesAcceptedPageSize
+	pageSize, err := validatePageSize(
+		req, AliceConfig.Ui.Pagination.RoutesAcceptedPageSize)
+	if err != nil {
+		return nil, err
+	}
 	routes, pagination := apiPaginateRoutes(routes, page, pageSize)
+	routes = apiQueryResolveCommunities(req, routes)
+	routes = apiQueryFormatCommunities(req, routes)
+	routes = stripRoutesPrivateAsns(AliceConfig.SourceById(rsId), routes)
 
 	// Calculate query duration
 	queryDuration := time.Since(t0)
@@ -88,7 +121,7 @@ func apiRoutesListReceived(
 	// Make paginated response
 	response := api.PaginatedRoutesResponse{
 		RoutesResponse: &api.RoutesResponse{
-			Api:      result.Api,
+			Api:      apiSourceTiming(result.Api, latency),
 			Imported: routes,
 		},
 		TimedResponse: api.TimedResponse{
@@ -121,14 +154,21 @@ func apiRoutesListFiltered(
 		return nil, SOURCE_NOT_FOUND_ERROR
 	}
 
+	t1 := time.Now()
 	result, err := source.RoutesFiltered(neighborId)
 	if err != nil {
 		apiLogSourceError("routes_filtered", rsId, neighborId, err)
 		return nil, err
 	}
+	latency := time.Since(t1)
+
+	result = annotateRoutesResponse(AliceConfig.SourceById(rsId), result)
 
 	// Filter routes based on criteria if present
-	allRoutes := apiQueryFilterNextHopGateway(req, "q", result.Filtered)
+	allRoutes, err := applyRouteFilters(req, result.Filtered)
+	if err != nil {
+		return nil, err
+	}
 	routes := api.Routes{}
 
 	// Apply other (commmunity) filters
@@ -150,10 +190,27 @@ func apiRoutesListFiltered(
 	filtersApplied.MergeProperties(filtersAvailable)
 	filtersAvailable = filtersAvailable.Sub(filtersApplied)
 
+	// Sort as requested or by the default of the source
+	err = apiQuerySortRoutes(
+		req, routes, AliceConfig.SourceById(rsId).RoutesSort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Featured routes go first
+	sortFeaturedRoutes(routes, AliceConfig.FeaturedRoutes.Communities)
+
 	// Paginate results
 	page := apiQueryMustInt(req, "page", 0)
-	pageSize := AliceConfig.Ui.Pagination.RoutesFilteredPageSize
+	pageSize, err := validatePageSize(
+		req, AliceConfig.Ui.Pagination.RoutesFilteredPageSize)
+	if err != nil {
+		return nil, err
+	}
 	routes, pagination := apiPaginateRoutes(routes, page, pageSize)
+	routes = apiQueryResolveCommunities(req, routes)
+	routes = apiQueryFormatCommunities(req, routes)
+	routes = stripRoutesPrivateAsns(AliceConfig.SourceById(rsId), routes)
 
 	// Calculate query duration
 	queryDuration := time.Since(t0)
@@ -161,7 +218,7 @@ func apiRoutesListFiltered(
 	// Make response
 	response := api.PaginatedRoutesResponse{
 		RoutesResponse: &api.RoutesResponse{
-			Api:      result.Api,
+			Api:      apiSourceTiming(result.Api, latency),
 			Filtered: routes,
 		},
 		TimedResponse: api.TimedResponse{
@@ -194,14 +251,21 @@ func apiRoutesListNotExported(
 		return nil, SOURCE_NOT_FOUND_ERROR
 	}
 
+	t1 := time.Now()
 	result, err := source.RoutesNotExported(neighborId)
 	if err != nil {
 		apiLogSourceError("routes_not_exported", rsId, neighborId, err)
 		return nil, err
 	}
+	latency := time.Since(t1)
+
+	result = annotateRoutesResponse(AliceConfig.SourceById(rsId), result)
 
 	// Filter routes based on criteria if present
-	allRoutes := apiQueryFilterNextHopGateway(req, "q", result.NotExported)
+	allRoutes, err := applyRouteFilters(req, result.NotExported)
+	if err != nil {
+		return nil, err
+	}
 	routes := api.Routes{}
 
 	// Apply other (commmunity) filters
@@ -223,10 +287,27 @@ func apiRoutesListNotExported(
 	filtersApplied.MergeProperties(filtersAvailable)
 	filtersAvailable = filtersAvailable.Sub(filtersApplied)
 
+	// Sort as requested or by the default of the source
+	err = apiQuerySortRoutes(
+		req, routes, AliceConfig.SourceById(rsId).RoutesSort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Featured routes go first
+	sortFeaturedRoutes(routes, AliceConfig.FeaturedRoutes.Communities)
+
 	// Paginate results
 	page := apiQueryMustInt(req, "page", 0)
-	pageSize := AliceConfig.Ui.Pagination.RoutesNotExportedPageSize
+	pageSize, err := validatePageSize(
+		req, AliceConfig.Ui.Pagination.RoutesNotExportedPageSize)
+	if err != nil {
+		return nil, err
+	}
 	routes, pagination := apiPaginateRoutes(routes, page, pageSize)
+	routes = apiQueryResolveCommunities(req, routes)
+	routes = apiQueryFormatCommunities(req, routes)
+	routes = stripRoutesPrivateAsns(AliceConfig.SourceById(rsId), routes)
 
 	// Calculate query duration
 	queryDuration := time.Since(t0)
@@ -234,7 +315,7 @@ func apiRoutesListNotExported(
 	// Make response
 	response := api.PaginatedRoutesResponse{
 		RoutesResponse: &api.RoutesResponse{
-			Api:         result.Api,
+			Api:         apiSourceTiming(result.Api, latency),
 			NotExported: routes,
 		},
 		TimedResponse: api.TimedResponse{
@@ -249,3 +330,447 @@ func apiRoutesListNotExported(
 
 	return response, nil
 }
+
+// Routes received from a neighbour, which were filtered
+// or are no longer filtered since the previous refresh.
+func apiRoutesListDiff(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	neighborId := params.ByName("neighborId")
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	status := AliceRoutesStore.SourceStatus(rsId)
+	received, accepted := AliceRoutesStore.NeighbourRoutesAt(rsId, neighborId)
+	newlyFiltered, noLongerFiltered, since :=
+		AliceRoutesStore.NeighbourRoutesDiffAt(rsId, neighborId)
+
+	response := &api.RoutesDiffResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: status.LastRefresh.Add(
+				AliceRoutesStore.refreshInterval),
+			Stale:   status.Stale,
+			Loading: status.IsLoading(),
+		},
+		Received:         len(received),
+		Accepted:         len(accepted),
+		Since:            since,
+		NewlyFiltered:    newlyFiltered,
+		NoLongerFiltered: noLongerFiltered,
+	}
+
+	return response, nil
+}
+
+// Get all routes of a source tagged as candidates
+// for rejection from the store.
+func apiRoutesRejectCandidates(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	status := AliceRoutesStore.SourceStatus(rsId)
+
+	imported, filtered := AliceRoutesStore.LabeledRoutesAt(
+		rsId, AliceConfig.Ui.RoutesRejectCandidates.Communities)
+
+	response := &api.RoutesResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: status.LastRefresh.Add(
+				AliceRoutesStore.refreshInterval),
+			Stale:   status.Stale,
+			Loading: status.IsLoading(),
+		},
+		Imported: imported,
+		Filtered: filtered,
+	}
+
+	return response, nil
+}
+
+// Get all routes of a source covered by an aggregate
+// from the store. The aggregates are taken from the
+// aggregates query parameter or the config.
+func apiRoutesCoveredByAggregates(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	aggregates := AliceConfig.Aggregates.Prefixes
+	if value := req.URL.Query().Get("aggregates"); value != "" {
+		aggregates, err = parsePrefixList(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	status := AliceRoutesStore.SourceStatus(rsId)
+
+	imported, filtered := AliceRoutesStore.CoveredRoutesAt(rsId, aggregates)
+
+	response := &api.RoutesResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: status.LastRefresh.Add(
+				AliceRoutesStore.refreshInterval),
+			Stale:   status.Stale,
+			Loading: status.IsLoading(),
+		},
+		Imported: imported,
+		Filtered: filtered,
+	}
+
+	return response, nil
+}
+
+// Get neighbours sending duplicate paths from the store.
+// Only neighbours with at least min_duplicates (default: 1)
+// duplicate paths are included.
+func apiRoutesDuplicatePaths(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	status := AliceRoutesStore.SourceStatus(rsId)
+
+	minDuplicates := apiQueryMustInt(req, "min_duplicates", 1)
+	neighbours := AliceRoutesStore.DuplicatePathsAt(rsId, minDuplicates)
+
+	response := &api.DuplicatePathsResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: status.LastRefresh.Add(
+				AliceRoutesStore.refreshInterval),
+			Stale:   status.Stale,
+			Loading: status.IsLoading(),
+		},
+		Neighbours: neighbours,
+	}
+
+	return response, nil
+}
+
+// Serve all routes of a source from the store. The payload
+// is served directly, as it may be pre-serialized.
+func apiRoutesAll(
+	res http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) error {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if AliceConfig.SourceById(rsId) == nil {
+		return SOURCE_NOT_FOUND_ERROR
+	}
+
+	payload, err := AliceRoutesStore.AllRoutesPayloadAt(rsId)
+	if err != nil {
+		return err
+	}
+
+	return payload.WriteTo(res, req)
+}
+
+// Export the routes of a source from the store in MRT format.
+// This is a streamed endpoint, as the response is written
+// to the client while it is encoded.
+func apiRoutesExportMrt(
+	res http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) error {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if AliceConfig.SourceById(rsId) == nil {
+		return SOURCE_NOT_FOUND_ERROR
+	}
+
+	routes := AliceRoutesStore.ImportedRoutesAt(rsId)
+
+	res.Header().Set("Content-Type", "application/octet-stream")
+	res.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s.mrt\"", rsId))
+
+	w := &contextWriter{ctx: req.Context(), w: res}
+	return writeMrtTableDump(w, rsId, routes, time.Now())
+}
+
+// Get the RPKI validation summary of a source
+func apiRoutesRpkiSummary(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	status := AliceRoutesStore.SourceStatus(rsId)
+
+	response := &api.RpkiSummaryResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: status.LastRefresh.Add(
+				AliceRoutesStore.refreshInterval),
+			Stale:   status.Stale,
+			Loading: status.IsLoading(),
+		},
+		Summary: AliceRoutesStore.RpkiSummaryAt(rsId),
+	}
+
+	return response, nil
+}
+
+// Get the imported routes of a source grouped by origin
+// ASN. The prefixes of a group are included, when the
+// group is expanded with ?expand=<asn>.
+func apiRoutesOriginAsnGroups(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	expand := 0
+	if value := req.URL.Query().Get("expand"); value != "" {
+		expand, err = parseAsn(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	page := apiQueryMustInt(req, "page", 0)
+	pageSize, err := validatePageSize(
+		req, AliceConfig.Ui.Pagination.RoutesAcceptedPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := AliceRoutesStore.OriginAsnGroups(rsId)
+	expandOriginAsnGroups(groups, expand)
+	groups, pagination := apiPaginateOriginAsnGroups(groups, page, pageSize)
+
+	status := AliceRoutesStore.SourceStatus(rsId)
+	response := &api.OriginAsnGroupsResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: status.LastRefresh.Add(
+				AliceRoutesStore.refreshInterval),
+			Stale:   status.Stale,
+			Loading: status.IsLoading(),
+		},
+		Groups:     groups,
+		Pagination: pagination,
+	}
+
+	return response, nil
+}
+
+// Get the prefixes with multiple origin ASNs of a source
+func apiRoutesMoasPrefixes(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	status := AliceRoutesStore.SourceStatus(rsId)
+	response := &api.MoasPrefixesResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: status.LastRefresh.Add(
+				AliceRoutesStore.refreshInterval),
+			Stale:   status.Stale,
+			Loading: status.IsLoading(),
+		},
+		Prefixes: AliceRoutesStore.MoasPrefixes(rsId),
+	}
+
+	return response, nil
+}
+
+// Get the prefixes of a source with a changed origin
+// ASN since the previous refresh of the routes store
+func apiRoutesOriginChanges(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	rsId, err := validateSourceId(params.ByName("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if AliceConfig.SourceById(rsId) == nil {
+		return nil, SOURCE_NOT_FOUND_ERROR
+	}
+
+	status := AliceRoutesStore.SourceStatus(rsId)
+	response := &api.OriginChangesResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl: status.LastRefresh.Add(
+				AliceRoutesStore.refreshInterval),
+			Stale:   status.Stale,
+			Loading: status.IsLoading(),
+		},
+		Changes: AliceRoutesStore.OriginChangesAt(rsId),
+	}
+
+	return response, nil
+}
+
+// Get the prefixes with multiple origin ASNs
+// across all sources.
+func apiMoasPrefixesGlobal(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	response := &api.MoasPrefixesResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: AliceRoutesStore.CachedAt(),
+			},
+			ResultFromCache: true,
+			Ttl:             AliceRoutesStore.CacheTtl(),
+			Loading:         AliceRoutesStore.IsLoading(),
+		},
+		Prefixes: AliceRoutesStore.MoasPrefixes(""),
+	}
+
+	return response, nil
+}
+
+// Get the RPKI validation summaries of all sources
+// and the network wide summary.
+func apiRpkiSummaryGlobal(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	sources, total := AliceRoutesStore.RpkiSummaries()
+
+	response := &api.RpkiSummaryResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: AliceRoutesStore.CachedAt(),
+			},
+			ResultFromCache: true,
+			Ttl:             AliceRoutesStore.CacheTtl(),
+			Loading:         AliceRoutesStore.IsLoading(),
+		},
+		Summary: total,
+		Sources: sources,
+	}
+
+	return response, nil
+}
+
+// Get the route counts per address family of all sources
+func apiAfiCountsGlobal(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	sources, total := AliceRoutesStore.AfiCounts()
+
+	response := &api.AfiCountsResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: AliceRoutesStore.CachedAt(),
+			},
+			ResultFromCache: true,
+			Ttl:             AliceRoutesStore.CacheTtl(),
+			Loading:         AliceRoutesStore.IsLoading(),
+		},
+		Total:   total,
+		Sources: sources,
+	}
+
+	return response, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Use the test routes store while the source rs1
+// is refreshed. The returned function restores the
+// previous config and store.
+func useRefreshingTestRoutesStore() func() {
+	restore := saveTestGlobals()
+
+	store := makeTestRoutesStore()
+	store.statusMap["rs1"] = StoreStatus{
+		State:       STATE_READY,
+		LastRefresh: time.Now(),
+	}.refreshing()
+
+	AliceRoutesStore = store
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{store.configMap["rs1"]},
+	}
+
+	return restore
+}
+
+var testRs1Params = httprouter.Params{
+	httprouter.Param{Key: "id", Value: "rs1"},
+}
+
+func TestApiRoutesRejectCandidatesRefreshing(t *testing.T) {
+	defer useRefreshingTestRoutesStore()()
+
+	result, err := apiRoutesRejectCandidates(nil, testRs1Params)
+	if err != nil {
+		t.Fatal("Expected the routes to be served, got:", err)
+	}
+	response := result.(*api.RoutesResponse)
+	if response.Api.Loading {
+		t.Error("Expected the source not to be loading")
+	}
+}
+
+func TestApiRoutesCoveredByAggregatesRefreshing(t *testing.T) {
+	defer useRefreshingTestRoutesStore()()
+
+	req := httptest.NewRequest("GET",
+		"/api/v1/routeservers/rs1/covered-routes?aggregates=0.0.0.0/0", nil)
+	result, err := apiRoutesCoveredByAggregates(req, testRs1Params)
+	if err != nil {
+		t.Fatal("Expected the routes to be served, got:", err)
+	}
+	response := result.(*api.RoutesResponse)
+	if len(response.Imported) == 0 {
+		t.Error("Expected covered routes")
+	}
+}
+
+func TestApiRoutesDuplicatePathsRefreshing(t *testing.T) {
+	defer useRefreshingTestRoutesStore()()
+
+	req := httptest.NewRequest("GET",
+		"/api/v1/routeservers/rs1/duplicate-paths", nil)
+	result, err := apiRoutesDuplicatePaths(req, testRs1Params)
+	if err != nil {
+		t.Fatal("Expected the duplicate paths to be served, got:", err)
+	}
+	response := result.(*api.DuplicatePathsResponse)
+	if response.Api.Loading {
+		t.Error("Expected the source not to be loading")
+	}
+}
+
+func TestApiRoutesRpkiSummaryRefreshing(t *testing.T) {
+	defer useRefreshingTestRoutesStore()()
+
+	result, err := apiRoutesRpkiSummary(nil, testRs1Params)
+	if err != nil {
+		t.Fatal("Expected the summary to be served, got:", err)
+	}
+	response := result.(*api.RpkiSummaryResponse)
+	if response.Api.Loading {
+		t.Error("Expected the source not to be loading")
+	}
+}
+
+func TestApiRoutesExportMrtRefreshing(t *testing.T) {
+	defer useRefreshingTestRoutesStore()()
+
+	req := httptest.NewRequest("GET",
+		"/api/v1/routeservers/rs1/export/mrt", nil)
+	res := httptest.NewRecorder()
+	if err := apiRoutesExportMrt(res, req, testRs1Params); err != nil {
+		t.Fatal("Expected the routes to be exported, got:", err)
+	}
+	if res.Body.Len() == 0 {
+		t.Error("Expected a MRT table dump")
+	}
+}
@@ -16,11 +16,16 @@ func apiRouteserversList(_req *http.Request, _params httprouter.Params) (api.Res
 	sources := AliceConfig.Sources
 	for _, source := range sources {
 		routeservers = append(routeservers, api.Routeserver{
-			Id:         source.Id,
-			Name:       source.Name,
-			Group:      source.Group,
-			Blackholes: source.Blackholes,
-			Order:      source.Order,
+			Id:             source.Id,
+			Name:           source.Name,
+			Group:          source.Group,
+			Blackholes:     source.getBlackholeNextHops(),
+			TimeConfig:     source.getTimeConfig(),
+			LookupMode:     source.getLookupMode(),
+			RoutesSort:     source.RoutesSort,
+			NeighboursSort: source.NeighboursSort,
+			RouteTabs:      source.getRouteTabs(),
+			Order:          source.Order,
 		})
 	}
 
@@ -34,3 +39,28 @@ func apiRouteserversList(_req *http.Request, _params httprouter.Params) (api.Res
 
 	return response, nil
 }
+
+// Handle Sources List: This is a lightweight
+// variant of the routeservers list for the layout
+func apiSourcesList(_req *http.Request, _params httprouter.Params) (api.Response, error) {
+	sources := make([]api.Source, 0, len(AliceConfig.Sources))
+	for _, source := range AliceConfig.Sources {
+		sources = append(sources, api.Source{
+			Id:    source.Id,
+			Name:  source.Name,
+			Group: source.Group,
+			Order: source.Order,
+			Type:  source.getTypeName(),
+		})
+	}
+
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Order < sources[j].Order
+	})
+
+	response := api.SourcesResponse{
+		Sources: sources,
+	}
+
+	return response, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestApiRouteserversListTimeConfig(t *testing.T) {
+	defer saveTestGlobals()()
+	config, err := loadConfig("../etc/alice-lg/alice.example.conf")
+	if err != nil {
+		t.Fatal("Could not load test config:", err)
+	}
+	AliceConfig = config
+
+	result, err := apiRouteserversList(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := result.(api.RouteserversResponse)
+	for _, rs := range response.Routeservers {
+		source := AliceConfig.SourceById(rs.Id)
+		if source.Type != SOURCE_BIRDWATCHER {
+			continue
+		}
+		if rs.TimeConfig == nil {
+			t.Error("Expected time config for", rs.Id)
+			continue
+		}
+		if rs.TimeConfig.Timezone != source.Birdwatcher.Timezone {
+			t.Error("Unexpected timezone for", rs.Id, ":", rs.TimeConfig.Timezone)
+		}
+		if rs.TimeConfig.ServerTime != source.Birdwatcher.ServerTime {
+			t.Error("Unexpected server time format:", rs.TimeConfig.ServerTime)
+		}
+	}
+
+	// The IPv6 source overrides the default timezone
+	for _, rs := range response.Routeservers {
+		if rs.Id == "rs1-example-v6" &&
+			rs.TimeConfig.Timezone != "Europe/Brussels" {
+			t.Error("Expected 'Europe/Brussels', got:", rs.TimeConfig.Timezone)
+		}
+	}
+}
+
+func TestApiSourcesList(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:    "rs2",
+				Name:  "rs2.example.com",
+				Group: "FRA",
+				Order: 1,
+				Type:  SOURCE_GOBGP,
+			},
+			&SourceConfig{
+				Id:    "rs1",
+				Name:  "rs1.example.com",
+				Group: "AMS",
+				Order: 0,
+				Type:  SOURCE_BIRDWATCHER,
+			},
+		},
+	}
+
+	result, err := apiSourcesList(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := result.(api.SourcesResponse).Sources
+	if len(sources) != 2 {
+		t.Fatal("Expected 2 sources, got:", len(sources))
+	}
+
+	if sources[0].Id != "rs1" || sources[1].Id != "rs2" {
+		t.Error("Unexpected source order:", sources)
+	}
+	if sources[0].Group != "AMS" || sources[0].Type != "birdwatcher" {
+		t.Error("Unexpected metadata:", sources[0])
+	}
+	if sources[1].Name != "rs2.example.com" || sources[1].Type != "gobgp" {
+		t.Error("Unexpected metadata:", sources[1])
+	}
+}
+
+func TestApiRouteserversListRouteTabs(t *testing.T) {
+	defer saveTestGlobals()()
+	tabs, err := parseRouteTabs("imported")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{Id: "rs1", Order: 0},
+			&SourceConfig{Id: "ris", Order: 1, RouteTabs: tabs},
+		},
+	}
+
+	result, err := apiRouteserversList(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := result.(api.RouteserversResponse)
+
+	if len(response.Routeservers[0].RouteTabs) != 3 {
+		t.Error("Expected all route tabs for rs1, got:",
+			response.Routeservers[0].RouteTabs)
+	}
+	ris := response.Routeservers[1].RouteTabs
+	if len(ris) != 1 || ris[0] != ROUTE_TAB_IMPORTED {
+		t.Error("Expected only the imported tab, got:", ris)
+	}
+
+	if _, err := parseRouteTabs("imported, accepted"); err == nil {
+		t.Error("Expected unknown route tab to be rejected")
+	}
+}
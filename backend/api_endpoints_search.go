@@ -4,6 +4,7 @@ import (
 	"github.com/alice-lg/alice-lg/backend/api"
 	"github.com/julienschmidt/httprouter"
 
+	"net"
 	"net/http"
 	"sort"
 	"time"
@@ -51,6 +52,9 @@ func apiLookupPrefixGlobal(
 		routes = AliceRoutesStore.LookupPrefixForNeighbours(neighbours)
 	}
 
+	// Cap paths per prefix
+	routes = apiQueryLimitPathsPerPrefix(req, routes)
+
 	// Split routes
 	// TODO: Refactor at neighbors store
 	totalResults := len(routes)
@@ -86,18 +90,38 @@ func apiLookupPrefixGlobal(
 	sort.Sort(imported)
 	sort.Sort(filtered)
 
+	featured := AliceConfig.FeaturedRoutes.Communities
+	sortFeaturedLookupRoutes(imported, featured)
+	sortFeaturedLookupRoutes(filtered, featured)
+
 	// Paginate results
 	pageImported := apiQueryMustInt(req, "page_imported", 0)
-	pageSizeImported := AliceConfig.Ui.Pagination.RoutesAcceptedPageSize
+	pageSizeImported, err := validatePageSize(
+		req, AliceConfig.Ui.Pagination.RoutesAcceptedPageSize)
+	if err != nil {
+		return nil, err
+	}
 	routesImported, paginationImported := apiPaginateLookupRoutes(
 		imported, pageImported, pageSizeImported,
 	)
+	routesImported = apiQueryResolveLookupCommunities(req, routesImported)
+	routesImported = apiQueryFormatLookupCommunities(req, routesImported)
+	routesImported = stripLookupRoutesPrivateAsns(routesImported)
 
 	pageFiltered := apiQueryMustInt(req, "page_filtered", 0)
-	pageSizeFiltered := AliceConfig.Ui.Pagination.RoutesFilteredPageSize
+	pageSizeFiltered, err := validatePageSize(
+		req, AliceConfig.Ui.Pagination.RoutesFilteredPageSize)
+	if err != nil {
+		return nil, err
+	}
 	routesFiltered, paginationFiltered := apiPaginateLookupRoutes(
 		filtered, pageFiltered, pageSizeFiltered,
 	)
+	routesFiltered = apiQueryResolveLookupCommunities(req, routesFiltered)
+	routesFiltered = apiQueryFormatLookupCommunities(req, routesFiltered)
+	routesFiltered = stripLookupRoutesPrivateAsns(routesFiltered)
+
+	skippedSources := AliceRoutesStore.SkippedLookupSources()
 
 	// Calculate query duration
 	queryDuration := time.Since(t0)
@@ -110,6 +134,7 @@ func apiLookupPrefixGlobal(
 			},
 			ResultFromCache: true, // Well.
 			Ttl:             AliceRoutesStore.CacheTtl(),
+			Loading:         AliceRoutesStore.IsLoading(),
 		},
 		TimedResponse: api.TimedResponse{
 			RequestDuration: DurationMs(queryDuration),
@@ -130,6 +155,110 @@ func apiLookupPrefixGlobal(
 			FiltersAvailable: filtersAvailable,
 			FiltersApplied:   filtersApplied,
 		},
+		SourcesSkipped: len(skippedSources) > 0,
+		SkippedSources: skippedSources,
+	}
+
+	return response, nil
+}
+
+// Handle destination lookup: Get the covering
+// route for an address from all sources.
+func apiLookupDestinationGlobal(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	q, err := validateQueryString(req, "q")
+	if err != nil {
+		return nil, err
+	}
+
+	ip := net.ParseIP(q)
+	if ip == nil {
+		return nil, &InvalidDestinationError{Destination: q}
+	}
+
+	routes := AliceRoutesStore.LookupDestination(ip)
+	routes = apiQueryLimitPathsPerPrefix(req, routes)
+	routes = stripLookupRoutesPrivateAsns(routes)
+
+	response := &api.RoutesLookupResponse{
+		Api: api.ApiStatus{
+			CacheStatus: api.CacheStatus{
+				CachedAt: AliceRoutesStore.CachedAt(),
+			},
+			ResultFromCache: true,
+			Ttl:             AliceRoutesStore.CacheTtl(),
+			Loading:         AliceRoutesStore.IsLoading(),
+		},
+		Routes:         routes,
+		SkippedSources: AliceRoutesStore.SkippedLookupSources(),
+	}
+
+	return response, nil
+}
+
+// Handle origin lookup: Get all prefixes originated
+// by an ASN with the sources carrying them.
+func apiLookupOriginGlobal(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	value, err := validateQueryString(req, "asn")
+	if err != nil {
+		return nil, err
+	}
+	asn, err := parseAsn(value)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := AliceRoutesStore.OriginatedPrefixes(asn)
+
+	response := &api.OriginatedPrefixesResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: AliceRoutesStore.CachedAt(),
+			},
+			ResultFromCache: true,
+			Ttl:             AliceRoutesStore.CacheTtl(),
+			Loading:         AliceRoutesStore.IsLoading(),
+		},
+		Asn:      asn,
+		Prefixes: prefixes,
+	}
+
+	return response, nil
+}
+
+// Handle next hop lookup: Get the sources with
+// routes via a next hop address or network.
+func apiLookupNextHopGlobal(
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	q, err := validateQueryString(req, "q")
+	if err != nil {
+		return nil, err
+	}
+	network, err := parseNextHopQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &api.NextHopSourcesResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: AliceRoutesStore.CachedAt(),
+			},
+			ResultFromCache: true,
+			Ttl:             AliceRoutesStore.CacheTtl(),
+			Loading:         AliceRoutesStore.IsLoading(),
+		},
+		NextHop: q,
+		Sources: AliceRoutesStore.NextHopSources(network),
 	}
 
 	return response, nil
@@ -145,6 +274,11 @@ func apiLookupNeighborsGlobal(
 
 	sort.Sort(neighbors)
 
+	neighbors, err := apiQueryFormatUptime(req, neighbors)
+	if err != nil {
+		return nil, err
+	}
+
 	// Make response
 	response := &api.NeighboursResponse{
 		Api: api.ApiStatus{
@@ -153,6 +287,7 @@ func apiLookupNeighborsGlobal(
 			},
 			ResultFromCache: true, // You would not have guessed.
 			Ttl:             AliceNeighboursStore.CacheTtl(),
+			Loading:         AliceNeighboursStore.IsLoading(),
 		},
 		Neighbours: neighbors,
 	}
@@ -6,9 +6,13 @@ package main
 // to internal IP addresses.
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alice-lg/alice-lg/backend/api"
 )
@@ -21,11 +25,129 @@ func (self *ResourceNotFoundError) Error() string {
 
 var SOURCE_NOT_FOUND_ERROR = &ResourceNotFoundError{}
 
+type UnknownQueryParamsError struct {
+	Params []string
+}
+
+func (self *UnknownQueryParamsError) Error() string {
+	return "unknown query parameters: " + strings.Join(self.Params, ", ")
+}
+
+type RouteAgeNotAvailableError struct{}
+
+func (self *RouteAgeNotAvailableError) Error() string {
+	return "age not available for this source"
+}
+
+var ROUTE_AGE_NOT_AVAILABLE_ERROR = &RouteAgeNotAvailableError{}
+
+type InvalidPageSizeError struct {
+	PageSize    string
+	MaxPageSize int
+}
+
+func (self *InvalidPageSizeError) Error() string {
+	return fmt.Sprintf(
+		"invalid page_size: %s (must be between 1 and %d)",
+		self.PageSize, self.MaxPageSize)
+}
+
+type InvalidSortError struct {
+	Sort string
+}
+
+func (self *InvalidSortError) Error() string {
+	return fmt.Sprintf("invalid sort key: %s", self.Sort)
+}
+
+type InvalidPrefixError struct {
+	Prefix string
+}
+
+func (self *InvalidPrefixError) Error() string {
+	return fmt.Sprintf("invalid prefix: %s", self.Prefix)
+}
+
+type InvalidDestinationError struct {
+	Destination string
+}
+
+func (self *InvalidDestinationError) Error() string {
+	return fmt.Sprintf("invalid destination, not an IP address: %s",
+		self.Destination)
+}
+
+type InvalidFormatError struct {
+	Format string
+}
+
+func (self *InvalidFormatError) Error() string {
+	return fmt.Sprintf("invalid format: %s", self.Format)
+}
+
+type InvalidAsnError struct {
+	Asn string
+}
+
+func (self *InvalidAsnError) Error() string {
+	return fmt.Sprintf("invalid asn: %s", self.Asn)
+}
+
+type InvalidReceivedWithinError struct {
+	ReceivedWithin string
+}
+
+func (self *InvalidReceivedWithinError) Error() string {
+	return fmt.Sprintf(
+		"invalid received_within: %s (expected minutes or a duration)",
+		self.ReceivedWithin)
+}
+
+// An error of a source providing connection diagnostics
+type SourceConnectionError struct {
+	Err        error
+	Connection *api.ConnectionStatus
+}
+
+func (self *SourceConnectionError) Error() string {
+	return self.Err.Error()
+}
+
+// The request can not be served right now
+type ServiceUnavailableError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (self *ServiceUnavailableError) Error() string {
+	return "service unavailable: " + self.Reason
+}
+
+// Get the value for the Retry-After header
+func (self *ServiceUnavailableError) RetryAfterSeconds() string {
+	seconds := int(math.Ceil(self.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
+
+type RequestBodyTooLargeError struct {
+	Limit int64
+}
+
+func (self *RequestBodyTooLargeError) Error() string {
+	return fmt.Sprintf("request body exceeds the limit of %d bytes", self.Limit)
+}
+
 const (
 	GENERIC_ERROR_TAG      = "GENERIC_ERROR"
 	CONNECTION_REFUSED_TAG = "CONNECTION_REFUSED"
 	CONNECTION_TIMEOUT_TAG = "CONNECTION_TIMEOUT"
 	RESOURCE_NOT_FOUND_TAG = "NOT_FOUND"
+	BAD_REQUEST_TAG        = "BAD_REQUEST"
+	UNAVAILABLE_TAG        = "SERVICE_UNAVAILABLE"
+	TOO_LARGE_TAG          = "REQUEST_TOO_LARGE"
 )
 
 const (
@@ -33,14 +155,26 @@ const (
 	CONNECTION_REFUSED_CODE = 100
 	CONNECTION_TIMEOUT_CODE = 101
 	RESOURCE_NOT_FOUND_CODE = 404
+	BAD_REQUEST_CODE        = 400
+	UNAVAILABLE_CODE        = 503
+	TOO_LARGE_CODE          = 413
 )
 
 const (
 	ERROR_STATUS              = http.StatusInternalServerError
 	RESOURCE_NOT_FOUND_STATUS = http.StatusNotFound
+	BAD_REQUEST_STATUS        = http.StatusBadRequest
+	UNAVAILABLE_STATUS        = http.StatusServiceUnavailable
+	TOO_LARGE_STATUS          = http.StatusRequestEntityTooLarge
 )
 
 func apiErrorResponse(routeserverId string, err error) (api.ErrorResponse, int) {
+	var connection *api.ConnectionStatus
+	if e, ok := err.(*SourceConnectionError); ok {
+		connection = e.Connection
+		err = e.Err
+	}
+
 	code := GENERIC_ERROR_CODE
 	message := err.Error()
 	tag := GENERIC_ERROR_TAG
@@ -51,6 +185,26 @@ func apiErrorResponse(routeserverId string, err error) (api.ErrorResponse, int)
 		tag = RESOURCE_NOT_FOUND_TAG
 		code = RESOURCE_NOT_FOUND_CODE
 		status = RESOURCE_NOT_FOUND_STATUS
+	case *UnknownQueryParamsError,
+		*RouteAgeNotAvailableError,
+		*InvalidPageSizeError,
+		*InvalidSortError,
+		*InvalidPrefixError,
+		*InvalidDestinationError,
+		*InvalidFormatError,
+		*InvalidAsnError,
+		*InvalidReceivedWithinError:
+		tag = BAD_REQUEST_TAG
+		code = BAD_REQUEST_CODE
+		status = BAD_REQUEST_STATUS
+	case *RequestBodyTooLargeError:
+		tag = TOO_LARGE_TAG
+		code = TOO_LARGE_CODE
+		status = TOO_LARGE_STATUS
+	case *ServiceUnavailableError:
+		tag = UNAVAILABLE_TAG
+		code = UNAVAILABLE_CODE
+		status = UNAVAILABLE_STATUS
 	case *url.Error:
 		if strings.Contains(message, "connection refused") {
 			tag = CONNECTION_REFUSED_TAG
@@ -68,5 +222,6 @@ func apiErrorResponse(routeserverId string, err error) (api.ErrorResponse, int)
 		Tag:           tag,
 		Message:       message,
 		RouteserverId: routeserverId,
+		Connection:    connection,
 	}, status
 }
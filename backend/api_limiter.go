@@ -0,0 +1,115 @@
+package main
+
+/*
+Limit the number of concurrent expensive api requests.
+
+Requests exceeding the limit are queued. When the queue
+is full or a request waited for too long, the request
+is rejected as the service is unavailable.
+*/
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+type requestLimiter struct {
+	slots   chan bool
+	queue   chan bool
+	timeout time.Duration
+}
+
+// Make a new limiter, a limit of 0 disables the limiter
+func newRequestLimiter(
+	limit int,
+	queueDepth int,
+	timeout time.Duration,
+) *requestLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	if queueDepth < 0 {
+		queueDepth = 0
+	}
+
+	return &requestLimiter{
+		slots:   make(chan bool, limit),
+		queue:   make(chan bool, queueDepth),
+		timeout: timeout,
+	}
+}
+
+// Acquire a slot or wait in the queue for a free slot
+func (self *requestLimiter) Acquire() error {
+	// Try to get a slot right away
+	select {
+	case self.slots <- true:
+		return nil
+	default:
+	}
+
+	// Enter the queue
+	select {
+	case self.queue <- true:
+	default:
+		return &ServiceUnavailableError{
+			Reason:     "too many requests",
+			RetryAfter: self.timeout,
+		}
+	}
+	defer func() { <-self.queue }()
+
+	timeout := time.NewTimer(self.timeout)
+	defer timeout.Stop()
+
+	select {
+	case self.slots <- true:
+		return nil
+	case <-timeout.C:
+		return &ServiceUnavailableError{
+			Reason:     "timeout while waiting for request slot",
+			RetryAfter: self.timeout,
+		}
+	}
+}
+
+// Release the slot
+func (self *requestLimiter) Release() {
+	<-self.slots
+}
+
+// Wrap an api endpoint with the limiter. The slot is
+// released when the handler returns: A handler still
+// running after the request timed out keeps its slot.
+func limitedEndpoint(
+	limiter *requestLimiter,
+	wrapped apiEndpoint,
+) apiEndpoint {
+	if limiter == nil {
+		return wrapped
+	}
+	return func(
+		req *http.Request,
+		params httprouter.Params,
+	) (api.Response, error) {
+		if err := limiter.Acquire(); err != nil {
+			return nil, err
+		}
+
+		defer limiter.Release()
+
+		return wrapped(req, params)
+	}
+}
+
+// Make the limiter for the expensive endpoints
+// from the server config
+func newApiRequestLimiter(config ServerConfig) *requestLimiter {
+	return newRequestLimiter(
+		config.MaxConcurrentRequests,
+		config.MaxQueuedRequests,
+		time.Duration(config.RequestQueueTimeout)*time.Second)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestRequestLimiterQueue(t *testing.T) {
+	limiter := newRequestLimiter(1, 1, time.Second)
+
+	if err := limiter.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next request is queued
+	queued := make(chan error)
+	go func() {
+		queued <- limiter.Acquire()
+	}()
+
+	select {
+	case <-queued:
+		t.Fatal("Expected request to wait in queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The queue is full
+	err := limiter.Acquire()
+	if _, ok := err.(*ServiceUnavailableError); !ok {
+		t.Error("Expected service unavailable error, got:", err)
+	}
+
+	// Releasing the slot serves the queued request
+	limiter.Release()
+	if err := <-queued; err != nil {
+		t.Error("Expected queued request to be served, got:", err)
+	}
+	limiter.Release()
+}
+
+func TestRequestLimiterTimeout(t *testing.T) {
+	limiter := newRequestLimiter(1, 1, 20*time.Millisecond)
+	limiter.Acquire()
+	defer limiter.Release()
+
+	err := limiter.Acquire()
+	if _, ok := err.(*ServiceUnavailableError); !ok {
+		t.Error("Expected timeout error, got:", err)
+	}
+}
+
+func TestRequestLimiterDisabled(t *testing.T) {
+	if newRequestLimiter(0, 10, time.Second) != nil {
+		t.Error("Expected limiter to be disabled")
+	}
+}
+
+func TestLimitedEndpointOverflow(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	limiter := newRequestLimiter(1, 0, 2*time.Second)
+
+	started := make(chan bool)
+	done := make(chan bool)
+	handler := endpoint(limitedEndpoint(limiter, func(
+		_req *http.Request,
+		_params httprouter.Params,
+	) (api.Response, error) {
+		started <- true
+		<-done
+		return "ok", nil
+	}))
+
+	// Occupy the only slot
+	finished := make(chan bool)
+	go func() {
+		req := httptest.NewRequest("GET", "/api/v1/lookup/prefix", nil)
+		handler(httptest.NewRecorder(), req, nil)
+		close(finished)
+	}()
+	<-started
+
+	req := httptest.NewRequest("GET", "/api/v1/lookup/prefix", nil)
+	res := httptest.NewRecorder()
+	handler(res, req, nil)
+	close(done)
+	<-finished
+
+	if res.Code != http.StatusServiceUnavailable {
+		t.Error("Expected 503, got:", res.Code)
+	}
+	if res.Header().Get("Retry-After") != "2" {
+		t.Error("Expected Retry-After header, got:",
+			res.Header().Get("Retry-After"))
+	}
+}
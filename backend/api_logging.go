@@ -3,9 +3,20 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
+	"time"
 )
 
+// Log an api request
+func apiLogAccess(req *http.Request, status int, duration time.Duration) {
+	log.Println(fmt.Sprintf(
+		"API ACCESS :: %s %s %s :: %d (%v)",
+		apiLogClientAddress(req), req.Method, req.URL.RequestURI(),
+		status, duration,
+	))
+}
+
 // Log an api error
 func apiLogSourceError(module string, sourceId string, params ...interface{}) {
 	var err error
@@ -6,6 +6,7 @@ import (
 )
 
 func TestApiLogSourceError(t *testing.T) {
+	defer saveTestGlobals()()
 	err := fmt.Errorf("an unexpected error occured")
 
 	conf := &Config{
@@ -0,0 +1,188 @@
+package main
+
+/*
+Streaming prefix lookups
+
+The results of a prefix lookup are written per source
+as soon as they are available, instead of buffering
+the entire result set. The routes are encoded as a
+json array or as newline delimited json (format=ndjson).
+
+When a page is requested (page, page_size), only the
+routes within the page are streamed. The sources are
+streamed in a stable order, so pages are consistent.
+*/
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	LOOKUP_STREAM_FORMAT_JSON   = "json"
+	LOOKUP_STREAM_FORMAT_NDJSON = "ndjson"
+)
+
+// Encode lookup routes to the response
+type lookupStreamWriter struct {
+	res    http.ResponseWriter
+	ndjson bool
+	count  int
+}
+
+func newLookupStreamWriter(
+	res http.ResponseWriter,
+	format string,
+) *lookupStreamWriter {
+	w := &lookupStreamWriter{
+		res:    res,
+		ndjson: format == LOOKUP_STREAM_FORMAT_NDJSON,
+	}
+	if w.ndjson {
+		res.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		res.Header().Set("Content-Type", "application/json")
+		res.Write([]byte("["))
+	}
+	return w
+}
+
+// Write a route
+func (self *lookupStreamWriter) Write(route *api.LookupRoute) error {
+	payload, err := json.Marshal(route)
+	if err != nil {
+		return err
+	}
+	if self.ndjson {
+		payload = append(payload, '\n')
+	} else if self.count > 0 {
+		self.res.Write([]byte(","))
+	}
+	self.count++
+	_, err = self.res.Write(payload)
+	return err
+}
+
+// Send the buffered routes to the client
+func (self *lookupStreamWriter) Flush() {
+	if flusher, ok := self.res.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// End the stream
+func (self *lookupStreamWriter) Close() {
+	if !self.ndjson {
+		self.res.Write([]byte("]"))
+	}
+	self.Flush()
+}
+
+// Get the range of the requested page. Without
+// a page, all routes are included (end < 0).
+func apiLookupStreamRange(req *http.Request) (int, int, error) {
+	query := req.URL.Query()
+	if query.Get("page") == "" && query.Get("page_size") == "" {
+		return 0, -1, nil
+	}
+
+	page := apiQueryMustInt(req, "page", 0)
+	if page < 0 {
+		page = 0
+	}
+	pageSize, err := validatePageSize(
+		req, AliceConfig.Ui.Pagination.RoutesAcceptedPageSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	start := page * pageSize
+	return start, start + pageSize, nil
+}
+
+// Handle streaming prefix lookup. The stream ends
+// when the request times out.
+func apiLookupPrefixStream(
+	res http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) error {
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = LOOKUP_STREAM_FORMAT_JSON
+	}
+
+	q, err := validateQueryString(req, "q")
+	if err == nil {
+		q, err = validatePrefixQuery(q)
+	}
+	var filtersApplied *api.SearchFilters
+	if err == nil {
+		filtersApplied, err = api.FiltersFromQuery(req.URL.Query())
+	}
+	start, end := 0, -1
+	if err == nil {
+		start, end, err = apiLookupStreamRange(req)
+	}
+	if err == nil &&
+		format != LOOKUP_STREAM_FORMAT_JSON &&
+		format != LOOKUP_STREAM_FORMAT_NDJSON {
+		err = &InvalidFormatError{Format: format}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Sources not queried due to the lookup sources limit
+	skipped := AliceRoutesStore.SkippedLookupSources()
+	if len(skipped) > 0 {
+		res.Header().Set(
+			"X-Alice-Skipped-Sources", strings.Join(skipped, ","))
+	}
+
+	w := newLookupStreamWriter(res, format)
+	defer w.Close()
+
+	offset := 0
+	AliceRoutesStore.LookupPrefixEach(q, func(
+		sourceId string,
+		routes api.LookupRoutes,
+	) bool {
+		if req.Context().Err() != nil {
+			err = req.Context().Err()
+			return false
+		}
+		routes = apiQueryLimitPathsPerPrefix(req, routes)
+		routes = apiQueryResolveLookupCommunities(req, routes)
+		matching := make(api.LookupRoutes, 0, len(routes))
+		for _, route := range routes {
+			if !filtersApplied.MatchRoute(route) {
+				continue
+			}
+			if end >= 0 && offset >= end {
+				break // Page is complete
+			}
+			if offset >= start {
+				matching = append(matching, route)
+			}
+			offset++
+		}
+
+		// Communities are formatted after filtering
+		matching = apiQueryFormatLookupCommunities(req, matching)
+		matching = stripLookupRoutesPrivateAsns(matching)
+		for _, route := range matching {
+			if err = w.Write(route); err != nil {
+				return false
+			}
+		}
+		w.Flush()
+		return end < 0 || offset < end
+	})
+
+	return err
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Record the size of the body on each flush
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []int
+}
+
+func (self *flushRecorder) Flush() {
+	self.flushes = append(self.flushes, self.Body.Len())
+}
+
+func startTestLookupStream() {
+	AliceConfig = &Config{
+		Ui: UiConfig{
+			Pagination: PaginationConfig{
+				RoutesAcceptedPageSize: 2,
+				MaxPageSize:            100,
+			},
+		},
+	}
+	startTestNeighboursStore()
+
+	store := makeTestRoutesStore()
+	store.configMap["rs2"] = &SourceConfig{Id: "rs2", Name: "rs2.test"}
+	store.routesMap["rs2"] = &api.RoutesResponse{
+		Imported: api.Routes{
+			&api.Route{
+				Id:          "r1",
+				NeighbourId: "ID2233_AS4223",
+				Network:     "193.200.230.0/24",
+			},
+			&api.Route{
+				Id:          "r2",
+				NeighbourId: "ID2233_AS4223",
+				Network:     "193.200.231.0/24",
+			},
+			&api.Route{
+				Id:          "r3",
+				NeighbourId: "ID2233_AS4223",
+				Network:     "193.200.232.0/24",
+			},
+		},
+	}
+	AliceRoutesStore = store
+}
+
+func decodeLookupStream(t *testing.T, body string) api.LookupRoutes {
+	routes := api.LookupRoutes{}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		route := &api.LookupRoute{}
+		if err := json.Unmarshal(scanner.Bytes(), route); err != nil {
+			t.Fatal(err)
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+func TestLookupPrefixStream(t *testing.T) {
+	defer saveTestGlobals()()
+	startTestLookupStream()
+	query := "193.200."
+	expected := AliceRoutesStore.LookupPrefix(query)
+
+	req := httptest.NewRequest(
+		"GET", "/api/v1/lookup/prefix/stream?format=ndjson&q="+query, nil)
+	res := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	streamEndpoint(nil, apiLookupPrefixStream)(res, req, nil)
+
+	routes := decodeLookupStream(t, res.Body.String())
+	if len(routes) != len(expected) {
+		t.Fatal("Expected", len(expected), "routes, got:", len(routes))
+	}
+
+	// Each source is flushed as soon as it is available
+	if len(res.flushes) != 3 {
+		t.Fatal("Expected a flush per source and on close, got:", res.flushes)
+	}
+	rs1 := decodeLookupStream(t, res.Body.String()[:res.flushes[0]])
+	if len(rs1) != len(expected)-3 {
+		t.Error("Expected the routes of rs1 in the first chunk, got:", len(rs1))
+	}
+	for _, route := range rs1 {
+		if route.Routeserver.Id != "rs1" {
+			t.Error("Unexpected route server in first chunk:", route.Routeserver.Id)
+		}
+	}
+	if routes[len(routes)-1].Routeserver.Id != "rs2" {
+		t.Error("Expected the route of rs2 last")
+	}
+}
+
+func TestLookupPrefixStreamPage(t *testing.T) {
+	defer saveTestGlobals()()
+	startTestLookupStream()
+	query := "193.200."
+	all := AliceRoutesStore.LookupPrefix(query)
+
+	req := httptest.NewRequest(
+		"GET", "/api/v1/lookup/prefix/stream?page=1&q="+query, nil)
+	res := httptest.NewRecorder()
+	streamEndpoint(nil, apiLookupPrefixStream)(res, req, nil)
+
+	routes := api.LookupRoutes{}
+	if err := json.Unmarshal(res.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err, res.Body.String())
+	}
+	if len(all) < 4 {
+		t.Fatal("Expected at least 4 routes, got:", len(all))
+	}
+	if len(routes) != 2 {
+		t.Fatal("Expected a page of 2 routes, got:", len(routes))
+	}
+	if routes[0].Id != "r2" || routes[1].Id != "r3" {
+		t.Error("Expected the second page, got:", routes[0].Id, routes[1].Id)
+	}
+}
+
+func TestLookupPrefixStreamMaxPathsPerPrefix(t *testing.T) {
+	defer saveTestGlobals()()
+	startTestLookupStream()
+	rs2 := AliceRoutesStore.routesMap["rs2"]
+	for _, id := range []string{"r1b", "r1c"} {
+		rs2.Imported = append(rs2.Imported, &api.Route{
+			Id:          id,
+			NeighbourId: "ID2233_AS4223",
+			Network:     "193.200.230.0/24",
+		})
+	}
+
+	req := httptest.NewRequest("GET",
+		"/api/v1/lookup/prefix/stream?format=ndjson"+
+			"&max_paths_per_prefix=1&q=193.200.230.0/24", nil)
+	res := httptest.NewRecorder()
+	streamEndpoint(nil, apiLookupPrefixStream)(res, req, nil)
+
+	paths := 0
+	for _, route := range decodeLookupStream(t, res.Body.String()) {
+		if route.Routeserver.Id != "rs2" {
+			continue
+		}
+		paths++
+		if !route.PathsTruncated {
+			t.Error("Expected truncated prefix to be flagged")
+		}
+	}
+	if paths != 1 {
+		t.Error("Expected a single path of rs2, got:", paths)
+	}
+}
+
+func TestLookupPrefixStreamInvalidFormat(t *testing.T) {
+	defer saveTestGlobals()()
+	startTestLookupStream()
+	req := httptest.NewRequest(
+		"GET", "/api/v1/lookup/prefix/stream?format=xml&q=193.200.", nil)
+	res := httptest.NewRecorder()
+	streamEndpoint(nil, apiLookupPrefixStream)(res, req, nil)
+	if res.Code != 400 {
+		t.Error("Expected status 400, got:", res.Code)
+	}
+}
+
+func TestLookupPrefixStreamCancelled(t *testing.T) {
+	defer saveTestGlobals()()
+	startTestLookupStream()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(
+		"GET", "/api/v1/lookup/prefix/stream?format=ndjson&q=193.200.", nil)
+	res := httptest.NewRecorder()
+	err := apiLookupPrefixStream(res, req.WithContext(ctx), nil)
+	if err != context.Canceled {
+		t.Error("Expected the stream to be cancelled, got:", err)
+	}
+	if res.Body.Len() != 0 {
+		t.Error("Expected no routes, got:", res.Body.String())
+	}
+}
@@ -102,3 +102,50 @@ func apiPaginateLookupRoutes(
 
 	return routes[offset:rindex], pagination
 }
+
+func apiPaginateOriginAsnGroups(
+	groups []*api.OriginAsnGroup,
+	page, pageSize int,
+) ([]*api.OriginAsnGroup, api.Pagination) {
+	totalResults := len(groups)
+
+	// In case pageSize is 0, we assume pagination
+	// is disabled.
+	if pageSize == 0 {
+		pagination := api.Pagination{
+			Page:         page,
+			PageSize:     pageSize,
+			TotalPages:   0,
+			TotalResults: totalResults,
+		}
+		return groups, pagination
+	}
+
+	// Calculate the number of pages we get
+	totalPages := int(math.Ceil(float64(totalResults) / float64(pageSize)))
+
+	offset := page * pageSize
+	rindex := offset + pageSize
+
+	// Don't access out of bounds
+	if rindex > totalResults {
+		rindex = totalResults
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	pagination := api.Pagination{
+		Page:         page,
+		PageSize:     pageSize,
+		TotalPages:   totalPages,
+		TotalResults: totalResults,
+	}
+
+	// Safeguards
+	if offset >= totalResults {
+		return []*api.OriginAsnGroup{}, pagination
+	}
+
+	return groups[offset:rindex], pagination
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alice-lg/alice-lg/backend/api"
 )
@@ -57,3 +59,448 @@ func apiQueryFilterNextHopGateway(
 
 	return results
 }
+
+/*
+Filter blackholed routes: blackholed=true only includes
+blackholed routes, blackholed=false excludes them.
+*/
+func apiQueryFilterBlackholed(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	query := req.URL.Query()
+	queryParam, ok := query["blackholed"]
+	if !ok {
+		return routes
+	}
+
+	blackholed, err := strconv.ParseBool(queryParam[0])
+	if err != nil {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		if r.Blackholed == blackholed {
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
+
+/*
+Filter bogon routes: bogon=true only includes
+routes for bogon prefixes, bogon=false excludes them.
+*/
+func apiQueryFilterBogon(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	query := req.URL.Query()
+	queryParam, ok := query["bogon"]
+	if !ok {
+		return routes
+	}
+
+	bogon, err := strconv.ParseBool(queryParam[0])
+	if err != nil {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		if r.Bogon == bogon {
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
+
+/*
+Filter routes by prepending: min_prepend_count and
+max_prepend_count are inclusive bounds.
+*/
+func apiQueryFilterPrependCount(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	minCount := apiQueryMustInt(req, "min_prepend_count", -1)
+	maxCount := apiQueryMustInt(req, "max_prepend_count", -1)
+	if minCount < 0 && maxCount < 0 {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		if minCount >= 0 && r.PrependCount < minCount {
+			continue
+		}
+		if maxCount >= 0 && r.PrependCount > maxCount {
+			continue
+		}
+		results = append(results, r)
+	}
+
+	return results
+}
+
+/*
+Filter routes by the next-hop ASN: next_hop_asn only
+includes routes where the first ASN in the AS path, the
+neighbour the route was learned from, matches.
+*/
+func apiQueryFilterNextHopAsn(
+	req *http.Request, routes api.Routes,
+) (api.Routes, error) {
+	value := req.URL.Query().Get("next_hop_asn")
+	if value == "" {
+		return routes, nil
+	}
+
+	asn, err := parseAsn(value)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		if len(r.Bgp.AsPath) > 0 && r.Bgp.AsPath[0] == asn {
+			results = append(results, r)
+		}
+	}
+
+	return results, nil
+}
+
+/*
+Filter routes by the size of the path attributes:
+min_attribute_bytes is an inclusive bound.
+*/
+func apiQueryFilterAttributeBytes(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	minBytes := apiQueryMustInt(req, "min_attribute_bytes", -1)
+	if minBytes < 0 {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		if r.AttributeBytes >= minBytes {
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
+
+/*
+Filter routes by AS path length: min_as_path_len and
+max_as_path_len are inclusive bounds.
+*/
+func apiQueryFilterAsPathLength(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	minLength := apiQueryMustInt(req, "min_as_path_len", -1)
+	maxLength := apiQueryMustInt(req, "max_as_path_len", -1)
+	if minLength < 0 && maxLength < 0 {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		length := len(r.Bgp.AsPath)
+		if minLength >= 0 && length < minLength {
+			continue
+		}
+		if maxLength >= 0 && length > maxLength {
+			continue
+		}
+		results = append(results, r)
+	}
+
+	return results
+}
+
+/*
+Filter routes by community category: community_category=rejection
+only includes routes with any community labeled as rejection
+reason, exclude_community_category=rejection excludes them.
+*/
+func apiQueryFilterCommunityCategory(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	query := req.URL.Query()
+
+	category := query.Get("community_category")
+	if labels, ok := getCommunityCategoryLabels(category); ok {
+		routes = filterRoutesByLabeledCommunities(routes, labels, true)
+	}
+
+	category = query.Get("exclude_community_category")
+	if labels, ok := getCommunityCategoryLabels(category); ok {
+		routes = filterRoutesByLabeledCommunities(routes, labels, false)
+	}
+
+	return routes
+}
+
+/*
+Filter routes by community group: community_group=<name>
+only includes routes with any community of the group.
+*/
+func apiQueryFilterCommunityGroup(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	name := req.URL.Query().Get("community_group")
+	if name == "" {
+		return routes
+	}
+
+	communities, ok := AliceConfig.CommunityGroups.Lookup(name)
+	if !ok {
+		return routes
+	}
+
+	return filterRoutesByCommunityGroup(routes, communities)
+}
+
+/*
+Filter routes by age: received_within=30m only includes
+routes learned within the given duration. A plain number
+is interpreted as minutes.
+Sources not providing a route age can not be filtered.
+*/
+func apiQueryFilterReceivedWithin(
+	req *http.Request, routes api.Routes,
+) (api.Routes, error) {
+	value := req.URL.Query().Get("received_within")
+	if value == "" {
+		return routes, nil
+	}
+
+	within, err := parseReceivedWithin(value)
+	if err != nil || within < 0 {
+		return nil, &InvalidReceivedWithinError{ReceivedWithin: value}
+	}
+
+	ageAvailable := false
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		if !routeHasAge(r) {
+			continue
+		}
+		ageAvailable = true
+		if r.Age <= within {
+			results = append(results, r)
+		}
+	}
+
+	if len(routes) > 0 && !ageAvailable {
+		return nil, ROUTE_AGE_NOT_AVAILABLE_ERROR
+	}
+
+	return results, nil
+}
+
+// Helper: Parse a duration, defaulting to minutes
+func parseReceivedWithin(value string) (time.Duration, error) {
+	if minutes, err := strconv.Atoi(value); err == nil {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// Helper: A route without age information will have
+// an age dating back to (at least) the epoch.
+func routeHasAge(route *api.Route) bool {
+	if route.Age <= 0 {
+		return false
+	}
+	return time.Now().Add(-route.Age).Unix() > 0
+}
+
+// Helper: Check if community labels should be included
+func apiQueryResolveCommunitiesEnabled(req *http.Request) bool {
+	value := req.URL.Query().Get("resolve_communities")
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
+
+/*
+Include the labels of the communities inline, if
+requested with resolve_communities=1.
+The routes are copied, as they are shared with the cache.
+*/
+func apiQueryResolveCommunities(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	if !apiQueryResolveCommunitiesEnabled(req) {
+		return routes
+	}
+
+	labels := AliceConfig.Ui.BgpCommunities
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		route := *r
+		route.CommunityLabels = labels.LookupAll(
+			route.Bgp, AliceConfig.Server.AsnNotation)
+		results = append(results, &route)
+	}
+	return results
+}
+
+// Same for lookup routes
+func apiQueryResolveLookupCommunities(
+	req *http.Request, routes api.LookupRoutes,
+) api.LookupRoutes {
+	if !apiQueryResolveCommunitiesEnabled(req) {
+		return routes
+	}
+
+	labels := AliceConfig.Ui.BgpCommunities
+	results := make(api.LookupRoutes, 0, len(routes))
+	for _, r := range routes {
+		route := *r
+		route.CommunityLabels = labels.LookupAll(
+			route.Bgp, AliceConfig.Server.AsnNotation)
+		results = append(results, &route)
+	}
+	return results
+}
+
+// Helper: Get the requested communities format, falling
+// back to the configured format. Unknown formats are ignored.
+func apiQueryCommunitiesFormat(req *http.Request) string {
+	switch format := req.URL.Query().Get("communities_format"); format {
+	case api.COMMUNITIES_FORMAT_ARRAY,
+		api.COMMUNITIES_FORMAT_STRING,
+		api.COMMUNITIES_FORMAT_BOTH:
+		return format
+	}
+	return AliceConfig.Server.CommunitiesFormat
+}
+
+/*
+Serialize the communities as requested with
+communities_format=array|string|both.
+The routes are copied, as they are shared with the cache.
+*/
+func apiQueryFormatCommunities(
+	req *http.Request, routes api.Routes,
+) api.Routes {
+	format := apiQueryCommunitiesFormat(req)
+	if format == "" || format == api.COMMUNITIES_FORMAT_ARRAY {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		route := *r
+		route.Bgp = route.Bgp.FormatCommunities(format)
+		results = append(results, &route)
+	}
+	return results
+}
+
+// Same for lookup routes
+func apiQueryFormatLookupCommunities(
+	req *http.Request, routes api.LookupRoutes,
+) api.LookupRoutes {
+	format := apiQueryCommunitiesFormat(req)
+	if format == "" || format == api.COMMUNITIES_FORMAT_ARRAY {
+		return routes
+	}
+
+	results := make(api.LookupRoutes, 0, len(routes))
+	for _, r := range routes {
+		route := *r
+		route.Bgp = route.Bgp.FormatCommunities(format)
+		results = append(results, &route)
+	}
+	return results
+}
+
+/*
+Limit the number of paths per prefix in lookup results:
+max_paths_per_prefix=N keeps at most N paths for each
+prefix, preferring primary (best) and accepted paths.
+Truncated prefixes are flagged.
+
+This applies to the prefix, destination and streaming
+lookups. The streaming lookup limits the paths of each
+source, as the sources are streamed one by one. The origin
+lookup is not affected, as it returns a single entry with
+the number of paths per prefix.
+*/
+func apiQueryLimitPathsPerPrefix(
+	req *http.Request, routes api.LookupRoutes,
+) api.LookupRoutes {
+	maxPaths := apiQueryMustInt(req, "max_paths_per_prefix", 0)
+	if maxPaths <= 0 {
+		return routes
+	}
+
+	// Group paths by prefix, keeping the order
+	// of first appearance
+	prefixes := []string{}
+	paths := make(map[string]api.LookupRoutes)
+	for _, r := range routes {
+		if _, ok := paths[r.Network]; !ok {
+			prefixes = append(prefixes, r.Network)
+		}
+		paths[r.Network] = append(paths[r.Network], r)
+	}
+
+	results := make(api.LookupRoutes, 0, len(routes))
+	for _, prefix := range prefixes {
+		group := paths[prefix]
+		if len(group) <= maxPaths {
+			results = append(results, group...)
+			continue
+		}
+
+		sort.SliceStable(group, func(i, j int) bool {
+			return lookupRoutePathRank(group[i]) < lookupRoutePathRank(group[j])
+		})
+		for _, r := range group[:maxPaths] {
+			r.PathsTruncated = true
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
+
+// Helper: Rank paths, lower is better
+func lookupRoutePathRank(route *api.LookupRoute) int {
+	rank := 0
+	if !route.Primary {
+		rank += 1
+	}
+	if route.State != "imported" {
+		rank += 2
+	}
+	return rank
+}
+
+// Apply the route filters of the query to the routes
+// of a neighbour. The community filters are applied
+// separately, as they update the available filters.
+func applyRouteFilters(
+	req *http.Request,
+	routes api.Routes,
+) (api.Routes, error) {
+	routes = apiQueryFilterNextHopGateway(req, "q", routes)
+	routes = apiQueryFilterBlackholed(req, routes)
+	routes = apiQueryFilterBogon(req, routes)
+	routes = apiQueryFilterAsPathLength(req, routes)
+	routes = apiQueryFilterPrependCount(req, routes)
+	routes, err := apiQueryFilterNextHopAsn(req, routes)
+	if err != nil {
+		return nil, err
+	}
+	routes = apiQueryFilterAttributeBytes(req, routes)
+	routes = apiQueryFilterCommunityCategory(req, routes)
+	routes = apiQueryFilterCommunityGroup(req, routes)
+	return apiQueryFilterReceivedWithin(req, routes)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/alice-lg/alice-lg/backend/api"
 )
@@ -75,3 +76,317 @@ func TestApiQueryFilterNextHopGateway(t *testing.T) {
 		t.Error("Expected route_02 to match criteria, got:", filtered[0])
 	}
 }
+
+func TestApiQueryLimitPathsPerPrefix(t *testing.T) {
+	routes := api.LookupRoutes{
+		&api.LookupRoute{Id: "a1", Network: "10.0.0.0/8", State: "filtered"},
+		&api.LookupRoute{Id: "a2", Network: "10.0.0.0/8", State: "imported"},
+		&api.LookupRoute{Id: "a3", Network: "10.0.0.0/8", State: "imported", Primary: true},
+		&api.LookupRoute{Id: "a4", Network: "10.0.0.0/8", State: "imported"},
+		&api.LookupRoute{Id: "b1", Network: "192.168.0.0/16", State: "imported"},
+	}
+
+	u, _ := url.Parse("http://alice/api?max_paths_per_prefix=2")
+	results := apiQueryLimitPathsPerPrefix(&http.Request{URL: u}, routes)
+	if len(results) != 3 {
+		t.Fatal("Expected 3 paths, got:", len(results))
+	}
+
+	// The best paths are kept and flagged
+	if results[0].Id != "a3" || results[1].Id != "a2" {
+		t.Error("Unexpected paths:", results[0].Id, results[1].Id)
+	}
+	if !results[0].PathsTruncated || !results[1].PathsTruncated {
+		t.Error("Expected truncated prefix to be flagged")
+	}
+
+	// Prefixes below the limit are not flagged
+	if results[2].Id != "b1" || results[2].PathsTruncated {
+		t.Error("Expected b1 not to be truncated")
+	}
+
+	// No limit
+	u, _ = url.Parse("http://alice/api")
+	results = apiQueryLimitPathsPerPrefix(&http.Request{URL: u}, routes)
+	if len(results) != 5 {
+		t.Error("Expected all paths, got:", len(results))
+	}
+}
+
+func TestApiQueryLimitPathsPerPrefixMixedAfi(t *testing.T) {
+	// The best path is marked per destination, so
+	// interleaved v4 and v6 paths each keep their best path.
+	routes := api.LookupRoutes{
+		&api.LookupRoute{Id: "v4_1", Network: "10.0.0.0/8", State: "imported"},
+		&api.LookupRoute{Id: "v6_1", Network: "2001:db8::/32", State: "imported"},
+		&api.LookupRoute{Id: "v4_2", Network: "10.0.0.0/8", State: "imported", Primary: true},
+		&api.LookupRoute{Id: "v6_2", Network: "2001:db8::/32", State: "imported", Primary: true},
+		&api.LookupRoute{Id: "v4_3", Network: "10.0.0.0/8", State: "imported"},
+		&api.LookupRoute{Id: "v6_3", Network: "2001:db8::/32", State: "imported"},
+	}
+
+	u, _ := url.Parse("http://alice/api?max_paths_per_prefix=1")
+	results := apiQueryLimitPathsPerPrefix(&http.Request{URL: u}, routes)
+	if len(results) != 2 {
+		t.Fatal("Expected one path per prefix, got:", len(results))
+	}
+	if results[0].Id != "v4_2" || results[1].Id != "v6_2" {
+		t.Error("Expected the best v4 and v6 paths, got:",
+			results[0].Id, results[1].Id)
+	}
+}
+
+func TestApiQueryFilterReceivedWithin(t *testing.T) {
+	routes := api.Routes{
+		&api.Route{Id: "fresh", Age: 5 * time.Minute},
+		&api.Route{Id: "recent", Age: 25 * time.Minute},
+		&api.Route{Id: "old", Age: 48 * time.Hour},
+	}
+
+	u, _ := url.Parse("http://alice/api?received_within=30m")
+	filtered, err := apiQueryFilterReceivedWithin(&http.Request{URL: u}, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 2 {
+		t.Error("Expected 2 recent routes, got:", len(filtered))
+	}
+
+	// Plain numbers are minutes
+	u, _ = url.Parse("http://alice/api?received_within=10")
+	filtered, err = apiQueryFilterReceivedWithin(&http.Request{URL: u}, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].Id != "fresh" {
+		t.Error("Expected only the fresh route, got:", filtered)
+	}
+
+	// No filter
+	u, _ = url.Parse("http://alice/api")
+	filtered, _ = apiQueryFilterReceivedWithin(&http.Request{URL: u}, routes)
+	if len(filtered) != 3 {
+		t.Error("Expected all routes, got:", len(filtered))
+	}
+
+	// Invalid durations are rejected
+	for _, value := range []string{"yesterday", "-5m"} {
+		u, _ = url.Parse("http://alice/api?received_within=" + value)
+		_, err = apiQueryFilterReceivedWithin(&http.Request{URL: u}, routes)
+		if _, ok := err.(*InvalidReceivedWithinError); !ok {
+			t.Error("Expected invalid received_within error for",
+				value, "got:", err)
+		}
+		_, status := apiErrorResponse("rs1", err)
+		if status != BAD_REQUEST_STATUS {
+			t.Error("Expected bad request status, got:", status)
+		}
+	}
+}
+
+func TestApiQueryFilterReceivedWithinNoAge(t *testing.T) {
+	routes := api.Routes{
+		&api.Route{Id: "r1"},
+		&api.Route{Id: "r2", Age: time.Since(time.Time{})},
+		&api.Route{Id: "r3", Age: time.Since(time.Unix(0, 0))},
+	}
+
+	u, _ := url.Parse("http://alice/api?received_within=30m")
+	_, err := apiQueryFilterReceivedWithin(&http.Request{URL: u}, routes)
+	if err != ROUTE_AGE_NOT_AVAILABLE_ERROR {
+		t.Error("Expected age not available error, got:", err)
+	}
+
+	_, status := apiErrorResponse("rs1", err)
+	if status != BAD_REQUEST_STATUS {
+		t.Error("Expected bad request status, got:", status)
+	}
+}
+
+func TestApiQueryResolveCommunities(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	AliceConfig.Ui.BgpCommunities = BgpCommunities{
+		"65535": BgpCommunities{
+			"666": "blackhole",
+		},
+		"9033": BgpCommunities{
+			"65666": BgpCommunities{
+				"1": "ip bogon detected",
+			},
+		},
+	}
+
+	route := &api.Route{
+		Id: "r1",
+		Bgp: api.BgpInfo{
+			Communities: api.Communities{
+				api.Community{65535, 666},
+				api.Community{23, 42},
+			},
+			LargeCommunities: api.Communities{
+				api.Community{9033, 65666, 1},
+			},
+		},
+	}
+	routes := api.Routes{route}
+
+	u, _ := url.Parse("http://alice/api?resolve_communities=1")
+	resolved := apiQueryResolveCommunities(&http.Request{URL: u}, routes)
+	labels := resolved[0].CommunityLabels
+	if len(labels) != 2 ||
+		labels["65535:666"] != "blackhole" ||
+		labels["9033:65666:1"] != "ip bogon detected" {
+		t.Error("Unexpected labels:", labels)
+	}
+
+	// The shared route is not modified
+	if route.CommunityLabels != nil {
+		t.Error("Expected original route to be unchanged")
+	}
+
+	// Not requested
+	u, _ = url.Parse("http://alice/api")
+	resolved = apiQueryResolveCommunities(&http.Request{URL: u}, routes)
+	if resolved[0].CommunityLabels != nil {
+		t.Error("Expected no labels, got:", resolved[0].CommunityLabels)
+	}
+
+	// Lookup routes
+	u, _ = url.Parse("http://alice/api?resolve_communities=true")
+	lookupRoutes := apiQueryResolveLookupCommunities(
+		&http.Request{URL: u},
+		api.LookupRoutes{&api.LookupRoute{Bgp: route.Bgp}})
+	if lookupRoutes[0].CommunityLabels["65535:666"] != "blackhole" {
+		t.Error("Expected lookup route labels, got:",
+			lookupRoutes[0].CommunityLabels)
+	}
+}
+
+func TestApiQueryFormatCommunities(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Server: ServerConfig{
+			CommunitiesFormat: api.COMMUNITIES_FORMAT_ARRAY,
+		},
+	}
+	routes := api.Routes{
+		&api.Route{
+			Id: "r1",
+			Bgp: api.BgpInfo{
+				LargeCommunities: api.Communities{{65000, 1, 2}},
+			},
+		},
+	}
+
+	// The configured default keeps the routes
+	req, _ := http.NewRequest("GET", "/", nil)
+	formatted := apiQueryFormatCommunities(req, routes)
+	if formatted[0] != routes[0] {
+		t.Error("Expected routes to be unchanged")
+	}
+
+	req, _ = http.NewRequest("GET", "/?communities_format=string", nil)
+	formatted = apiQueryFormatCommunities(req, routes)
+	if len(formatted[0].Bgp.LargeCommunitiesStr) != 1 ||
+		formatted[0].Bgp.LargeCommunitiesStr[0] != "65000:1:2" {
+		t.Error("Unexpected communities:", formatted[0].Bgp)
+	}
+	if routes[0].Bgp.LargeCommunities == nil {
+		t.Error("The cached routes must not be modified")
+	}
+}
+
+func TestApiQueryFilterNextHopAsn(t *testing.T) {
+	routes := api.Routes{
+		&api.Route{Id: "match", Bgp: api.BgpInfo{AsPath: []int{64500, 64501}}},
+		&api.Route{Id: "origin", Bgp: api.BgpInfo{AsPath: []int{64502, 64500}}},
+		&api.Route{Id: "empty", Bgp: api.BgpInfo{AsPath: []int{}}},
+	}
+
+	u, _ := url.Parse("http://alice/api?next_hop_asn=AS64500")
+	filtered, err := apiQueryFilterNextHopAsn(&http.Request{URL: u}, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].Id != "match" {
+		t.Error("Expected only the matching route, got:", filtered)
+	}
+
+	u, _ = url.Parse("http://alice/api?next_hop_asn=64503")
+	filtered, _ = apiQueryFilterNextHopAsn(&http.Request{URL: u}, routes)
+	if len(filtered) != 0 {
+		t.Error("Expected no routes, got:", len(filtered))
+	}
+
+	u, _ = url.Parse("http://alice/api?next_hop_asn=foo")
+	_, err = apiQueryFilterNextHopAsn(&http.Request{URL: u}, routes)
+	if _, ok := err.(*InvalidAsnError); !ok {
+		t.Error("Expected an invalid asn error, got:", err)
+	}
+
+	// No filter
+	u, _ = url.Parse("http://alice/api")
+	filtered, _ = apiQueryFilterNextHopAsn(&http.Request{URL: u}, routes)
+	if len(filtered) != 3 {
+		t.Error("Expected all routes, got:", len(filtered))
+	}
+}
+
+func TestApplyRouteFilters(t *testing.T) {
+	routes := api.Routes{
+		&api.Route{
+			Id:  "match",
+			Age: 5 * time.Minute,
+			Bgp: api.BgpInfo{AsPath: []int{64500, 64501}},
+		},
+		&api.Route{
+			Id:         "blackholed",
+			Age:        5 * time.Minute,
+			Blackholed: true,
+			Bgp:        api.BgpInfo{AsPath: []int{64500}},
+		},
+		&api.Route{
+			Id:  "long",
+			Age: 5 * time.Minute,
+			Bgp: api.BgpInfo{AsPath: []int{64500, 64501, 64502}},
+		},
+		&api.Route{
+			Id:  "old",
+			Age: 48 * time.Hour,
+			Bgp: api.BgpInfo{AsPath: []int{64500}},
+		},
+		&api.Route{
+			Id:  "other",
+			Age: 5 * time.Minute,
+			Bgp: api.BgpInfo{AsPath: []int{64502}},
+		},
+	}
+
+	u, _ := url.Parse("http://alice/api?blackholed=false" +
+		"&max_as_path_len=2&next_hop_asn=64500&received_within=1h")
+	filtered, err := applyRouteFilters(&http.Request{URL: u}, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].Id != "match" {
+		t.Error("Expected only the matching route, got:", filtered)
+	}
+
+	// Invalid filters are rejected
+	u, _ = url.Parse("http://alice/api?next_hop_asn=foo")
+	if _, err := applyRouteFilters(&http.Request{URL: u}, routes); err == nil {
+		t.Error("Expected an error for an invalid asn")
+	}
+	u, _ = url.Parse("http://alice/api?received_within=soon")
+	if _, err := applyRouteFilters(&http.Request{URL: u}, routes); err == nil {
+		t.Error("Expected an error for an invalid duration")
+	}
+
+	// No filter
+	u, _ = url.Parse("http://alice/api")
+	filtered, _ = applyRouteFilters(&http.Request{URL: u}, routes)
+	if len(filtered) != len(routes) {
+		t.Error("Expected all routes, got:", len(filtered))
+	}
+}
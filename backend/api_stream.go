@@ -0,0 +1,124 @@
+package main
+
+/*
+Streamed api endpoints
+
+The response of a streamed endpoint is written by the
+handler, so it can not be wrapped as an endpoint. The
+checks of the endpoints are applied before the handler
+is called: Unknown query parameters are rejected in
+strict mode, the request body is limited and the
+handler runs within a slot of the limiter.
+
+The request timeout is the deadline of the request
+context. As the response may be partially written,
+the handler must stop when the context is done.
+*/
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// A streamed endpoint returns an error before writing
+// the response, or when writing the response failed.
+type apiStreamEndpoint func(
+	http.ResponseWriter,
+	*http.Request,
+	httprouter.Params,
+) error
+
+// Record the status of a streamed response
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (self *statusResponseWriter) WriteHeader(status int) {
+	if self.status == 0 {
+		self.status = status
+	}
+	self.ResponseWriter.WriteHeader(status)
+}
+
+func (self *statusResponseWriter) Write(data []byte) (int, error) {
+	if self.status == 0 {
+		self.status = http.StatusOK
+	}
+	return self.ResponseWriter.Write(data)
+}
+
+func (self *statusResponseWriter) Flush() {
+	if flusher, ok := self.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Fail writes when the context is done
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (self *contextWriter) Write(data []byte) (int, error) {
+	if err := self.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return self.w.Write(data)
+}
+
+// Wrap a streamed endpoint with the checks of the
+// endpoints, the limiter and the access log.
+func streamEndpoint(
+	limiter *requestLimiter,
+	wrapped apiStreamEndpoint,
+) httprouter.Handle {
+	return func(
+		res http.ResponseWriter,
+		req *http.Request,
+		params httprouter.Params,
+	) {
+		t0 := time.Now()
+		w := &statusResponseWriter{ResponseWriter: res}
+
+		var err error
+		if AliceConfig.Server.StrictParams {
+			err = validateQueryParams(req)
+		}
+		if err == nil {
+			err = apiLimitRequestBody(
+				req, AliceConfig.Server.MaxRequestBodySize)
+		}
+		if err == nil && limiter != nil {
+			err = limiter.Acquire()
+			if err == nil {
+				defer limiter.Release()
+			}
+		}
+		if err == nil {
+			timeout := time.Duration(
+				AliceConfig.Server.RequestTimeout) * time.Second
+			if timeout > 0 {
+				ctx, cancel := context.WithTimeout(req.Context(), timeout)
+				defer cancel()
+				req = req.WithContext(ctx)
+			}
+			err = wrapped(w, req, params)
+		}
+
+		if err != nil && w.status == 0 {
+			apiWriteError(w, params, err)
+		} else if err != nil {
+			log.Println("Streaming", req.URL.Path, "failed with:", err)
+		}
+
+		if AliceConfig.Server.AccessLog {
+			apiLogAccess(req, w.status, time.Since(t0))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestStreamEndpointStrictParams(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Server: ServerConfig{
+			StrictParams: true,
+		},
+	}
+
+	called := false
+	handler := streamEndpoint(nil, func(
+		res http.ResponseWriter,
+		req *http.Request,
+		params httprouter.Params,
+	) error {
+		called = true
+		return nil
+	})
+
+	req := httptest.NewRequest("GET",
+		"/api/v1/routeservers/rs1/export/mrt?foo=bar", nil)
+	res := httptest.NewRecorder()
+	handler(res, req, nil)
+
+	if called {
+		t.Error("Expected the handler not to be called")
+	}
+	if res.Code != http.StatusBadRequest {
+		t.Error("Expected status 400, got:", res.Code)
+	}
+}
+
+func TestStreamEndpointLimiter(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	limiter := newRequestLimiter(1, 0, time.Second)
+	if err := limiter.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	defer limiter.Release()
+
+	handler := streamEndpoint(limiter, func(
+		res http.ResponseWriter,
+		req *http.Request,
+		params httprouter.Params,
+	) error {
+		t.Error("Expected the handler not to be called")
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/routeservers/rs1/export/mrt", nil)
+	res := httptest.NewRecorder()
+	handler(res, req, nil)
+
+	if res.Code != http.StatusServiceUnavailable {
+		t.Error("Expected status 503, got:", res.Code)
+	}
+	if res.Header().Get("Retry-After") != "1" {
+		t.Error("Unexpected Retry-After:", res.Header().Get("Retry-After"))
+	}
+}
+
+func TestStreamEndpointError(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	// An error after the response was written is not
+	// written to the response
+	handler := streamEndpoint(nil, func(
+		res http.ResponseWriter,
+		req *http.Request,
+		params httprouter.Params,
+	) error {
+		res.Write([]byte("partial"))
+		return errors.New("stream failed")
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/routeservers/rs1/export/mrt", nil)
+	res := httptest.NewRecorder()
+	handler(res, req, nil)
+
+	if res.Code != http.StatusOK || res.Body.String() != "partial" {
+		t.Error("Unexpected response:", res.Code, res.Body.String())
+	}
+}
+
+func TestContextWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	res := httptest.NewRecorder()
+	w := &contextWriter{ctx: ctx, w: res}
+
+	if _, err := w.Write([]byte("data")); err != nil {
+		t.Error(err)
+	}
+
+	cancel()
+	if _, err := w.Write([]byte("more")); err == nil {
+		t.Error("Expected the write to fail after the context is done")
+	}
+	if res.Body.String() != "data" {
+		t.Error("Unexpected body:", res.Body.String())
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Call the handler with a hard deadline. When the deadline
+// is exceeded, the request is aborted with a timeout error.
+// The handler may continue in the background, but its
+// result is discarded and its request limiter slot is
+// released. A timeout <= 0 disables the deadline.
+func apiHandleWithTimeout(
+	wrapped apiEndpoint,
+	timeout time.Duration,
+	req *http.Request,
+	params httprouter.Params,
+) (api.Response, error) {
+	if timeout <= 0 {
+		return wrapped(req, params)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	type handlerResult struct {
+		response api.Response
+		err      error
+	}
+	done := make(chan handlerResult, 1)
+	go func() {
+		response, err := wrapped(req, params)
+		done <- handlerResult{response, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.response, result.err
+	case <-ctx.Done():
+		return nil, &ServiceUnavailableError{
+			Reason:     "request timed out",
+			RetryAfter: timeout,
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestApiHandleWithTimeout(t *testing.T) {
+	slow := func(
+		req *http.Request, _ httprouter.Params,
+	) (api.Response, error) {
+		select {
+		case <-time.After(time.Second):
+		case <-req.Context().Done():
+		}
+		return &api.StatusResponse{}, nil
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/status", nil)
+	t0 := time.Now()
+	result, err := apiHandleWithTimeout(
+		slow, 20*time.Millisecond, req, nil)
+	if time.Since(t0) > 500*time.Millisecond {
+		t.Error("Expected handler to be aborted")
+	}
+	if result != nil {
+		t.Error("Expected no result")
+	}
+	if _, ok := err.(*ServiceUnavailableError); !ok {
+		t.Fatal("Expected ServiceUnavailableError, got:", err)
+	}
+
+	_, status := apiErrorResponse("rs1", err)
+	if status != http.StatusServiceUnavailable {
+		t.Error("Expected status 503, got:", status)
+	}
+}
+
+func TestApiHandleWithTimeoutCompleted(t *testing.T) {
+	fast := func(
+		_ *http.Request, _ httprouter.Params,
+	) (api.Response, error) {
+		return &api.StatusResponse{}, nil
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/status", nil)
+	for _, timeout := range []time.Duration{0, time.Second} {
+		result, err := apiHandleWithTimeout(fast, timeout, req, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		if result == nil {
+			t.Error("Expected a result with timeout:", timeout)
+		}
+	}
+}
+
+func TestEndpointRequestTimeout(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Server: ServerConfig{
+			RequestTimeout: 1,
+		},
+	}
+	slow := func(
+		req *http.Request, _ httprouter.Params,
+	) (api.Response, error) {
+		<-req.Context().Done()
+		return &api.StatusResponse{}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	res := httptest.NewRecorder()
+	endpoint(slow)(res, req, nil)
+
+	if res.Code != http.StatusServiceUnavailable {
+		t.Error("Expected status 503, got:", res.Code)
+	}
+	if res.Header().Get("Retry-After") != "1" {
+		t.Error("Unexpected Retry-After:", res.Header().Get("Retry-After"))
+	}
+}
+
+func TestApiHandleWithTimeoutKeepsLimiterSlot(t *testing.T) {
+	limiter := newRequestLimiter(1, 1, time.Second)
+
+	blocked := make(chan bool)
+	stuck := func(
+		req *http.Request, _ httprouter.Params,
+	) (api.Response, error) {
+		<-blocked // Ignores the request context
+		return &api.StatusResponse{}, nil
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/lookup", nil)
+	_, err := apiHandleWithTimeout(
+		limitedEndpoint(limiter, stuck), 20*time.Millisecond, req, nil)
+	if _, ok := err.(*ServiceUnavailableError); !ok {
+		t.Fatal("Expected timeout error, got:", err)
+	}
+
+	// The handler is still running and keeps its slot
+	if len(limiter.slots) != 1 {
+		t.Error("Expected the slot to be occupied")
+	}
+
+	// The slot is released when the handler returns
+	close(blocked)
+	if err := limiter.Acquire(); err != nil {
+		t.Error("Expected the slot to be released, got:", err)
+	}
+	limiter.Release()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Add the timing of the source call to the api status,
+// if enabled. The status is returned as a copy, as
+// responses may be shared with the source caches.
+func apiSourceTiming(
+	status api.ApiStatus,
+	latency time.Duration,
+) api.ApiStatus {
+	if !AliceConfig.Server.IncludeSourceTiming {
+		return status
+	}
+	status.Timing = &api.ApiTiming{
+		SourceLatency: DurationMs(latency),
+		CacheHit:      status.ResultFromCache,
+	}
+	return status
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// A source taking its time to respond
+type slowSource struct {
+	expireCountingSource
+	delay    time.Duration
+	response *api.RoutesResponse
+}
+
+func (self *slowSource) Routes(_ string) (*api.RoutesResponse, error) {
+	time.Sleep(self.delay)
+	return self.response, nil
+}
+
+func TestApiSourceTiming(t *testing.T) {
+	defer saveTestGlobals()()
+	source := &slowSource{
+		delay: 50 * time.Millisecond,
+		response: &api.RoutesResponse{
+			Api: api.ApiStatus{
+				ResultFromCache: true,
+			},
+		},
+	}
+	AliceConfig = &Config{
+		Server: ServerConfig{
+			IncludeSourceTiming: true,
+		},
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:       "rs1",
+				Name:     "rs1",
+				instance: source,
+			},
+		},
+	}
+
+	params := httprouter.Params{
+		httprouter.Param{Key: "id", Value: "rs1"},
+		httprouter.Param{Key: "neighborId", Value: "n1"},
+	}
+	result, err := apiRoutesList(nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timing := result.(*api.RoutesResponse).Api.Timing
+	if timing == nil {
+		t.Fatal("Expected timing in api status")
+	}
+	if timing.SourceLatency < 50 {
+		t.Error("Expected source latency >= 50ms, got:", timing.SourceLatency)
+	}
+	if !timing.CacheHit {
+		t.Error("Expected cache hit")
+	}
+
+	// The response of the source is not modified
+	if source.response.Api.Timing != nil {
+		t.Error("Expected source response to be unchanged")
+	}
+
+	// Disabled timing
+	AliceConfig.Server.IncludeSourceTiming = false
+	result, err = apiRoutesList(nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.(*api.RoutesResponse).Api.Timing != nil {
+		t.Error("Expected no timing when disabled")
+	}
+}
@@ -2,9 +2,12 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 
 	"net/http"
+
+	"github.com/alice-lg/alice-lg/backend/api"
 )
 
 // Helper: Validate source Id
@@ -79,3 +82,97 @@ func validatePaginationParams(req *http.Request, limit, offset int) (int, int, e
 
 	return limit, offset, nil
 }
+
+// Get the page size: Clients may request a page_size,
+// bounded by the configured maximum. Refer to the
+// default if none is given.
+func validatePageSize(req *http.Request, pageSize int) (int, error) {
+	value := req.URL.Query().Get("page_size")
+	if value == "" {
+		return pageSize, nil
+	}
+
+	maxPageSize := AliceConfig.Ui.Pagination.MaxPageSize
+	requested, err := strconv.Atoi(value)
+	if err != nil || requested < 1 || requested > maxPageSize {
+		return 0, &InvalidPageSizeError{
+			PageSize:    value,
+			MaxPageSize: maxPageSize,
+		}
+	}
+
+	return requested, nil
+}
+
+// Query parameters known to the api. Unknown parameters
+// are rejected, if strict_params is enabled.
+var apiKnownQueryParams = map[string]bool{
+	"q":             true,
+	"page":          true,
+	"page_imported": true,
+	"page_filtered": true,
+	"page_size":     true,
+	"limit":         true,
+	"offset":        true,
+
+	// Neighbours
+	"name":  true,
+	"asn":   true,
+	"sort":  true,
+	"group": true,
+
+	"uptime_format": true,
+
+	"min_distinct_next_hops": true,
+
+	// Search filters
+	api.SEARCH_KEY_SOURCES:           true,
+	api.SEARCH_KEY_ASNS:              true,
+	api.SEARCH_KEY_COMMUNITIES:       true,
+	api.SEARCH_KEY_EXT_COMMUNITIES:   true,
+	api.SEARCH_KEY_LARGE_COMMUNITIES: true,
+
+	// Routes filters
+	"blackholed":                 true,
+	"bogon":                      true,
+	"min_as_path_len":            true,
+	"max_as_path_len":            true,
+	"min_attribute_bytes":        true,
+	"min_prepend_count":          true,
+	"max_prepend_count":          true,
+	"next_hop_asn":               true,
+	"community_category":         true,
+	"exclude_community_category": true,
+	"community_group":            true,
+	"received_within":            true,
+	"resolve_communities":        true,
+	"communities_format":         true,
+	"aggregates":                 true,
+
+	// Lookup
+	"format":               true,
+	"max_paths_per_prefix": true,
+
+	// Duplicate paths
+	"min_duplicates": true,
+
+	// Origin ASN groups
+	"expand": true,
+}
+
+// Helper: Check for unknown query parameters
+func validateQueryParams(req *http.Request) error {
+	unknown := []string{}
+	for key, _ := range req.URL.Query() {
+		if !apiKnownQueryParams[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &UnknownQueryParamsError{Params: unknown}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestValidateQueryParams(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api?q=foo&page=2&blackholed=true", nil)
+	if err := validateQueryParams(req); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+
+	req = httptest.NewRequest("GET", "/api?q=foo&comunity=23:42&foo=bar", nil)
+	err := validateQueryParams(req)
+	paramsErr, ok := err.(*UnknownQueryParamsError)
+	if !ok {
+		t.Fatal("Expected unknown query params error, got:", err)
+	}
+	if len(paramsErr.Params) != 2 ||
+		paramsErr.Params[0] != "comunity" ||
+		paramsErr.Params[1] != "foo" {
+		t.Error("Unexpected unknown params:", paramsErr.Params)
+	}
+}
+
+func TestEndpointStrictParams(t *testing.T) {
+	defer saveTestGlobals()()
+	handler := endpoint(func(
+		_req *http.Request,
+		_params httprouter.Params,
+	) (api.Response, error) {
+		return api.ConfigResponse{}, nil
+	})
+
+	AliceConfig = &Config{}
+
+	// Unknown parameters are ignored by default
+	res := httptest.NewRecorder()
+	handler(res, httptest.NewRequest("GET", "/api?comunity=23:42", nil), nil)
+	if res.Code != http.StatusOK {
+		t.Error("Expected status 200, got:", res.Code)
+	}
+
+	// Strict mode
+	AliceConfig.Server.StrictParams = true
+
+	res = httptest.NewRecorder()
+	handler(res, httptest.NewRequest("GET", "/api?comunity=23:42", nil), nil)
+	if res.Code != http.StatusBadRequest {
+		t.Error("Expected status 400, got:", res.Code)
+	}
+
+	res = httptest.NewRecorder()
+	handler(res, httptest.NewRequest("GET", "/api?q=foo", nil), nil)
+	if res.Code != http.StatusOK {
+		t.Error("Expected status 200, got:", res.Code)
+	}
+}
+
+func TestValidatePageSize(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	AliceConfig.Ui.Pagination.MaxPageSize = 500
+
+	// Default page size
+	req := httptest.NewRequest("GET", "/api?page=1", nil)
+	pageSize, err := validatePageSize(req, 250)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if pageSize != 250 {
+		t.Error("Expected default page size, got:", pageSize)
+	}
+
+	// Within bounds
+	req = httptest.NewRequest("GET", "/api?page_size=500", nil)
+	pageSize, err = validatePageSize(req, 250)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if pageSize != 500 {
+		t.Error("Expected requested page size, got:", pageSize)
+	}
+
+	// Exceeding the maximum
+	req = httptest.NewRequest("GET", "/api?page_size=501", nil)
+	_, err = validatePageSize(req, 250)
+	if _, ok := err.(*InvalidPageSizeError); !ok {
+		t.Fatal("Expected invalid page size error, got:", err)
+	}
+	_, status := apiErrorResponse("rs1", err)
+	if status != BAD_REQUEST_STATUS {
+		t.Error("Expected bad request status, got:", status)
+	}
+
+	// Invalid
+	req = httptest.NewRequest("GET", "/api?page_size=0", nil)
+	if _, err := validatePageSize(req, 250); err == nil {
+		t.Error("Expected error for page size 0")
+	}
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
 )
 
 /*
@@ -125,3 +128,72 @@ func (self BgpCommunities) Set(community string, label string) {
 	slookup := lookup.(BgpCommunities)
 	slookup[path[len(path)-1]] = label
 }
+
+// Resolve the labels of all communities in the bgp info.
+// The result maps the community, with the ASN in the
+// notation, to its label.
+func (self BgpCommunities) LookupAll(
+	bgp api.BgpInfo,
+	notation string,
+) map[string]string {
+	labels := make(map[string]string)
+	for _, communities := range []api.Communities{
+		bgp.Communities,
+		bgp.LargeCommunities,
+	} {
+		for _, c := range communities {
+			if label, err := self.Lookup(c.String()); err == nil {
+				labels[c.Format(notation)] = label
+			}
+		}
+	}
+	for _, c := range bgp.ExtCommunities {
+		if label, err := self.Lookup(c.String()); err == nil {
+			labels[c.String()] = label
+		}
+	}
+	return labels
+}
+
+// Flatten the dictionary into a map of
+// communities (including wildcards) to labels.
+func (self BgpCommunities) Flatten() map[string]string {
+	labels := make(map[string]string)
+	self.flatten("", labels)
+	return labels
+}
+
+func (self BgpCommunities) flatten(prefix string, labels map[string]string) {
+	for key, value := range self {
+		community := key
+		if prefix != "" {
+			community = prefix + ":" + key
+		}
+		switch v := value.(type) {
+		case string:
+			labels[community] = v
+		case BgpCommunities:
+			v.flatten(community, labels)
+		}
+	}
+}
+
+// Find all communities with a label containing
+// the query. The match is case insensitive.
+func (self BgpCommunities) SearchLabels(query string) []*api.CommunityLabel {
+	query = strings.ToLower(strings.TrimSpace(query))
+	results := []*api.CommunityLabel{}
+	for community, label := range self.Flatten() {
+		if !strings.Contains(strings.ToLower(label), query) {
+			continue
+		}
+		results = append(results, &api.CommunityLabel{
+			Community: community,
+			Label:     label,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Community < results[j].Community
+	})
+	return results
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
 )
 
 func TestCommunityLookup(t *testing.T) {
@@ -82,3 +84,60 @@ func TestWildcardLookup(t *testing.T) {
 		t.Error("Unexpected label for key")
 	}
 }
+
+func TestLookupAllNotation(t *testing.T) {
+	c := MakeWellKnownBgpCommunities()
+	c.Set("65537:1:2", "large label")
+
+	bgp := api.BgpInfo{
+		Communities:      api.Communities{api.Community{65535, 666}},
+		LargeCommunities: api.Communities{api.Community{65537, 1, 2}},
+	}
+
+	labels := c.LookupAll(bgp, api.ASN_NOTATION_ASPLAIN)
+	if labels["65537:1:2"] != "large label" ||
+		labels["65535:666"] != "blackhole" {
+		t.Error("Unexpected asplain labels:", labels)
+	}
+
+	labels = c.LookupAll(bgp, api.ASN_NOTATION_ASDOT)
+	if labels["1.1:1:2"] != "large label" ||
+		labels["65535:666"] != "blackhole" {
+		t.Error("Unexpected asdot labels:", labels)
+	}
+}
+
+func TestSearchLabels(t *testing.T) {
+	c := MakeWellKnownBgpCommunities()
+	c.Set("9033:65666:1", "ip bogon detected")
+	c.Set("0:*", "do not redistribute to AS$1")
+
+	results := c.SearchLabels("No Export")
+	expected := []string{
+		"65535:1048321", // no export
+		"65535:1048323", // no export subconfed
+	}
+	if len(results) != len(expected) {
+		t.Fatal("Expected", len(expected), "results, got:", len(results))
+	}
+	for i, community := range expected {
+		if results[i].Community != community {
+			t.Error("Expected", community, "got:", results[i].Community)
+		}
+	}
+
+	results = c.SearchLabels("redistribute")
+	if len(results) != 1 || results[0].Community != "0:*" {
+		t.Error("Expected wildcard community, got:", results)
+	}
+
+	results = c.SearchLabels("bogon")
+	if len(results) != 1 || results[0].Label != "ip bogon detected" {
+		t.Error("Expected bogon community, got:", results)
+	}
+
+	results = c.SearchLabels("unicorn")
+	if len(results) != 0 {
+		t.Error("Expected no results, got:", results)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+/*
+Blackhole detection
+
+A route is considered blackholed if the next hop matches
+one of the blackhole addresses, or if the route is tagged
+with a blackhole community. Both can be configured per
+source, overriding the global blackholes config.
+The well-known BLACKHOLE community (65535:666, RFC7999)
+is used if no communities are configured.
+*/
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Parse a community from a string like 65535:666 or 23:42:1.
+// Communities with three components are large communities.
+func parseCommunityString(value string) (api.Community, error) {
+	components := strings.Split(strings.TrimSpace(value), ":")
+	if len(components) != 2 && len(components) != 3 {
+		return nil, fmt.Errorf("malformed community: %s", value)
+	}
+
+	community := make(api.Community, len(components))
+	for i, c := range components {
+		v, err := strconv.Atoi(strings.TrimSpace(c))
+		if err != nil {
+			return nil, fmt.Errorf("malformed community: %s", value)
+		}
+		community[i] = v
+	}
+
+	return community, nil
+}
+
+// Parse a comma separated list of communities
+func parseCommunitiesList(value string) (api.Communities, error) {
+	communities := api.Communities{}
+	for _, c := range TrimmedStringList(value) {
+		community, err := parseCommunityString(c)
+		if err != nil {
+			return nil, err
+		}
+		communities = append(communities, community)
+	}
+	return communities, nil
+}
+
+// Check if a route carries any of the communities.
+// Depending on the length of the community, a standard or
+// a large community is matched.
+func routeHasAnyCommunity(route *api.Route, communities api.Communities) bool {
+	for _, c := range communities {
+		if route.Bgp.HasCommunity(c) || route.Bgp.HasLargeCommunity(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare two addresses. An IPv4 address and its
+// IPv4-mapped IPv6 form are considered equal.
+func addressesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ipA := net.ParseIP(a)
+	ipB := net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return false
+	}
+	return ipA.Equal(ipB)
+}
+
+// Check if a route is blackholed, either by next hop
+// or by community
+func isBlackholedRoute(
+	route *api.Route,
+	nextHops []string,
+	communities api.Communities,
+) bool {
+	for _, nextHop := range nextHops {
+		if addressesEqual(route.Gateway, nextHop) ||
+			addressesEqual(route.Bgp.NextHop, nextHop) {
+			return true
+		}
+	}
+
+	return routeHasAnyCommunity(route, communities)
+}
+
+// Get the blackhole next hops of the source,
+// falling back to the global blackholes config.
+func (self *SourceConfig) getBlackholeNextHops() []string {
+	if len(self.Blackholes) > 0 {
+		return self.Blackholes
+	}
+	return AliceConfig.Blackholes.NextHops
+}
+
+// Get the blackhole communities of the source,
+// falling back to the global blackholes config.
+func (self *SourceConfig) getBlackholeCommunities() api.Communities {
+	if self.BlackholeCommunities != nil {
+		return self.BlackholeCommunities
+	}
+	return AliceConfig.Blackholes.Communities
+}
+
+// Flag all blackholed routes
+func annotateBlackholedRoutes(
+	routes api.Routes,
+	nextHops []string,
+	communities api.Communities,
+) {
+	for _, route := range routes {
+		route.Blackholed = isBlackholedRoute(route, nextHops, communities)
+	}
+}
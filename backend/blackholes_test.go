@@ -0,0 +1,206 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func makeBlackholeTestRoutes() api.Routes {
+	return api.Routes{
+		&api.Route{
+			Id:      "community_blackholed",
+			Gateway: "192.168.1.1",
+			Bgp: api.BgpInfo{
+				NextHop: "192.168.1.1",
+				Communities: api.Communities{
+					api.Community{65535, 666},
+				},
+			},
+		},
+		&api.Route{
+			Id:      "nexthop_blackholed",
+			Gateway: "10.23.6.66",
+			Bgp: api.BgpInfo{
+				NextHop: "10.23.6.66",
+			},
+		},
+		&api.Route{
+			Id:      "large_community_blackholed",
+			Gateway: "192.168.1.2",
+			Bgp: api.BgpInfo{
+				NextHop: "192.168.1.2",
+				LargeCommunities: api.Communities{
+					api.Community{9033, 666, 0},
+				},
+			},
+		},
+		&api.Route{
+			Id:      "regular",
+			Gateway: "192.168.1.3",
+			Bgp: api.BgpInfo{
+				NextHop: "192.168.1.3",
+				Communities: api.Communities{
+					api.Community{23, 42},
+				},
+			},
+		},
+	}
+}
+
+func TestParseCommunityString(t *testing.T) {
+	c, err := parseCommunityString("65535:666")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(c) != 2 || c[0] != 65535 || c[1] != 666 {
+		t.Error("Unexpected community:", c)
+	}
+
+	c, err = parseCommunityString(" 9033:666:0 ")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(c) != 3 || c[2] != 0 {
+		t.Error("Unexpected large community:", c)
+	}
+
+	_, err = parseCommunityString("foo:bar")
+	if err == nil {
+		t.Error("Expected error for malformed community")
+	}
+}
+
+func TestBlackholedRouteDetection(t *testing.T) {
+	routes := makeBlackholeTestRoutes()
+	nextHops := []string{"10.23.6.66"}
+	communities := api.Communities{
+		api.Community{65535, 666},
+		api.Community{9033, 666, 0},
+	}
+
+	annotateBlackholedRoutes(routes, nextHops, communities)
+
+	expected := map[string]bool{
+		"community_blackholed":       true,
+		"nexthop_blackholed":         true,
+		"large_community_blackholed": true,
+		"regular":                    false,
+	}
+
+	for _, r := range routes {
+		if r.Blackholed != expected[r.Id] {
+			t.Error("Expected", r.Id, "blackholed to be", expected[r.Id])
+		}
+	}
+
+	// Without next hops, only communities are considered
+	routes = makeBlackholeTestRoutes()
+	annotateBlackholedRoutes(routes, []string{}, communities)
+	if routes[1].Blackholed {
+		t.Error("Route should not be blackholed without next hop config")
+	}
+	if !routes[0].Blackholed {
+		t.Error("Route should be blackholed by community")
+	}
+}
+
+func TestApiQueryFilterBlackholed(t *testing.T) {
+	routes := makeBlackholeTestRoutes()
+	annotateBlackholedRoutes(
+		routes,
+		[]string{"10.23.6.66"},
+		api.Communities{api.Community{65535, 666}})
+
+	u, _ := url.Parse("http://alice/api?blackholed=true")
+	filtered := apiQueryFilterBlackholed(&http.Request{URL: u}, routes)
+	if len(filtered) != 2 {
+		t.Error("Expected 2 blackholed routes, got:", len(filtered))
+	}
+
+	u, _ = url.Parse("http://alice/api?blackholed=false")
+	filtered = apiQueryFilterBlackholed(&http.Request{URL: u}, routes)
+	if len(filtered) != 2 {
+		t.Error("Expected 2 not blackholed routes, got:", len(filtered))
+	}
+
+	u, _ = url.Parse("http://alice/api")
+	filtered = apiQueryFilterBlackholed(&http.Request{URL: u}, routes)
+	if len(filtered) != 4 {
+		t.Error("Expected unfiltered routes, got:", len(filtered))
+	}
+}
+
+func TestBlackholedRouteMappedNextHop(t *testing.T) {
+	route := &api.Route{
+		Gateway: "::ffff:10.23.6.66",
+		Bgp: api.BgpInfo{
+			NextHop: "::ffff:10.23.6.66",
+		},
+	}
+
+	// The blackhole is configured in IPv4 form
+	if !isBlackholedRoute(route, []string{"10.23.6.66"}, nil) {
+		t.Error("Expected route with mapped next hop to be blackholed")
+	}
+
+	// ...and the other way round
+	route = &api.Route{
+		Gateway: "10.23.6.66",
+		Bgp: api.BgpInfo{
+			NextHop: "10.23.6.66",
+		},
+	}
+	if !isBlackholedRoute(route, []string{"::ffff:10.23.6.66"}, nil) {
+		t.Error("Expected route to match mapped blackhole next hop")
+	}
+
+	if isBlackholedRoute(route, []string{"10.23.6.67"}, nil) {
+		t.Error("Route should not be blackholed")
+	}
+}
+
+func TestBlackholesPerSource(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Blackholes: BlackholesConfig{
+			Communities: api.Communities{api.Community{65535, 666}},
+			NextHops:    []string{"10.23.6.66"},
+		},
+	}
+
+	// The first source uses the global config, while
+	// the second source overrides it.
+	rs1 := &SourceConfig{Id: "rs1"}
+	rs2 := &SourceConfig{
+		Id:                   "rs2",
+		Blackholes:           []string{"10.42.6.66"},
+		BlackholeCommunities: api.Communities{api.Community{9033, 666, 0}},
+	}
+
+	expected := map[string][]string{
+		"rs1": {"community_blackholed", "nexthop_blackholed"},
+		"rs2": {"large_community_blackholed"},
+	}
+
+	for _, source := range []*SourceConfig{rs1, rs2} {
+		response := &api.RoutesResponse{
+			Imported: makeBlackholeTestRoutes(),
+		}
+		response = annotateRoutesResponse(source, response)
+
+		blackholed := []string{}
+		for _, route := range response.Imported {
+			if route.Blackholed {
+				blackholed = append(blackholed, route.Id)
+			}
+		}
+		if strings.Join(blackholed, ",") !=
+			strings.Join(expected[source.Id], ",") {
+			t.Error(source.Id, "- unexpected blackholed routes:", blackholed)
+		}
+	}
+}
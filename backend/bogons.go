@@ -0,0 +1,74 @@
+package main
+
+/*
+Bogon prefixes
+
+Routes for reserved or unallocated address space
+are flagged, as they often are the result of a leak.
+*/
+
+import (
+	"net"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Built-in list of bogon prefixes, used
+// unless overridden in the config.
+var DEFAULT_BOGON_PREFIXES = []string{
+	// IPv4
+	"0.0.0.0/8",       // "This" network
+	"10.0.0.0/8",      // Private-use (RFC 1918)
+	"100.64.0.0/10",   // Shared address space (RFC 6598)
+	"127.0.0.0/8",     // Loopback
+	"169.254.0.0/16",  // Link local
+	"172.16.0.0/12",   // Private-use (RFC 1918)
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // Documentation (TEST-NET-1)
+	"192.168.0.0/16",  // Private-use (RFC 1918)
+	"198.18.0.0/15",   // Benchmarking
+	"198.51.100.0/24", // Documentation (TEST-NET-2)
+	"203.0.113.0/24",  // Documentation (TEST-NET-3)
+	"224.0.0.0/4",     // Multicast
+	"240.0.0.0/4",     // Reserved
+
+	// IPv6
+	"::/8",          // Loopback, unspecified, IPv4-mapped
+	"100::/64",      // Discard-only
+	"2001:2::/48",   // Benchmarking
+	"2001:10::/28",  // ORCHID
+	"2001:db8::/32", // Documentation
+	"3ffe::/16",     // Former 6bone
+	"fc00::/7",      // Unique local (ULA)
+	"fe80::/10",     // Link local
+	"fec0::/10",     // Site local
+	"ff00::/8",      // Multicast
+}
+
+// Check if a network is equal to or more specific
+// than any of the bogon prefixes.
+func isBogonNetwork(network string, bogons []*net.IPNet) bool {
+	ip, prefix, err := net.ParseCIDR(network)
+	if err != nil {
+		return false
+	}
+	ones, bits := prefix.Mask.Size()
+
+	for _, bogon := range bogons {
+		bogonOnes, bogonBits := bogon.Mask.Size()
+		if bogonBits != bits || bogonOnes > ones {
+			continue
+		}
+		if bogon.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flag all routes for bogon prefixes
+func annotateBogonRoutes(routes api.Routes, bogons []*net.IPNet) {
+	for _, route := range routes {
+		route.Bogon = isBogonNetwork(route.Network, bogons)
+	}
+}
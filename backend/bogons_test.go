@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/go-ini/ini"
+)
+
+func TestAnnotateBogonRoutes(t *testing.T) {
+	bogons, err := parsePrefixList(
+		"10.0.0.0/8, 192.0.2.0/24, 2001:db8::/32, fc00::/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := api.Routes{
+		&api.Route{Id: "private", Network: "10.23.0.0/16"},
+		&api.Route{Id: "documentation", Network: "192.0.2.0/24"},
+		&api.Route{Id: "ula", Network: "fd42::/48"},
+		&api.Route{Id: "public", Network: "193.0.0.0/21"},
+		&api.Route{Id: "public6", Network: "2a00:1234::/32"},
+		&api.Route{Id: "covering", Network: "10.0.0.0/7"},
+	}
+	annotateBogonRoutes(routes, bogons)
+
+	expected := map[string]bool{
+		"private":       true,
+		"documentation": true,
+		"ula":           true,
+		"public":        false,
+		"public6":       false,
+		"covering":      false,
+	}
+	for _, route := range routes {
+		if route.Bogon != expected[route.Id] {
+			t.Error("Unexpected bogon flag for route:", route.Id)
+		}
+	}
+
+	// Filter by query
+	u, _ := url.Parse("http://alice/api?bogon=false")
+	filtered := apiQueryFilterBogon(&http.Request{URL: u}, routes)
+	if len(filtered) != 3 {
+		t.Error("Expected 3 routes, got:", len(filtered))
+	}
+	for _, route := range filtered {
+		if route.Bogon {
+			t.Error("Unexpected bogon route:", route.Id)
+		}
+	}
+}
+
+func TestBogonsConfig(t *testing.T) {
+	// Built-in defaults
+	config, err := getBogonsConfig(ini.Empty())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Prefixes) != len(DEFAULT_BOGON_PREFIXES) {
+		t.Error("Expected default bogons, got:", config.Prefixes)
+	}
+	if !isBogonNetwork("192.168.23.0/24", config.Prefixes) {
+		t.Error("Expected 192.168.23.0/24 to be a bogon")
+	}
+
+	// Override and extend
+	file, err := ini.Load([]byte(`
+[bogons]
+prefixes = 10.0.0.0/8
+additional_prefixes = 2001:db8::/32
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err = getBogonsConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Prefixes) != 2 {
+		t.Error("Expected 2 bogon prefixes, got:", config.Prefixes)
+	}
+	if isBogonNetwork("192.168.23.0/24", config.Prefixes) {
+		t.Error("Built-in bogons should be replaced")
+	}
+
+	// Disabled
+	file, _ = ini.Load([]byte("[bogons]\nenabled = false\n"))
+	config, _ = getBogonsConfig(file)
+	if len(config.Prefixes) != 0 {
+		t.Error("Expected no bogons, got:", config.Prefixes)
+	}
+}
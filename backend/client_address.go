@@ -0,0 +1,57 @@
+package main
+
+/*
+Client addresses may only be logged in an anonymized
+form, depending on the configured method:
+
+  none      log the address as is
+  truncate  zero the last octet (v4) or the last 80 bits (v6)
+  hash      log a salted hash of the address
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+)
+
+// Helper: Get the address of the client
+// from the request.
+func clientAddress(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// Anonymize an address with the given method
+func anonymizeClientAddress(address, method, salt string) string {
+	switch method {
+	case ANONYMIZE_CLIENT_IPS_TRUNCATE:
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return "invalid"
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4.Mask(net.CIDRMask(24, 32)).String()
+		}
+		return ip.Mask(net.CIDRMask(48, 128)).String()
+
+	case ANONYMIZE_CLIENT_IPS_HASH:
+		sum := sha256.Sum256([]byte(salt + address))
+		return hex.EncodeToString(sum[:8])
+	}
+
+	return address
+}
+
+// Get the client address for logging, anonymized
+// as configured.
+func apiLogClientAddress(req *http.Request) string {
+	return anonymizeClientAddress(
+		clientAddress(req),
+		AliceConfig.Server.AnonymizeClientIps,
+		AliceConfig.Server.ClientIpHashSalt)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnonymizeClientAddress(t *testing.T) {
+	addr := anonymizeClientAddress("192.0.2.42", ANONYMIZE_CLIENT_IPS_TRUNCATE, "")
+	if addr != "192.0.2.0" {
+		t.Error("Expected truncated v4 address, got:", addr)
+	}
+
+	addr = anonymizeClientAddress(
+		"2001:db8:1:2:3:4:5:6", ANONYMIZE_CLIENT_IPS_TRUNCATE, "")
+	if addr != "2001:db8:1::" {
+		t.Error("Expected truncated v6 address, got:", addr)
+	}
+
+	addr = anonymizeClientAddress("192.0.2.42", ANONYMIZE_CLIENT_IPS_NONE, "")
+	if addr != "192.0.2.42" {
+		t.Error("Expected address to be unchanged, got:", addr)
+	}
+
+	hashed := anonymizeClientAddress("192.0.2.42", ANONYMIZE_CLIENT_IPS_HASH, "s1")
+	if strings.Contains(hashed, "192.0.2") || len(hashed) != 16 {
+		t.Error("Expected hashed address, got:", hashed)
+	}
+	if hashed != anonymizeClientAddress(
+		"192.0.2.42", ANONYMIZE_CLIENT_IPS_HASH, "s1") {
+		t.Error("Expected hash to be stable")
+	}
+	if hashed == anonymizeClientAddress(
+		"192.0.2.42", ANONYMIZE_CLIENT_IPS_HASH, "s2") {
+		t.Error("Expected hash to depend on the salt")
+	}
+}
+
+func TestApiLogAccessAnonymized(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Server: ServerConfig{
+			AnonymizeClientIps: ANONYMIZE_CLIENT_IPS_TRUNCATE,
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	req := httptest.NewRequest("GET", "/api/v1/routeservers", nil)
+	req.RemoteAddr = "198.51.100.23:4242"
+	apiLogAccess(req, 200, time.Millisecond)
+
+	output := buf.String()
+	if strings.Contains(output, "198.51.100.23") {
+		t.Error("Expected client address to be anonymized:", output)
+	}
+	if !strings.Contains(output, "198.51.100.0 GET /api/v1/routeservers") {
+		t.Error("Unexpected log output:", output)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+/*
+Table columns are configured as dot-paths into the
+api responses, e.g. bgp.local_pref or neighbour.asn.
+The path elements are the json field names of the
+api structs. Keys of maps (like details) are accepted
+as they are.
+*/
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Columns rendered by a special widget in the frontend
+var COLUMNS_WIDGETS = map[string]bool{
+	"flags":  true,
+	"ASPath": true,
+}
+
+// Helper: Get the index of a struct field by its json name
+func jsonFieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = t.Field(i).Name
+		}
+		if strings.EqualFold(tag, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Resolve a path in a value. Unknown paths
+// resolve to an empty string.
+func resolveColumnPath(value interface{}, path string) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	for _, elem := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return "", false
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			i, ok := jsonFieldIndex(v.Type(), elem)
+			if !ok {
+				return "", false
+			}
+			v = v.Field(i)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return "", false
+			}
+			v = v.MapIndex(reflect.ValueOf(elem))
+			if !v.IsValid() {
+				return "", false
+			}
+		default:
+			return "", false
+		}
+	}
+
+	if !v.IsValid() || !v.CanInterface() {
+		return "", false
+	}
+	return v.Interface(), true
+}
+
+// Compare resolved column values: Numbers are compared
+// by value, all other values by their formatted string.
+// Blank values are sorted first.
+func compareColumnValues(a, b interface{}) int {
+	va := reflect.ValueOf(a)
+	vb := reflect.ValueOf(b)
+	switch {
+	case isIntValue(va) && isIntValue(vb):
+		if va.Int() != vb.Int() {
+			return compareBool(va.Int() < vb.Int())
+		}
+		return 0
+	case isUintValue(va) && isUintValue(vb):
+		if va.Uint() != vb.Uint() {
+			return compareBool(va.Uint() < vb.Uint())
+		}
+		return 0
+	case isFloatValue(va) && isFloatValue(vb):
+		if va.Float() != vb.Float() {
+			return compareBool(va.Float() < vb.Float())
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// Helper: -1 if less, 1 otherwise
+func compareBool(less bool) int {
+	if less {
+		return -1
+	}
+	return 1
+}
+
+func isIntValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUintValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isFloatValue(v reflect.Value) bool {
+	return v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+// Check if a path can be resolved in a type
+func isColumnPath(t reflect.Type, path string) bool {
+	for _, elem := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			i, ok := jsonFieldIndex(t, elem)
+			if !ok {
+				return false
+			}
+			t = t.Field(i).Type
+		case reflect.Map, reflect.Interface:
+			return true // Can not be checked
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Log configured columns, which can not be resolved.
+// Those will be rendered blank.
+func checkColumns(section string, columns []string, prototype interface{}) {
+	t := reflect.TypeOf(prototype)
+	for _, column := range columns {
+		if COLUMNS_WIDGETS[column] || isColumnPath(t, column) {
+			continue
+		}
+		log.Println("Unknown column in", section+":", column)
+	}
+}
+
+// Check all configured table columns
+func checkUiColumns(config UiConfig) {
+	checkColumns("routes_columns",
+		config.RoutesColumnsOrder, api.Route{})
+	checkColumns("neighbours_columns",
+		config.NeighboursColumnsOrder, api.Neighbour{})
+	checkColumns("lookup_columns",
+		config.LookupColumnsOrder, api.LookupRoute{})
+}
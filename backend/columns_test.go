@@ -0,0 +1,95 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestResolveColumnPath(t *testing.T) {
+	route := &api.LookupRoute{
+		Network: "10.0.0.0/8",
+		Neighbour: &api.Neighbour{
+			Asn: 2342,
+		},
+		Bgp: api.BgpInfo{
+			AsPath:    []int{2342, 23},
+			LocalPref: 100,
+			Communities: api.Communities{
+				api.Community{23, 42},
+			},
+		},
+		Details: api.Details{
+			"source": "bird",
+		},
+	}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"network", "10.0.0.0/8"},
+		{"neighbour.asn", 2342},
+		{"bgp.local_pref", 100},
+		{"bgp.as_path", []int{2342, 23}},
+		{"bgp.communities", api.Communities{api.Community{23, 42}}},
+		{"details.source", "bird"},
+	}
+	for _, test := range tests {
+		value, ok := resolveColumnPath(route, test.path)
+		if !ok {
+			t.Error("Expected path to resolve:", test.path)
+			continue
+		}
+		if !reflect.DeepEqual(value, test.expected) {
+			t.Error("Unexpected value for", test.path, ":", value)
+		}
+	}
+
+	// Invalid paths are blank
+	for _, path := range []string{"bgp.foo", "network.foo", "details.bar"} {
+		value, ok := resolveColumnPath(route, path)
+		if ok || value != "" {
+			t.Error("Expected path not to resolve:", path, value)
+		}
+	}
+}
+
+func TestIsColumnPath(t *testing.T) {
+	routeType := reflect.TypeOf(api.LookupRoute{})
+	for _, path := range []string{
+		"bgp.local_pref", "neighbour.asn", "routeserver.name", "details.foo",
+	} {
+		if !isColumnPath(routeType, path) {
+			t.Error("Expected path to be valid:", path)
+		}
+	}
+	if isColumnPath(routeType, "bgp.local_preference") {
+		t.Error("Expected path to be invalid")
+	}
+
+	// Neighbours columns are case insensitive
+	if !isColumnPath(reflect.TypeOf(api.Neighbour{}), "Uptime") {
+		t.Error("Expected Uptime to be a valid neighbour column")
+	}
+}
+
+func TestCompareColumnValues(t *testing.T) {
+	tests := []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{2, 10, -1},
+		{10, 2, 1},
+		{uint32(23), uint32(23), 0},
+		{"b", "a", 1},
+		{"", "a", -1}, // Blank values first
+		{"", 100, -1}, // Unresolved values are blank
+	}
+	for _, test := range tests {
+		if c := compareColumnValues(test.a, test.b); c != test.expected {
+			t.Error("Unexpected comparison of", test.a, test.b, ":", c)
+		}
+	}
+}
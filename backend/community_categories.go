@@ -0,0 +1,67 @@
+package main
+
+/*
+Community categories
+
+Communities are labeled in different sections of the
+config. The section defines the category of the label:
+A community labeled in [rejection_reasons] marks
+a route as rejected, [noexport_reasons] as not exported
+and [rejection_candidates] as candidate for rejection.
+*/
+
+import (
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+const (
+	COMMUNITY_CATEGORY_REJECTION = "rejection"
+	COMMUNITY_CATEGORY_NOEXPORT  = "noexport"
+
+	COMMUNITY_CATEGORY_REJECT_CANDIDATE = "reject_candidate"
+)
+
+// Get the labeled communities of a category
+func getCommunityCategoryLabels(category string) (BgpCommunities, bool) {
+	switch category {
+	case COMMUNITY_CATEGORY_REJECTION:
+		return AliceConfig.Ui.RoutesRejections.Reasons, true
+	case COMMUNITY_CATEGORY_NOEXPORT:
+		return AliceConfig.Ui.RoutesNoexports.Reasons, true
+	case COMMUNITY_CATEGORY_REJECT_CANDIDATE:
+		return AliceConfig.Ui.RoutesRejectCandidates.Communities, true
+	}
+	return nil, false
+}
+
+// Check if any standard or large community of the route
+// is labeled.
+func routeHasLabeledCommunity(route *api.Route, labels BgpCommunities) bool {
+	for _, communities := range []api.Communities{
+		route.Bgp.Communities,
+		route.Bgp.LargeCommunities,
+	} {
+		for _, c := range communities {
+			if _, err := labels.Lookup(c.String()); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Filter routes by the presence or absence of
+// any labeled community.
+func filterRoutesByLabeledCommunities(
+	routes api.Routes,
+	labels BgpCommunities,
+	present bool,
+) api.Routes {
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		if routeHasLabeledCommunity(r, labels) == present {
+			results = append(results, r)
+		}
+	}
+	return results
+}
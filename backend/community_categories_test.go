@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func makeCommunityCategoryTestRoutes() api.Routes {
+	return api.Routes{
+		&api.Route{
+			Id: "bogon",
+			Bgp: api.BgpInfo{
+				LargeCommunities: api.Communities{
+					api.Community{9033, 65666, 1},
+				},
+			},
+		},
+		&api.Route{
+			Id: "too_specific",
+			Bgp: api.BgpInfo{
+				Communities: api.Communities{
+					api.Community{23, 42},
+				},
+				LargeCommunities: api.Communities{
+					api.Community{9033, 65666, 3},
+				},
+			},
+		},
+		&api.Route{
+			Id: "regular",
+			Bgp: api.BgpInfo{
+				LargeCommunities: api.Communities{
+					api.Community{9033, 65667, 1},
+				},
+			},
+		},
+	}
+}
+
+func TestFilterRoutesByLabeledCommunities(t *testing.T) {
+	labels := make(BgpCommunities)
+	labels.Set("9033:65666:1", "An IP Bogon was detected")
+	labels.Set("9033:65666:3", "Prefix is longer than 24")
+
+	routes := makeCommunityCategoryTestRoutes()
+
+	filtered := filterRoutesByLabeledCommunities(routes, labels, true)
+	if len(filtered) != 2 {
+		t.Error("Expected 2 routes, got:", len(filtered))
+	}
+	for _, r := range filtered {
+		if r.Id == "regular" {
+			t.Error("Route should not be matched:", r.Id)
+		}
+	}
+
+	filtered = filterRoutesByLabeledCommunities(routes, labels, false)
+	if len(filtered) != 1 || filtered[0].Id != "regular" {
+		t.Error("Expected only the regular route")
+	}
+}
+
+func TestApiQueryFilterCommunityCategory(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	AliceConfig.Ui.RoutesRejections.Reasons = make(BgpCommunities)
+	AliceConfig.Ui.RoutesRejections.Reasons.Set("9033:65666:1", "Bogon")
+	AliceConfig.Ui.RoutesRejections.Reasons.Set("9033:65666:3", "Too specific")
+
+	routes := makeCommunityCategoryTestRoutes()
+
+	u, _ := url.Parse("http://alice/api?community_category=rejection")
+	filtered := apiQueryFilterCommunityCategory(&http.Request{URL: u}, routes)
+	if len(filtered) != 2 {
+		t.Error("Expected 2 rejected routes, got:", len(filtered))
+	}
+
+	u, _ = url.Parse("http://alice/api?exclude_community_category=rejection")
+	filtered = apiQueryFilterCommunityCategory(&http.Request{URL: u}, routes)
+	if len(filtered) != 1 {
+		t.Error("Expected 1 route, got:", len(filtered))
+	}
+
+	// Unknown categories are ignored
+	u, _ = url.Parse("http://alice/api?community_category=foo")
+	filtered = apiQueryFilterCommunityCategory(&http.Request{URL: u}, routes)
+	if len(filtered) != 3 {
+		t.Error("Expected unfiltered routes, got:", len(filtered))
+	}
+}
+
+func TestLabeledRoutesAtRejectCandidates(t *testing.T) {
+	defer saveTestGlobals()()
+	candidates := BgpCommunities{}
+	candidates.Set("23:42:46", "reject-candidate-1")
+
+	AliceConfig = &Config{}
+	AliceConfig.Ui.RoutesRejectCandidates.Communities = candidates
+
+	candidate := &api.Route{
+		Id: "candidate",
+		Bgp: api.BgpInfo{
+			LargeCommunities: api.Communities{api.Community{23, 42, 46}},
+		},
+	}
+	other := &api.Route{
+		Id: "other",
+		Bgp: api.BgpInfo{
+			LargeCommunities: api.Communities{api.Community{23, 42, 1}},
+		},
+	}
+
+	store := &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{
+			"rs1": &api.RoutesResponse{
+				Imported: api.Routes{candidate, other},
+				Filtered: api.Routes{other},
+			},
+		},
+	}
+
+	labels, ok := getCommunityCategoryLabels(COMMUNITY_CATEGORY_REJECT_CANDIDATE)
+	if !ok {
+		t.Fatal("Expected reject candidate category")
+	}
+
+	imported, filtered := store.LabeledRoutesAt("rs1", labels)
+	if len(imported) != 1 || imported[0].Id != "candidate" {
+		t.Error("Expected only the candidate route, got:", imported)
+	}
+	if len(filtered) != 0 {
+		t.Error("Expected no filtered candidates, got:", filtered)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+/*
+Community groups
+
+Related communities can be grouped by name in the
+[community_groups] section of the config, e.g.
+
+    [community_groups]
+    do_not_announce = 65000:0:1, 65000:0:2, 0:65000
+
+Routes can be filtered by the group name: A route
+matches the group if it carries any of the communities.
+*/
+
+import (
+	"fmt"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+
+	"github.com/go-ini/ini"
+)
+
+type CommunityGroupsConfig struct {
+	Groups map[string]api.Communities
+}
+
+// Get the communities of a group
+func (self CommunityGroupsConfig) Lookup(name string) (api.Communities, bool) {
+	communities, ok := self.Groups[name]
+	return communities, ok
+}
+
+// Get the community groups config
+func getCommunityGroupsConfig(config *ini.File) (CommunityGroupsConfig, error) {
+	groups := make(map[string]api.Communities)
+
+	for _, key := range config.Section("community_groups").Keys() {
+		communities, err := parseCommunitiesList(key.MustString(""))
+		if err != nil {
+			return CommunityGroupsConfig{}, fmt.Errorf(
+				"community_groups: %s: %s", key.Name(), err)
+		}
+		groups[key.Name()] = communities
+	}
+
+	return CommunityGroupsConfig{
+		Groups: groups,
+	}, nil
+}
+
+// Filter routes by a community group, only routes
+// carrying any community of the group are included.
+func filterRoutesByCommunityGroup(
+	routes api.Routes,
+	communities api.Communities,
+) api.Routes {
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		if routeHasAnyCommunity(r, communities) {
+			results = append(results, r)
+		}
+	}
+	return results
+}
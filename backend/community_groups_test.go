@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+
+	"github.com/go-ini/ini"
+)
+
+func TestGetCommunityGroupsConfig(t *testing.T) {
+	parsed, err := ini.Load([]byte(
+		"[community_groups]\n" +
+			"do_not_announce = 65000:0:1, 65000:0:2, 0:9033\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := getCommunityGroupsConfig(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	communities, ok := config.Lookup("do_not_announce")
+	if !ok {
+		t.Fatal("expected group do_not_announce")
+	}
+	if len(communities) != 3 {
+		t.Error("expected 3 communities, got:", communities)
+	}
+
+	// Invalid communities are rejected
+	parsed, _ = ini.Load([]byte("[community_groups]\nbroken = 65000:foo\n"))
+	if _, err := getCommunityGroupsConfig(parsed); err == nil {
+		t.Error("expected invalid community to be rejected")
+	}
+}
+
+func TestApiQueryFilterCommunityGroup(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		CommunityGroups: CommunityGroupsConfig{
+			Groups: map[string]api.Communities{
+				"do_not_announce": api.Communities{
+					api.Community{65000, 0, 1},
+					api.Community{65000, 0, 2},
+					api.Community{0, 9033},
+				},
+			},
+		},
+	}
+
+	routes := api.Routes{
+		&api.Route{Id: "large_1", Bgp: api.BgpInfo{
+			LargeCommunities: api.Communities{{65000, 0, 1}}}},
+		&api.Route{Id: "large_2", Bgp: api.BgpInfo{
+			LargeCommunities: api.Communities{{65000, 0, 2}}}},
+		&api.Route{Id: "standard", Bgp: api.BgpInfo{
+			Communities: api.Communities{{23, 42}, {0, 9033}}}},
+		&api.Route{Id: "other", Bgp: api.BgpInfo{
+			Communities:      api.Communities{{65000, 0}},
+			LargeCommunities: api.Communities{{65000, 0, 3}}}},
+	}
+
+	u, _ := url.Parse("http://alice/api?community_group=do_not_announce")
+	filtered := apiQueryFilterCommunityGroup(&http.Request{URL: u}, routes)
+	if len(filtered) != 3 {
+		t.Fatal("expected 3 routes, got:", len(filtered))
+	}
+	for _, r := range filtered {
+		if r.Id == "other" {
+			t.Error("unexpected route in group:", r.Id)
+		}
+	}
+
+	// Unknown groups are ignored
+	u, _ = url.Parse("http://alice/api?community_group=unknown")
+	filtered = apiQueryFilterCommunityGroup(&http.Request{URL: u}, routes)
+	if len(filtered) != 4 {
+		t.Error("expected all routes, got:", len(filtered))
+	}
+}
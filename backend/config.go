@@ -2,10 +2,17 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"net"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/alice-lg/alice-lg/backend/api"
 	"github.com/alice-lg/alice-lg/backend/sources"
 	"github.com/alice-lg/alice-lg/backend/sources/birdwatcher"
 	"github.com/alice-lg/alice-lg/backend/sources/gobgp"
@@ -17,6 +24,17 @@ const SOURCE_UNKNOWN = 0
 const SOURCE_BIRDWATCHER = 1
 const SOURCE_GOBGP = 2
 
+// Lookups are served from the routes store
+// or from the source directly
+const LOOKUP_MODE_STORE = "store"
+const LOOKUP_MODE_LIVE = "live"
+
+const (
+	ANONYMIZE_CLIENT_IPS_NONE     = "none"
+	ANONYMIZE_CLIENT_IPS_TRUNCATE = "truncate"
+	ANONYMIZE_CLIENT_IPS_HASH     = "hash"
+)
+
 type ServerConfig struct {
 	Listen                         string `ini:"listen_http"`
 	EnablePrefixLookup             bool   `ini:"enable_prefix_lookup"`
@@ -24,15 +42,57 @@ type ServerConfig struct {
 	RoutesStoreRefreshInterval     int    `ini:"routes_store_refresh_interval"`
 	Asn                            int    `ini:"asn"`
 	EnableNeighborsStatusRefresh   bool   `ini:"enable_neighbors_status_refresh"`
+	MaxAsPathLength                int    `ini:"max_as_path_length"`
+	NeighbourDescriptionFallback   string `ini:"neighbour_description_fallback"`
+	CollapseIPv4MappedNextHops     bool   `ini:"collapse_ipv4_mapped_next_hops"`
+	StrictParams                   bool   `ini:"strict_params"`
+	AccessLog                      bool   `ini:"access_log"`
+	AnonymizeClientIps             string `ini:"anonymize_client_ips"`
+	ClientIpHashSalt               string `ini:"client_ip_hash_salt"`
+	MaxConcurrentRequests          int    `ini:"max_concurrent_requests"`
+	MaxQueuedRequests              int    `ini:"max_queued_requests"`
+	RequestQueueTimeout            int    `ini:"request_queue_timeout"`
+	CacheAllRoutesPayload          bool   `ini:"cache_all_routes_payload"`
+	IncludeSourceTiming            bool   `ini:"include_source_timing"`
+	RequestTimeout                 int    `ini:"request_timeout"`
+	AsnNotation                    string `ini:"asn_notation"`
+	UptimeFormat                   string `ini:"uptime_format"`
+	CommunitiesFormat              string `ini:"communities_format"`
+	SourcesDir                     string `ini:"sources_dir"`
+	MaxLookupSources               int    `ini:"max_lookup_sources"`
+	LookupSourcesOrder             string `ini:"lookup_sources_order"`
+	IncludeConfigHash              bool   `ini:"include_config_hash"`
+	MaxRequestBodySize             int64  `ini:"max_request_body_size"`
+	TLSCert                        string `ini:"tls_cert"`
+	TLSKey                         string `ini:"tls_key"`
+	TLSClientCA                    string `ini:"tls_client_ca"`
+
+	// States of neighbours counted as up
+	NeighbourUpStates []string `ini:"neighbour_up_states"`
+
+	// Static headers added to all responses
+	ResponseHeaders map[string]string `ini:"-"`
 }
 
 type HousekeepingConfig struct {
-	Interval           int  `ini:"interval"`
-	ForceReleaseMemory bool `ini:"force_release_memory"`
+	Interval             int  `ini:"interval"`
+	ForceReleaseMemory   bool `ini:"force_release_memory"`
+	ExpireCaches         bool `ini:"expire_caches"`
+	ExpireCachesInterval int  `ini:"expire_caches_interval"`
+}
+
+type ExportConfig struct {
+	Enabled    bool     `ini:"enabled"`
+	Directory  string   `ini:"directory"`
+	Interval   int      `ini:"interval"`
+	Format     string   `ini:"format"`
+	Filename   string   `ini:"filename"`
+	Neighbours []string `ini:"neighbours"`
 }
 
 type RejectionsConfig struct {
 	Reasons BgpCommunities
+	Details BgpCommunities // Optional extended descriptions
 }
 
 type NoexportsConfig struct {
@@ -44,6 +104,27 @@ type RejectCandidatesConfig struct {
 	Communities BgpCommunities
 }
 
+type BlackholesConfig struct {
+	Communities api.Communities
+	NextHops    []string
+}
+
+type HiddenRoutesConfig struct {
+	Communities api.Communities
+}
+
+type FeaturedRoutesConfig struct {
+	Communities api.Communities
+}
+
+type AggregatesConfig struct {
+	Prefixes []*net.IPNet
+}
+
+type BogonsConfig struct {
+	Prefixes []*net.IPNet
+}
+
 type RpkiConfig struct {
 	// Define communities
 	Enabled    bool     `ini:"enabled"`
@@ -84,6 +165,9 @@ type PaginationConfig struct {
 	RoutesFilteredPageSize    int `ini:"routes_filtered_page_size"`
 	RoutesAcceptedPageSize    int `ini:"routes_accepted_page_size"`
 	RoutesNotExportedPageSize int `ini:"routes_not_exported_page_size"`
+
+	// Upper bound for client requested page sizes
+	MaxPageSize int `ini:"max_page_size"`
 }
 
 type SourceConfig struct {
@@ -92,24 +176,72 @@ type SourceConfig struct {
 	Name  string
 	Group string
 
-	// Blackhole IPs
-	Blackholes []string
+	// Blackhole IPs and communities, overriding
+	// the global blackholes config if present
+	Blackholes           []string
+	BlackholeCommunities api.Communities
+
+	// Neighbour addresses or ASNs excluded from responses
+	HiddenNeighbours []string
+
+	// Default sort if none is requested
+	RoutesSort     string
+	NeighboursSort string
+
+	// Serve lookups from the store or live
+	LookupMode string
+
+	// Route tabs shown for the neighbours
+	RouteTabs []string
+
+	// Retry the initial refresh of an unreachable source
+	StartupRetries    int
+	StartupRetryDelay time.Duration
+
+	// Refresh the neighbours of the source in a different
+	// interval than the store. Inherited when 0.
+	NeighboursStoreRefreshInterval time.Duration
+
+	// Serve the data of the last successful refresh after
+	// a failed refresh for the grace period. Disabled when 0.
+	StaleGracePeriod time.Duration
+
+	// Refreshing is suspended during maintenance
+	MaintenanceWindow *MaintenanceWindow
+
+	// Warn about refreshes with fewer routes and
+	// optionally keep the previous routes
+	MinRoutes             int
+	MinRoutesKeepPrevious bool
+
+	// Remove private ASNs from the displayed AS paths
+	StripPrivateAsns           bool
+	StripPrivateAsnsKeepOrigin bool
 
 	// Source configurations
 	Type        int
 	Birdwatcher birdwatcher.Config
-	GoBGP 		gobgp.Config
+	GoBGP       gobgp.Config
 
 	// Source instance
 	instance sources.Source
 }
 
 type Config struct {
-	Server       ServerConfig
-	Housekeeping HousekeepingConfig
-	Ui           UiConfig
-	Sources      []*SourceConfig
-	File         string
+	Server          ServerConfig
+	Housekeeping    HousekeepingConfig
+	Blackholes      BlackholesConfig
+	HiddenRoutes    HiddenRoutesConfig
+	FeaturedRoutes  FeaturedRoutesConfig
+	Aggregates      AggregatesConfig
+	Bogons          BogonsConfig
+	CommunityGroups CommunityGroupsConfig
+	Export          ExportConfig
+	ResponseCache   ResponseCacheConfig
+	Ui              UiConfig
+	Sources         []*SourceConfig
+	SourcesHash     string
+	File            string
 }
 
 // Get source by id
@@ -340,8 +472,15 @@ func getRoutesRejections(config *ini.File) (RejectionsConfig, error) {
 		make(BgpCommunities),
 		reasonsConfig.Body())
 
+	// Reasons may carry an extended description, e.g.
+	// with hints on how to fix the announcement.
+	details := parseAndMergeCommunities(
+		make(BgpCommunities),
+		config.Section("rejection_reasons_details").Body())
+
 	rejectionsConfig := RejectionsConfig{
 		Reasons: reasons,
+		Details: details,
 	}
 
 	return rejectionsConfig, nil
@@ -386,6 +525,126 @@ func getRejectCandidatesConfig(config *ini.File) (RejectCandidatesConfig, error)
 	return conf, nil
 }
 
+// Get blackhole communities, fall back to the
+// well-known BLACKHOLE community (RFC7999)
+func getBlackholesConfig(config *ini.File) (BlackholesConfig, error) {
+	value := config.Section("blackholes").Key("communities").MustString(
+		"65535:666")
+
+	communities, err := parseCommunitiesList(value)
+	if err != nil {
+		return BlackholesConfig{}, err
+	}
+
+	nextHops := TrimmedStringList(
+		config.Section("blackholes").Key("next_hops").MustString(""))
+
+	return BlackholesConfig{
+		Communities: communities,
+		NextHops:    nextHops,
+	}, nil
+}
+
+// Get hidden routes config
+func getHiddenRoutesConfig(config *ini.File) (HiddenRoutesConfig, error) {
+	value := config.Section("hidden_routes").Key("communities").MustString("")
+
+	communities, err := parseCommunitiesList(value)
+	if err != nil {
+		return HiddenRoutesConfig{}, err
+	}
+
+	return HiddenRoutesConfig{
+		Communities: communities,
+	}, nil
+}
+
+// Get featured routes config
+func getFeaturedRoutesConfig(config *ini.File) (FeaturedRoutesConfig, error) {
+	value := config.Section("featured_routes").Key("communities").MustString("")
+
+	communities, err := parseCommunitiesList(value)
+	if err != nil {
+		return FeaturedRoutesConfig{}, err
+	}
+
+	return FeaturedRoutesConfig{
+		Communities: communities,
+	}, nil
+}
+
+// Get aggregate prefixes config
+func getAggregatesConfig(config *ini.File) (AggregatesConfig, error) {
+	value := config.Section("aggregates").Key("prefixes").MustString("")
+
+	prefixes, err := parsePrefixList(value)
+	if err != nil {
+		return AggregatesConfig{}, err
+	}
+
+	return AggregatesConfig{
+		Prefixes: prefixes,
+	}, nil
+}
+
+// Get bogons config: The built-in list of bogon
+// prefixes can be replaced or extended.
+func getBogonsConfig(config *ini.File) (BogonsConfig, error) {
+	section := config.Section("bogons")
+	if !section.Key("enabled").MustBool(true) {
+		return BogonsConfig{}, nil
+	}
+
+	value := strings.Join(DEFAULT_BOGON_PREFIXES, ",")
+	if section.HasKey("prefixes") {
+		value = section.Key("prefixes").MustString("")
+	}
+	value += "," + section.Key("additional_prefixes").MustString("")
+
+	prefixes, err := parsePrefixList(value)
+	if err != nil {
+		return BogonsConfig{}, err
+	}
+
+	return BogonsConfig{
+		Prefixes: prefixes,
+	}, nil
+}
+
+// Get scheduled routes export config
+func getExportConfig(config *ini.File) ExportConfig {
+	section := config.Section("export")
+
+	export := ExportConfig{
+		Interval: 60,
+		Filename: "{source}_{neighbour}_{timestamp}.json",
+	}
+	section.MapTo(&export)
+
+	export.Format = section.Key("format").In(
+		EXPORT_FORMAT_JSON,
+		[]string{EXPORT_FORMAT_JSON, EXPORT_FORMAT_NDJSON})
+	if export.Interval <= 0 {
+		export.Interval = 60
+	}
+
+	return export
+}
+
+// Get the response cache config. Without a TTL
+// for an endpoint group, the default TTL is used.
+func getResponseCacheConfig(config *ini.File) ResponseCacheConfig {
+	responseCache := ResponseCacheConfig{
+		StatusTtl:     -1,
+		NeighboursTtl: -1,
+		RoutesTtl:     -1,
+		LookupTtl:     -1,
+	}
+	config.Section("response_cache").MapTo(&responseCache)
+
+	return responseCache
+}
+
 // Get UI config: RPKI configuration
 func getRpkiConfig(config *ini.File) (RpkiConfig, error) {
 	var rpki RpkiConfig
@@ -474,7 +733,9 @@ func getThemeConfig(config *ini.File) ThemeConfig {
 func getPaginationConfig(config *ini.File) PaginationConfig {
 	baseConfig := config.Section("pagination")
 
-	paginationConfig := PaginationConfig{}
+	paginationConfig := PaginationConfig{
+		MaxPageSize: 1000,
+	}
 	baseConfig.MapTo(&paginationConfig)
 
 	return paginationConfig
@@ -557,6 +818,35 @@ func getUiConfig(config *ini.File) (UiConfig, error) {
 	return uiConfig, nil
 }
 
+// Check the raw configuration for source sections
+// defined more than once. The parser would merge them
+// into a single source.
+func checkDuplicateSourceIds(data []byte) error {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		end := strings.Index(line, "]")
+		if end < 0 {
+			continue
+		}
+		name := strings.TrimSpace(line[1:end])
+		if !strings.HasPrefix(name, "source.") ||
+			strings.Count(name, ".") != 1 {
+			continue
+		}
+
+		sourceId := name[len("source."):]
+		if seen[sourceId] {
+			return fmt.Errorf("duplicate source id: %s", sourceId)
+		}
+		seen[sourceId] = true
+	}
+	return nil
+}
+
 func getSources(config *ini.File) ([]*SourceConfig, error) {
 	sources := []*SourceConfig{}
 
@@ -596,14 +886,77 @@ func getSources(config *ini.File) ([]*SourceConfig, error) {
 		sourceGroup := section.Key("group").MustString("")
 		sourceBlackholes := TrimmedStringList(
 			section.Key("blackholes").MustString(""))
+		var sourceBlackholeCommunities api.Communities
+		if section.HasKey("blackhole_communities") {
+			communities, err := parseCommunitiesList(
+				section.Key("blackhole_communities").MustString(""))
+			if err != nil {
+				return sources, fmt.Errorf("%s: %s", section.Name(), err)
+			}
+			sourceBlackholeCommunities = communities
+		}
+		sourceHiddenNeighbours := TrimmedStringList(
+			section.Key("hidden_neighbours").MustString(""))
+		sourceRoutesSort := section.Key("routes_sort").MustString("")
+		if _, err := parseRouteSortKeys(sourceRoutesSort); err != nil {
+			return sources, fmt.Errorf("%s: %s", section.Name(), err)
+		}
+		sourceNeighboursSort := section.Key("neighbours_sort").MustString("")
+		if _, err := parseNeighbourSortKeys(sourceNeighboursSort); err != nil {
+			return sources, fmt.Errorf("%s: %s", section.Name(), err)
+		}
+		sourceLookupMode := section.Key("lookup_mode").In(
+			LOOKUP_MODE_STORE,
+			[]string{LOOKUP_MODE_STORE, LOOKUP_MODE_LIVE})
+		sourceRouteTabs, err := parseRouteTabs(
+			section.Key("route_tabs").MustString(""))
+		if err != nil {
+			return sources, fmt.Errorf("%s: %s", section.Name(), err)
+		}
+		sourceStartupRetries := section.Key("startup_retries").MustInt(0)
+		sourceStartupRetryDelay := time.Duration(
+			section.Key("startup_retry_delay").MustInt(5)) * time.Second
+		sourceNeighboursStoreRefreshInterval := time.Duration(
+			section.Key("neighbours_store_refresh_interval").MustInt(0)) *
+			time.Minute
+		sourceStaleGracePeriod := time.Duration(
+			section.Key("stale_grace_period").MustInt(0)) * time.Minute
+		sourceMaintenanceWindow, err := parseMaintenanceWindow(
+			section.Key("maintenance_window").MustString(""),
+			section.Key("maintenance_timezone").MustString("UTC"))
+		if err != nil {
+			return sources, fmt.Errorf("%s: %s", section.Name(), err)
+		}
+		sourceMinRoutes := section.Key("min_routes").MustInt(0)
+		sourceMinRoutesKeepPrevious := section.Key(
+			"min_routes_keep_previous").MustBool(false)
+		sourceStripPrivateAsns := section.Key(
+			"strip_private_asns").MustBool(false)
+		sourceStripPrivateAsnsKeepOrigin := section.Key(
+			"strip_private_asns_keep_origin").MustBool(false)
 
 		config := &SourceConfig{
-			Id:         sourceId,
-			Order:      order,
-			Name:       sourceName,
-			Group:      sourceGroup,
-			Blackholes: sourceBlackholes,
-			Type:       backendType,
+			Id:                             sourceId,
+			Order:                          order,
+			Name:                           sourceName,
+			Group:                          sourceGroup,
+			Blackholes:                     sourceBlackholes,
+			BlackholeCommunities:           sourceBlackholeCommunities,
+			HiddenNeighbours:               sourceHiddenNeighbours,
+			RoutesSort:                     sourceRoutesSort,
+			NeighboursSort:                 sourceNeighboursSort,
+			LookupMode:                     sourceLookupMode,
+			RouteTabs:                      sourceRouteTabs,
+			StartupRetries:                 sourceStartupRetries,
+			StartupRetryDelay:              sourceStartupRetryDelay,
+			NeighboursStoreRefreshInterval: sourceNeighboursStoreRefreshInterval,
+			StaleGracePeriod:               sourceStaleGracePeriod,
+			MaintenanceWindow:              sourceMaintenanceWindow,
+			MinRoutes:                      sourceMinRoutes,
+			MinRoutesKeepPrevious:          sourceMinRoutesKeepPrevious,
+			StripPrivateAsns:               sourceStripPrivateAsns,
+			StripPrivateAsnsKeepOrigin:     sourceStripPrivateAsnsKeepOrigin,
+			Type:                           backendType,
 		}
 
 		// Set backend
@@ -615,7 +968,14 @@ func getSources(config *ini.File) ([]*SourceConfig, error) {
 
 			if sourceType != "single_table" &&
 				sourceType != "multi_table" {
-				log.Fatal("Configuration error (birdwatcher source) unknown birdwatcher type:", sourceType)
+				// Disable the source, but keep the others running
+				log.Println(
+					"Configuration error (birdwatcher source)",
+					section.Name(),
+					"- unknown birdwatcher type:", sourceType,
+					"- valid types are: single_table, multi_table",
+					"- SOURCE DISABLED")
+				continue
 			}
 
 			log.Println("Adding birdwatcher source of type", sourceType,
@@ -672,51 +1032,191 @@ func loadConfig(file string) (*Config, error) {
 		return nil, err
 	}
 
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sections with the same name are merged when parsing,
+	// so duplicate sources must be detected beforehand.
+	if err := checkDuplicateSourceIds(data); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+
 	// Load configuration, but handle bgp communities section
 	// with our own parser
 	parsedConfig, err := ini.LoadSources(ini.LoadOptions{
 		UnparseableSections: []string{
 			"bgp_communities",
 			"rejection_reasons",
+			"rejection_reasons_details",
 			"noexport_reasons",
 		},
-	}, file)
+	}, data)
 	if err != nil {
 		return nil, err
 	}
+	expandConfigEnv(parsedConfig)
 
 	// Map sections
-	server := ServerConfig{}
+	server := ServerConfig{
+		RequestQueueTimeout: 10,
+		NeighbourUpStates:   []string{"up"},
+		MaxRequestBodySize:  1 << 20,
+	}
 	parsedConfig.Section("server").MapTo(&server)
-
-	housekeeping := HousekeepingConfig{}
+	server.AnonymizeClientIps = parsedConfig.Section("server").Key(
+		"anonymize_client_ips").In(
+		ANONYMIZE_CLIENT_IPS_NONE,
+		[]string{
+			ANONYMIZE_CLIENT_IPS_NONE,
+			ANONYMIZE_CLIENT_IPS_TRUNCATE,
+			ANONYMIZE_CLIENT_IPS_HASH,
+		})
+	// An unsalted hash of an address is easily reversed
+	if server.AnonymizeClientIps == ANONYMIZE_CLIENT_IPS_HASH &&
+		server.ClientIpHashSalt == "" {
+		return nil, fmt.Errorf(
+			"anonymize_client_ips = hash requires a client_ip_hash_salt")
+	}
+	server.AsnNotation = parsedConfig.Section("server").Key(
+		"asn_notation").In(
+		api.ASN_NOTATION_ASPLAIN,
+		[]string{
+			api.ASN_NOTATION_ASPLAIN,
+			api.ASN_NOTATION_ASDOT,
+		})
+	server.UptimeFormat = parsedConfig.Section("server").Key(
+		"uptime_format").In(
+		api.UPTIME_FORMAT_NANOSECONDS,
+		[]string{
+			api.UPTIME_FORMAT_NANOSECONDS,
+			api.UPTIME_FORMAT_SECONDS,
+			api.UPTIME_FORMAT_HUMAN,
+		})
+	server.CommunitiesFormat = parsedConfig.Section("server").Key(
+		"communities_format").In(
+		api.COMMUNITIES_FORMAT_ARRAY,
+		[]string{
+			api.COMMUNITIES_FORMAT_ARRAY,
+			api.COMMUNITIES_FORMAT_STRING,
+			api.COMMUNITIES_FORMAT_BOTH,
+		})
+	server.LookupSourcesOrder = parsedConfig.Section("server").Key(
+		"lookup_sources_order").In(
+		LOOKUP_SOURCES_ORDER_CONFIG,
+		[]string{
+			LOOKUP_SOURCES_ORDER_CONFIG,
+			LOOKUP_SOURCES_ORDER_ROUTES,
+		})
+	server.ResponseHeaders = getResponseHeaders(parsedConfig)
+
+	housekeeping := HousekeepingConfig{
+		ExpireCaches: true,
+	}
 	parsedConfig.Section("housekeeping").MapTo(&housekeeping)
 
+	blackholes, err := getBlackholesConfig(parsedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	hiddenRoutes, err := getHiddenRoutesConfig(parsedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	communityGroups, err := getCommunityGroupsConfig(parsedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	featuredRoutes, err := getFeaturedRoutesConfig(parsedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := getAggregatesConfig(parsedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	bogons, err := getBogonsConfig(parsedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	export := getExportConfig(parsedConfig)
+	// The export reads from the routes store, which
+	// is only populated with the prefix lookup enabled.
+	if export.Enabled && !server.EnablePrefixLookup {
+		return nil, fmt.Errorf(
+			"[export] requires enable_prefix_lookup in [server]")
+	}
+
 	// Get all sources
 	sources, err := getSources(parsedConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	// Load additional sources from the sources directory
+	sourcesDir := resolveConfigPath(server.SourcesDir, file)
+	if sourcesDir != "" {
+		sources, err = loadSourcesDir(sourcesDir, sources)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Load additional sources from included files
+	sources, err = loadSourcesGlobs(
+		getSourcesIncludes(parsedConfig, file), sources)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcesHash, err := hashSourcesConfig(sources)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get UI configurations
 	ui, err := getUiConfig(parsedConfig)
 	if err != nil {
 		return nil, err
 	}
+	checkUiColumns(ui)
 
 	config := &Config{
-		Server:       server,
-		Housekeeping: housekeeping,
-		Ui:           ui,
-		Sources:      sources,
-		File:         file,
+		Server:          server,
+		Housekeeping:    housekeeping,
+		Blackholes:      blackholes,
+		HiddenRoutes:    hiddenRoutes,
+		FeaturedRoutes:  featuredRoutes,
+		Aggregates:      aggregates,
+		Bogons:          bogons,
+		CommunityGroups: communityGroups,
+		Export:          export,
+		ResponseCache:   getResponseCacheConfig(parsedConfig),
+		Ui:              ui,
+		Sources:         sources,
+		SourcesHash:     sourcesHash,
+		File:            file,
 	}
 
 	return config, nil
 }
 
+// Source instances are created lazily and closed
+// at shutdown, both guarded by this lock
+var sourceInstancesLock sync.Mutex
+
 // Get source instance from config
 func (self *SourceConfig) getInstance() sources.Source {
+	sourceInstancesLock.Lock()
+	defer sourceInstancesLock.Unlock()
+
 	if self.instance != nil {
 		return self.instance
 	}
@@ -733,21 +1233,108 @@ func (self *SourceConfig) getInstance() sources.Source {
 	return instance
 }
 
-// Get configuration file with fallbacks
-func getConfigFile(filename string) (string, error) {
-	// Check if requested file is present
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		// Fall back to local filename
-		filename = ".." + filename
+// Close the source instance, if the source holds
+// resources like a connection. A new instance is
+// created when the source is used again.
+func (self *SourceConfig) closeInstance() error {
+	sourceInstancesLock.Lock()
+	defer sourceInstancesLock.Unlock()
+
+	instance := self.instance
+	self.instance = nil
+
+	if closer, ok := instance.(io.Closer); ok {
+		return closer.Close()
 	}
+	return nil
+}
 
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		filename = strings.Replace(filename, ".conf", ".local.conf", 1)
+// Close the instances of all sources
+func (self *Config) CloseSources() {
+	for _, source := range self.Sources {
+		if err := source.closeInstance(); err != nil {
+			log.Println("Closing source", source.Id, "failed:", err)
+		}
 	}
+}
 
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		return "not_found", fmt.Errorf("could not find any configuration file")
+// Get the name of the source backend type
+func (self *SourceConfig) getTypeName() string {
+	switch self.Type {
+	case SOURCE_BIRDWATCHER:
+		return "birdwatcher"
+	case SOURCE_GOBGP:
+		return "gobgp"
 	}
+	return "unknown"
+}
 
-	return filename, nil
+// Get the lookup mode, default is the store
+func (self *SourceConfig) getLookupMode() string {
+	if self.LookupMode == LOOKUP_MODE_LIVE {
+		return LOOKUP_MODE_LIVE
+	}
+	return LOOKUP_MODE_STORE
+}
+
+// Get the effective time config of the source,
+// if provided by the backend.
+func (self *SourceConfig) getTimeConfig() *api.TimeConfig {
+	switch self.Type {
+	case SOURCE_BIRDWATCHER:
+		return &api.TimeConfig{
+			Timezone:        self.Birdwatcher.Timezone,
+			ServerTime:      self.Birdwatcher.ServerTime,
+			ServerTimeShort: self.Birdwatcher.ServerTimeShort,
+			ServerTimeExt:   self.Birdwatcher.ServerTimeExt,
+		}
+	}
+	return nil
+}
+
+// Get the candidates for a configuration file, in the
+// order they are tried: The file itself, the file relative
+// to the parent directory (e.g. ../etc/alice-lg/alice.conf
+// when running from the backend directory) and the
+// .local.conf variant of that.
+func configFileCandidates(filename string) []string {
+	candidates := []string{filename}
+
+	local := filename
+	if filepath.IsAbs(filename) {
+		local = ".." + filename
+		candidates = append(candidates, local)
+	}
+
+	if strings.HasSuffix(local, ".conf") {
+		candidates = append(candidates,
+			strings.TrimSuffix(local, ".conf")+".local.conf")
+	}
+
+	return candidates
+}
+
+// Find the first existing file of the candidates
+func findConfigFile(
+	candidates []string,
+	exists func(string) bool,
+) (string, error) {
+	for _, filename := range candidates {
+		if exists(filename) {
+			return filename, nil
+		}
+	}
+	return "not_found", fmt.Errorf(
+		"could not find any configuration file, tried: %s",
+		strings.Join(candidates, ", "))
+}
+
+func configFileExists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
+
+// Get configuration file with fallbacks
+func getConfigFile(filename string) (string, error) {
+	return findConfigFile(configFileCandidates(filename), configFileExists)
 }
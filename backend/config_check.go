@@ -0,0 +1,77 @@
+package main
+
+/*
+Check the configuration without starting the server.
+
+All problems found are reported, so they can be fixed
+at once, e.g. in a CI pipeline before a deployment.
+*/
+
+import (
+	"fmt"
+	"log"
+)
+
+// Check the backend configuration of a source
+func (self *SourceConfig) Verify() error {
+	switch self.Type {
+	case SOURCE_BIRDWATCHER:
+		return self.Birdwatcher.Verify()
+	case SOURCE_GOBGP:
+		return self.GoBGP.Verify()
+	}
+	return fmt.Errorf("no backend configured")
+}
+
+// Check the sources for invalid backends, duplicate
+// ids and overlapping orders.
+func checkConfig(config *Config) []error {
+	problems := []error{}
+
+	ids := make(map[string]bool)
+	orders := make(map[int]string)
+	for _, source := range config.Sources {
+		if err := source.Verify(); err != nil {
+			problems = append(problems, fmt.Errorf(
+				"source %s: %s", source.Id, err))
+		}
+
+		if ids[source.Id] {
+			problems = append(problems, fmt.Errorf(
+				"source %s: duplicate source id", source.Id))
+		}
+		ids[source.Id] = true
+
+		if other, ok := orders[source.Order]; ok {
+			problems = append(problems, fmt.Errorf(
+				"source %s: order %d overlaps with source %s",
+				source.Id, source.Order, other))
+		} else {
+			orders[source.Order] = source.Id
+		}
+	}
+
+	return problems
+}
+
+// Load and check the configuration, report all problems
+// and return the exit code.
+func runConfigCheck(file string) int {
+	config, err := loadConfig(file)
+	if err != nil {
+		log.Println("Configuration error:", err)
+		return 1
+	}
+
+	problems := checkConfig(config)
+	for _, problem := range problems {
+		log.Println("Configuration error:", problem)
+	}
+	if len(problems) > 0 {
+		log.Println("Found", len(problems), "problem(s) in", config.File)
+		return 1
+	}
+
+	log.Println("Configuration OK:", config.File)
+	return 0
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/sources/birdwatcher"
+	"github.com/alice-lg/alice-lg/backend/sources/gobgp"
+)
+
+func TestCheckConfig(t *testing.T) {
+	config := &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:    "rs1",
+				Order: 0,
+				Type:  SOURCE_BIRDWATCHER,
+				Birdwatcher: birdwatcher.Config{
+					Api:  "http://rs1.example.net:29184/",
+					Type: "multi_table",
+				},
+			},
+			&SourceConfig{
+				Id:    "rs2",
+				Order: 1,
+				Type:  SOURCE_GOBGP,
+				GoBGP: gobgp.Config{
+					Host:     "rs2.example.net:50051",
+					Insecure: true,
+				},
+			},
+		},
+	}
+	if problems := checkConfig(config); len(problems) != 0 {
+		t.Error("Expected no problems, got:", problems)
+	}
+
+	// Add a duplicate source without a backend and
+	// an overlapping order
+	config.Sources = append(config.Sources, &SourceConfig{
+		Id:    "rs1",
+		Order: 1,
+	})
+	problems := checkConfig(config)
+	if len(problems) != 3 {
+		t.Fatal("Expected 3 problems, got:", problems)
+	}
+
+	expected := []string{
+		"source rs1: no backend configured",
+		"source rs1: duplicate source id",
+		"source rs1: order 1 overlaps with source rs2",
+	}
+	for i, message := range expected {
+		if problems[i].Error() != message {
+			t.Error("Expected:", message, "got:", problems[i])
+		}
+	}
+}
+
+func TestSourceConfigVerify(t *testing.T) {
+	source := &SourceConfig{
+		Id:   "rs1",
+		Type: SOURCE_BIRDWATCHER,
+		Birdwatcher: birdwatcher.Config{
+			Type: "multi_table",
+		},
+	}
+	err := source.Verify()
+	if err == nil || !strings.Contains(err.Error(), "api") {
+		t.Error("Expected missing api to be reported, got:", err)
+	}
+
+	source = &SourceConfig{
+		Id:    "rs2",
+		Type:  SOURCE_GOBGP,
+		GoBGP: gobgp.Config{Host: "rs2.example.net:50051"},
+	}
+	err = source.Verify()
+	if err == nil || !strings.Contains(err.Error(), "tls_crt") {
+		t.Error("Expected missing tls cert to be reported, got:", err)
+	}
+}
+
+func TestRunConfigCheck(t *testing.T) {
+	if code := runConfigCheck("../etc/alice-lg/alice.example.conf"); code != 0 {
+		t.Error("Expected example config to pass the check, got:", code)
+	}
+	if code := runConfigCheck("does-not-exist.conf"); code == 0 {
+		t.Error("Expected missing config to fail the check")
+	}
+}
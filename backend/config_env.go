@@ -0,0 +1,38 @@
+package main
+
+/*
+Expand environment variables in the configuration.
+
+Values may reference variables as $VAR or ${VAR},
+unset variables expand to an empty string.
+A literal $ is written as $$.
+*/
+
+import (
+	"os"
+
+	"github.com/go-ini/ini"
+)
+
+// Look up a variable, $$ is expanded to $
+func expandConfigVar(name string) string {
+	if name == "$" {
+		return "$"
+	}
+	return os.Getenv(name)
+}
+
+// Expand environment variables in a single value
+func expandConfigValue(value string) string {
+	return os.Expand(value, expandConfigVar)
+}
+
+// Expand environment variables in all values of
+// the parsed configuration
+func expandConfigEnv(parsed *ini.File) {
+	for _, section := range parsed.Sections() {
+		for _, key := range section.Keys() {
+			key.SetValue(expandConfigValue(key.Value()))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestExpandConfigEnv(t *testing.T) {
+	os.Setenv("ALICE_TEST_BIRD_HOST", "bird.example.net")
+	os.Setenv("ALICE_TEST_LISTEN", "0.0.0.0:7340")
+	os.Unsetenv("ALICE_TEST_MISSING")
+	defer os.Unsetenv("ALICE_TEST_BIRD_HOST")
+	defer os.Unsetenv("ALICE_TEST_LISTEN")
+
+	parsed, err := ini.Load([]byte(`
+[server]
+listen_http = $ALICE_TEST_LISTEN
+message = costs $$5${ALICE_TEST_MISSING}
+
+[source.rs1]
+name = rs1 ($ALICE_TEST_MISSING)
+
+[source.rs1.birdwatcher]
+api = http://${ALICE_TEST_BIRD_HOST}:29184/
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expandConfigEnv(parsed)
+
+	expected := []struct {
+		section string
+		key     string
+		value   string
+	}{
+		{"server", "listen_http", "0.0.0.0:7340"},
+		{"server", "message", "costs $5"},
+		{"source.rs1", "name", "rs1 ()"},
+		{"source.rs1.birdwatcher", "api", "http://bird.example.net:29184/"},
+	}
+	for _, e := range expected {
+		value := parsed.Section(e.section).Key(e.key).String()
+		if value != e.value {
+			t.Error("Expected", e.section, e.key, "to be", e.value,
+				"got:", value)
+		}
+	}
+}
+
+func TestLoadConfigExpandsEnv(t *testing.T) {
+	config, err := loadConfig("../etc/alice-lg/alice.example.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Communities are not expanded
+	label, err := config.Ui.BgpCommunities.Lookup("0:1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label != "do not redistribute to AS$1" {
+		t.Error("Unexpected community label:", label)
+	}
+}
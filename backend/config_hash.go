@@ -0,0 +1,27 @@
+package main
+
+/*
+Hash of the effective source configuration.
+
+Alice instances serving from the same source config
+report the same hash. The hash is computed when the
+config is loaded, so it changes with a reload of a
+changed config.
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+const CONFIG_HASH_HEADER = "X-Alice-Config-Hash"
+
+// Compute the hash of the source configs
+func hashSourcesConfig(sources []*SourceConfig) (string, error) {
+	payload, err := json.Marshal(sources)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(payload)), nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+func writeTestHashConfig(t *testing.T, file, api string) {
+	content := []byte(
+		"[server]\n" +
+			"include_config_hash = true\n" +
+			"[source.rs1]\n" +
+			"name = rs1.example.com\n" +
+			"[source.rs1.birdwatcher]\n" +
+			"api = " + api + "\n" +
+			"type = multi_table\n")
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSourcesHashReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alice-config-hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "alice.conf")
+
+	writeTestHashConfig(t, file, "http://rs1.example.com:29184/")
+	config, err := loadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := config.SourcesHash
+	if hash == "" {
+		t.Fatal("expected a config hash")
+	}
+
+	// Reloading the same config yields the same hash
+	config, err = loadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.SourcesHash != hash {
+		t.Error("expected stable hash, got:", config.SourcesHash, hash)
+	}
+
+	// A changed source config changes the hash
+	writeTestHashConfig(t, file, "http://rs2.example.com:29184/")
+	config, err = loadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.SourcesHash == hash {
+		t.Error("expected hash to change after reload")
+	}
+}
+
+func TestConfigHashHeader(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Server:      ServerConfig{IncludeConfigHash: true},
+		SourcesHash: "c0ffee",
+	}
+	handler := func(
+		req *http.Request, _ httprouter.Params,
+	) (api.Response, error) {
+		return &api.StatusResponse{}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/api/v1/status", nil)
+		res := httptest.NewRecorder()
+		endpoint(handler)(res, req, nil)
+		if res.Header().Get(CONFIG_HASH_HEADER) != "c0ffee" {
+			t.Error("unexpected config hash header:",
+				res.Header().Get(CONFIG_HASH_HEADER))
+		}
+	}
+
+	// The stores are not required for the status
+	AliceRoutesStore = nil
+
+	status, _ := NewAppStatus()
+	if status.ConfigHash != "c0ffee" {
+		t.Error("expected config hash in status, got:", status.ConfigHash)
+	}
+
+	// Disabled
+	AliceConfig.Server.IncludeConfigHash = false
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	res := httptest.NewRecorder()
+	endpoint(handler)(res, req, nil)
+	if res.Header().Get(CONFIG_HASH_HEADER) != "" {
+		t.Error("expected no config hash header")
+	}
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-ini/ini"
 )
 
 // Test configuration loading and parsing
@@ -96,6 +103,46 @@ func TestRejectAndNoexportReasons(t *testing.T) {
 	}
 }
 
+func TestRejectReasonsDetails(t *testing.T) {
+	config, err := loadConfig("../etc/alice-lg/alice.example.conf")
+	if err != nil {
+		t.Error("Could not load test config:", err)
+	}
+
+	rejections := config.Ui.RoutesRejections
+
+	// Reason with extended description
+	reason, err := rejections.Reasons.Lookup("9033:65666:3")
+	if err != nil {
+		t.Error(err)
+	}
+	if reason != "Prefix is longer than 24" {
+		t.Error("Unexpected reason for 9033:65666:3 -", reason)
+	}
+
+	details, err := rejections.Details.Lookup("9033:65666:3")
+	if err != nil {
+		t.Error(err)
+	}
+	if details != "Prefix is too specific. Aggregate your announcement to a /24 or shorter." {
+		t.Error("Unexpected details for 9033:65666:3 -", details)
+	}
+
+	// Short label only
+	reason, err = rejections.Reasons.Lookup("9033:65666:1")
+	if err != nil {
+		t.Error(err)
+	}
+	if reason != "An IP Bogon was detected" {
+		t.Error("Unexpected reason for 9033:65666:1 -", reason)
+	}
+
+	_, err = rejections.Details.Lookup("9033:65666:1")
+	if err == nil {
+		t.Error("Expected no details for 9033:65666:1")
+	}
+}
+
 func TestBlackholeParsing(t *testing.T) {
 	config, err := loadConfig("../etc/alice-lg/alice.example.conf")
 	if err != nil {
@@ -174,3 +221,373 @@ func TestRejectCandidatesConfig(t *testing.T) {
 		t.Error("expected 23:42:46 to be a 'reject-candidate'")
 	}
 }
+
+func TestBlackholesConfig(t *testing.T) {
+	config, err := loadConfig("../etc/alice-lg/alice.example.conf")
+	if err != nil {
+		t.Error("Could not load test config:", err)
+		return
+	}
+
+	communities := config.Blackholes.Communities
+	if len(communities) != 2 {
+		t.Error("Expected 2 blackhole communities, got:", communities)
+		return
+	}
+
+	if communities[0].String() != "65535:666" {
+		t.Error("Unexpected blackhole community:", communities[0])
+	}
+	if communities[1].String() != "9033:666:0" {
+		t.Error("Unexpected blackhole community:", communities[1])
+	}
+}
+
+func TestSourceBlackholeCommunities(t *testing.T) {
+	config, err := ini.Load([]byte(`
+[source.rs1]
+name = rs1.example.net
+blackhole_communities = 9033:666:1
+[source.rs1.birdwatcher]
+api = http://localhost:29184
+type = multi_table
+
+[source.rs2]
+name = rs2.example.net
+[source.rs2.birdwatcher]
+api = http://localhost:29185
+type = multi_table
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := getSources(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	communities := sources[0].BlackholeCommunities
+	if len(communities) != 1 || communities[0].String() != "9033:666:1" {
+		t.Error("Unexpected blackhole communities:", communities)
+	}
+	if sources[1].BlackholeCommunities != nil {
+		t.Error("Expected no blackhole communities override")
+	}
+}
+
+func TestInvalidBirdwatcherType(t *testing.T) {
+	config, err := ini.Load([]byte(`
+[source.rs1]
+name = rs1.example.net
+
+[source.rs1.birdwatcher]
+api = http://rs1.example.net:29184/
+type = multi_tabel
+
+[source.rs2]
+name = rs2.example.net
+
+[source.rs2.birdwatcher]
+api = http://rs2.example.net:29184/
+type = single_table
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := getSources(config)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+
+	// The source with the invalid type is disabled
+	if len(sources) != 1 {
+		t.Fatal("Expected 1 source, got:", len(sources))
+	}
+	if sources[0].Id != "rs2" {
+		t.Error("Expected rs2 to be configured, got:", sources[0].Id)
+	}
+	if sources[0].Order != 0 {
+		t.Error("Unexpected source order:", sources[0].Order)
+	}
+}
+
+func TestSourceStartupRetriesConfig(t *testing.T) {
+	config, err := loadConfig("../etc/alice-lg/alice.example.conf")
+	if err != nil {
+		t.Fatal("Could not load test config:", err)
+	}
+
+	source := config.Sources[0]
+	if source.StartupRetries != 0 {
+		t.Error("Expected no startup retries, got:", source.StartupRetries)
+	}
+	if source.StartupRetryDelay != 5*time.Second {
+		t.Error("Unexpected startup retry delay:", source.StartupRetryDelay)
+	}
+}
+
+func TestSourceNeighboursStoreRefreshInterval(t *testing.T) {
+	config, err := ini.Load([]byte(`
+[source.rs1]
+name = rs1.example.net
+neighbours_store_refresh_interval = 30
+
+[source.rs1.birdwatcher]
+api = http://rs1.example.net:29184/
+type = multi_table
+
+[source.rs2]
+name = rs2.example.net
+
+[source.rs2.birdwatcher]
+api = http://rs2.example.net:29184/
+type = multi_table
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := getSources(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sources[0].NeighboursStoreRefreshInterval != 30*time.Minute {
+		t.Error("Unexpected refresh interval:",
+			sources[0].NeighboursStoreRefreshInterval)
+	}
+	if sources[1].NeighboursStoreRefreshInterval != 0 {
+		t.Error("Expected rs2 to inherit the refresh interval, got:",
+			sources[1].NeighboursStoreRefreshInterval)
+	}
+
+	// The store falls back to the server-wide setting
+	store := NewNeighboursStore(&Config{
+		Server:  ServerConfig{NeighboursStoreRefreshInterval: 10},
+		Sources: sources,
+	})
+	if store.SourceRefreshInterval("rs1") != 30*time.Minute {
+		t.Error("Unexpected interval for rs1:",
+			store.SourceRefreshInterval("rs1"))
+	}
+	if store.SourceRefreshInterval("rs2") != 10*time.Minute {
+		t.Error("Unexpected interval for rs2:",
+			store.SourceRefreshInterval("rs2"))
+	}
+	if store.updateInterval() != 10*time.Minute {
+		t.Error("Unexpected update interval:", store.updateInterval())
+	}
+}
+
+func TestCheckDuplicateSourceIds(t *testing.T) {
+	config := []byte(`
+[source.rs1]
+name = rs1.example.net
+
+[source.rs1.birdwatcher]
+api = http://rs1.example.net:29184/
+type = multi_table
+
+[source.rs2]
+name = rs2.example.net
+
+[ source.rs1 ] ; again
+name = rs1.example.net (again)
+`)
+	err := checkDuplicateSourceIds(config)
+	if err == nil {
+		t.Fatal("Expected duplicate source id to be rejected")
+	}
+	if err.Error() != "duplicate source id: rs1" {
+		t.Error("Unexpected error:", err)
+	}
+
+	// Distinct sources with backends are accepted
+	config = []byte(`
+[source.rs1]
+[source.rs1.birdwatcher]
+[source.rs2]
+[source.rs2.gobgp]
+`)
+	if err := checkDuplicateSourceIds(config); err != nil {
+		t.Error(err)
+	}
+}
+
+// Save the global config and stores. The returned
+// function restores them, when a test replaced them:
+//
+//	defer saveTestGlobals()()
+func saveTestGlobals() func() {
+	config := AliceConfig
+	routesStore := AliceRoutesStore
+	neighboursStore := AliceNeighboursStore
+
+	return func() {
+		AliceConfig = config
+		AliceRoutesStore = routesStore
+		AliceNeighboursStore = neighboursStore
+	}
+}
+
+// Write a configuration to a temporary file
+func writeTestConfig(t *testing.T, config string) string {
+	file, err := ioutil.TempFile("", "alice-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(config); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
+func TestLoadConfigDuplicateSourceId(t *testing.T) {
+	filename := writeTestConfig(t, `
+[server]
+listen_http = 127.0.0.1:7340
+
+[source.rs1]
+name = rs1.example.net
+[source.rs1.birdwatcher]
+api = http://rs1.example.net:29184/
+type = multi_table
+
+[source.rs1]
+name = rs1.example.net (again)
+`)
+	defer os.Remove(filename)
+
+	_, err := loadConfig(filename)
+	if err == nil {
+		t.Fatal("Expected duplicate source id to be rejected")
+	}
+	if !strings.Contains(err.Error(), "duplicate source id: rs1") {
+		t.Error("Unexpected error:", err)
+	}
+
+	// The configuration check fails as well
+	if code := runConfigCheck(filename); code == 0 {
+		t.Error("Expected duplicate source id to fail the check")
+	}
+}
+
+func TestLoadConfigClientIpHashRequiresSalt(t *testing.T) {
+	config := `
+[server]
+listen_http = 127.0.0.1:7340
+anonymize_client_ips = hash
+%s
+
+[source.rs1]
+name = rs1.example.net
+[source.rs1.birdwatcher]
+api = http://rs1.example.net:29184/
+type = multi_table
+`
+	filename := writeTestConfig(t, fmt.Sprintf(config, ""))
+	defer os.Remove(filename)
+	_, err := loadConfig(filename)
+	if err == nil || !strings.Contains(err.Error(), "client_ip_hash_salt") {
+		t.Error("Expected hash without salt to fail, got:", err)
+	}
+
+	filename = writeTestConfig(t,
+		fmt.Sprintf(config, "client_ip_hash_salt = secret"))
+	defer os.Remove(filename)
+	if _, err := loadConfig(filename); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadConfigExportRequiresPrefixLookup(t *testing.T) {
+	config := `
+[server]
+listen_http = 127.0.0.1:7340
+enable_prefix_lookup = %s
+
+[export]
+enabled = true
+directory = /tmp
+
+[source.rs1]
+name = rs1.example.net
+[source.rs1.birdwatcher]
+api = http://rs1.example.net:29184/
+type = multi_table
+`
+	filename := writeTestConfig(t, fmt.Sprintf(config, "false"))
+	defer os.Remove(filename)
+	_, err := loadConfig(filename)
+	if err == nil || !strings.Contains(err.Error(), "enable_prefix_lookup") {
+		t.Error("Expected export without prefix lookup to fail, got:", err)
+	}
+
+	filename = writeTestConfig(t, fmt.Sprintf(config, "true"))
+	defer os.Remove(filename)
+	if _, err := loadConfig(filename); err != nil {
+		t.Error(err)
+	}
+}
+
+func existingFiles(files ...string) func(string) bool {
+	return func(filename string) bool {
+		for _, f := range files {
+			if f == filename {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestConfigFileCandidates(t *testing.T) {
+	expected := map[string][]string{
+		"/etc/alice-lg/alice.conf": []string{
+			"/etc/alice-lg/alice.conf",
+			"../etc/alice-lg/alice.conf",
+			"../etc/alice-lg/alice.local.conf",
+		},
+		"alice.conf": []string{
+			"alice.conf",
+			"alice.local.conf",
+		},
+		"/etc/alice-lg/alice.ini": []string{
+			"/etc/alice-lg/alice.ini",
+			"../etc/alice-lg/alice.ini",
+		},
+	}
+	for filename, candidates := range expected {
+		result := configFileCandidates(filename)
+		if strings.Join(result, ",") != strings.Join(candidates, ",") {
+			t.Error("unexpected candidates for", filename, ":", result)
+		}
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	candidates := configFileCandidates("/etc/alice-lg/alice.conf")
+
+	// The absolute path is preferred
+	file, err := findConfigFile(candidates, existingFiles(
+		"/etc/alice-lg/alice.conf",
+		"../etc/alice-lg/alice.conf"))
+	if err != nil || file != "/etc/alice-lg/alice.conf" {
+		t.Error("expected absolute path, got:", file, err)
+	}
+
+	// Fall back to the .local.conf
+	file, err = findConfigFile(candidates, existingFiles(
+		"../etc/alice-lg/alice.local.conf"))
+	if err != nil || file != "../etc/alice-lg/alice.local.conf" {
+		t.Error("expected .local.conf fallback, got:", file, err)
+	}
+
+	// Missing file
+	_, err = findConfigFile(candidates, existingFiles())
+	if err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
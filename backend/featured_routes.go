@@ -0,0 +1,48 @@
+package main
+
+/*
+Featured routes carry one of the configured featured
+communities and are listed ahead of all other routes.
+The order within both groups is preserved.
+*/
+
+import (
+	"sort"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Check if the bgp info has any of the featured communities
+func isFeatured(bgp api.BgpInfo, communities api.Communities) bool {
+	for _, c := range communities {
+		if bgp.HasCommunity(c) || bgp.HasLargeCommunity(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Move featured routes to the top
+func sortFeaturedRoutes(routes api.Routes, communities api.Communities) {
+	if len(communities) == 0 {
+		return
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return isFeatured(routes[i].Bgp, communities) &&
+			!isFeatured(routes[j].Bgp, communities)
+	})
+}
+
+// Move featured lookup routes to the top
+func sortFeaturedLookupRoutes(
+	routes api.LookupRoutes,
+	communities api.Communities,
+) {
+	if len(communities) == 0 {
+		return
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return isFeatured(routes[i].Bgp, communities) &&
+			!isFeatured(routes[j].Bgp, communities)
+	})
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestSortFeaturedRoutes(t *testing.T) {
+	featured := api.BgpInfo{
+		LargeCommunities: api.Communities{
+			api.Community{9033, 65535, 2},
+		},
+	}
+	routes := api.Routes{
+		&api.Route{Id: "a", Network: "10.0.0.0/8"},
+		&api.Route{Id: "b", Network: "10.1.0.0/16", Bgp: featured},
+		&api.Route{Id: "c", Network: "10.2.0.0/16"},
+		&api.Route{Id: "d", Network: "10.3.0.0/16", Bgp: featured},
+	}
+
+	communities := api.Communities{
+		api.Community{9033, 65535, 2},
+	}
+	sortFeaturedRoutes(routes, communities)
+
+	order := ""
+	for _, r := range routes {
+		order += r.Id
+	}
+	if order != "bdac" {
+		t.Error("Expected featured routes first in order, got:", order)
+	}
+
+	// Nothing is featured
+	sortFeaturedRoutes(routes, api.Communities{})
+	order = ""
+	for _, r := range routes {
+		order += r.Id
+	}
+	if order != "bdac" {
+		t.Error("Expected order to be unchanged, got:", order)
+	}
+}
+
+func TestSortFeaturedLookupRoutes(t *testing.T) {
+	routes := api.LookupRoutes{
+		&api.LookupRoute{Id: "a"},
+		&api.LookupRoute{Id: "b"},
+		&api.LookupRoute{Id: "c", Bgp: api.BgpInfo{
+			Communities: api.Communities{api.Community{65000, 1}},
+		}},
+	}
+
+	sortFeaturedLookupRoutes(routes, api.Communities{
+		api.Community{65000, 1},
+	})
+	if routes[0].Id != "c" || routes[1].Id != "a" || routes[2].Id != "b" {
+		t.Error("Unexpected order:", routes[0].Id, routes[1].Id, routes[2].Id)
+	}
+}
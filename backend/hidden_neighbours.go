@@ -0,0 +1,104 @@
+package main
+
+/*
+Hidden neighbours
+
+Sessions like internal monitoring peers can be excluded
+per source with a list of neighbour addresses or ASNs:
+
+    hidden_neighbours = 10.23.42.1, AS64512
+
+The neighbours and their routes are removed from all
+responses of the source. Routes are attributed to a
+hidden neighbour by their gateway or the first ASN
+in the AS path.
+*/
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Helper: Check if an address matches any of the
+// hidden neighbours.
+func isHiddenNeighbourAddress(address string, hidden []string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, h := range hidden {
+		if hiddenIp := net.ParseIP(h); hiddenIp != nil && hiddenIp.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper: Check if an ASN matches any of the hidden
+// neighbours. ASNs may be prefixed with AS.
+func isHiddenNeighbourAsn(asn int, hidden []string) bool {
+	for _, h := range hidden {
+		value := strings.TrimPrefix(strings.ToUpper(h), "AS")
+		hiddenAsn, err := strconv.Atoi(value)
+		if err == nil && hiddenAsn == asn {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the neighbour is hidden
+func isHiddenNeighbour(neighbour *api.Neighbour, hidden []string) bool {
+	return isHiddenNeighbourAddress(neighbour.Address, hidden) ||
+		isHiddenNeighbourAsn(neighbour.Asn, hidden)
+}
+
+// Check if the route was received from a hidden neighbour
+func isHiddenNeighbourRoute(route *api.Route, hidden []string) bool {
+	if isHiddenNeighbourAddress(route.Gateway, hidden) {
+		return true
+	}
+	asPath := route.Bgp.AsPath
+	return len(asPath) > 0 && isHiddenNeighbourAsn(asPath[0], hidden)
+}
+
+// Remove all hidden neighbours
+func filterHiddenNeighbours(
+	neighbours api.Neighbours,
+	hidden []string,
+) api.Neighbours {
+	if len(hidden) == 0 {
+		return neighbours
+	}
+
+	results := make(api.Neighbours, 0, len(neighbours))
+	for _, neighbour := range neighbours {
+		if isHiddenNeighbour(neighbour, hidden) {
+			continue
+		}
+		results = append(results, neighbour)
+	}
+	return results
+}
+
+// Remove all routes received from hidden neighbours
+func filterHiddenNeighbourRoutes(
+	routes api.Routes,
+	hidden []string,
+) api.Routes {
+	if len(hidden) == 0 {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, route := range routes {
+		if isHiddenNeighbourRoute(route, hidden) {
+			continue
+		}
+		results = append(results, route)
+	}
+	return results
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestHiddenNeighbours(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	source := &SourceConfig{
+		Id:               "rs1",
+		HiddenNeighbours: []string{"10.23.42.1", "AS64512", "2001:db8::0:1"},
+	}
+
+	response := &api.NeighboursResponse{
+		Neighbours: api.Neighbours{
+			&api.Neighbour{Id: "n1", Address: "10.23.42.1", Asn: 2342},
+			&api.Neighbour{Id: "n2", Address: "10.23.42.2", Asn: 64512},
+			&api.Neighbour{Id: "n3", Address: "10.23.42.3", Asn: 4223},
+			&api.Neighbour{Id: "n4", Address: "2001:db8::1", Asn: 1111},
+		},
+	}
+	annotateNeighboursResponse(source, response)
+
+	if len(response.Neighbours) != 1 || response.Neighbours[0].Id != "n3" {
+		t.Error("Expected only n3, got:", response.Neighbours)
+	}
+}
+
+func TestHiddenNeighbourRoutes(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	source := &SourceConfig{
+		Id:               "rs1",
+		HiddenNeighbours: []string{"10.23.42.1", "64512"},
+	}
+
+	response := &api.RoutesResponse{
+		Imported: api.Routes{
+			&api.Route{Id: "r1", Gateway: "10.23.42.1",
+				Bgp: api.BgpInfo{AsPath: []int{2342}}},
+			&api.Route{Id: "r2", Gateway: "10.23.42.2",
+				Bgp: api.BgpInfo{AsPath: []int{64512, 2342}}},
+			&api.Route{Id: "r3", Gateway: "10.23.42.3",
+				Bgp: api.BgpInfo{AsPath: []int{4223, 64512}}},
+		},
+		Filtered: api.Routes{
+			&api.Route{Id: "r4", Gateway: "10.23.42.1"},
+		},
+	}
+	response = annotateRoutesResponse(source, response)
+
+	if len(response.Imported) != 1 || response.Imported[0].Id != "r3" {
+		t.Error("Expected only r3 to be imported, got:", response.Imported)
+	}
+	if len(response.Filtered) != 0 {
+		t.Error("Expected no filtered routes, got:", response.Filtered)
+	}
+}
+
+func TestHiddenNeighboursDisabled(t *testing.T) {
+	neighbours := api.Neighbours{
+		&api.Neighbour{Id: "n1", Address: "10.23.42.1", Asn: 2342},
+	}
+	if len(filterHiddenNeighbours(neighbours, nil)) != 1 {
+		t.Error("Expected neighbours to be unchanged")
+	}
+}
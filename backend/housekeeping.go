@@ -2,8 +2,8 @@ package main
 
 import (
 	"log"
-	"time"
 	"runtime/debug"
+	"time"
 )
 
 func Housekeeping(config *Config) {
@@ -16,11 +16,11 @@ func Housekeeping(config *Config) {
 
 		log.Println("Housekeeping started")
 
-		// Expire the caches
-		log.Println("Expiring caches")
-		for _, source := range config.Sources {
-			count := source.getInstance().ExpireCaches()
-			log.Println("Expired", count, "entries for source", source.Name)
+		// Expire the caches, unless this is done
+		// on a separate interval
+		if config.Housekeeping.ExpireCaches &&
+			config.Housekeeping.ExpireCachesInterval <= 0 {
+			expireSourcesCaches(config.Sources)
 		}
 
 		if config.Housekeeping.ForceReleaseMemory {
@@ -32,3 +32,51 @@ func Housekeeping(config *Config) {
 	}
 }
 
+// Expire the caches of all sources and
+// report the total number of expired entries
+func expireSourcesCaches(sources []*SourceConfig) int {
+	log.Println("Expiring caches")
+
+	total := 0
+	for _, source := range sources {
+		count := source.getInstance().ExpireCaches()
+		log.Println("Expired", count, "entries for source", source.Name)
+		total += count
+	}
+
+	log.Println("Expired", total, "cache entries in total")
+	return total
+}
+
+// Expire the caches of all sources on every tick,
+// until stopped.
+func expireCachesLoop(
+	sources []*SourceConfig,
+	tick <-chan time.Time,
+	stop chan bool,
+) {
+	for {
+		select {
+		case <-tick:
+			expireSourcesCaches(sources)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Start expiring caches on a separate interval,
+// if configured.
+func StartExpireCaches(config *Config) {
+	if !config.Housekeeping.ExpireCaches ||
+		config.Housekeeping.ExpireCachesInterval <= 0 {
+		return
+	}
+
+	interval := time.Duration(
+		config.Housekeeping.ExpireCachesInterval) * time.Minute
+	log.Println("Expiring source caches every", interval)
+
+	ticker := time.NewTicker(interval)
+	go expireCachesLoop(config.Sources, ticker.C, nil)
+}
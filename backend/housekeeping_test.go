@@ -0,0 +1,116 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// A source counting calls to ExpireCaches
+type expireCountingSource struct {
+	sync.Mutex
+	calls int
+}
+
+func (self *expireCountingSource) ExpireCaches() int {
+	self.Lock()
+	defer self.Unlock()
+	self.calls++
+	return 2
+}
+
+func (self *expireCountingSource) Calls() int {
+	self.Lock()
+	defer self.Unlock()
+	return self.calls
+}
+
+func (self *expireCountingSource) Status() (*api.StatusResponse, error) {
+	return nil, nil
+}
+func (self *expireCountingSource) Neighbours() (*api.NeighboursResponse, error) {
+	return nil, nil
+}
+func (self *expireCountingSource) NeighboursStatus() (*api.NeighboursStatusResponse, error) {
+	return nil, nil
+}
+func (self *expireCountingSource) Routes(_ string) (*api.RoutesResponse, error) {
+	return nil, nil
+}
+func (self *expireCountingSource) RoutesReceived(_ string) (*api.RoutesResponse, error) {
+	return nil, nil
+}
+func (self *expireCountingSource) RoutesFiltered(_ string) (*api.RoutesResponse, error) {
+	return nil, nil
+}
+func (self *expireCountingSource) RoutesNotExported(_ string) (*api.RoutesResponse, error) {
+	return nil, nil
+}
+func (self *expireCountingSource) AllRoutes() (*api.RoutesResponse, error) {
+	return nil, nil
+}
+
+func TestExpireSourcesCaches(t *testing.T) {
+	rs1 := &expireCountingSource{}
+	rs2 := &expireCountingSource{}
+	sources := []*SourceConfig{
+		&SourceConfig{Id: "rs1", Name: "rs1", instance: rs1},
+		&SourceConfig{Id: "rs2", Name: "rs2", instance: rs2},
+	}
+
+	total := expireSourcesCaches(sources)
+	if total != 4 {
+		t.Error("Expected 4 expired entries, got:", total)
+	}
+	if rs1.Calls() != 1 || rs2.Calls() != 1 {
+		t.Error("Expected ExpireCaches to be called on each source")
+	}
+}
+
+func TestExpireCachesLoop(t *testing.T) {
+	rs1 := &expireCountingSource{}
+	rs2 := &expireCountingSource{}
+	sources := []*SourceConfig{
+		&SourceConfig{Id: "rs1", Name: "rs1", instance: rs1},
+		&SourceConfig{Id: "rs2", Name: "rs2", instance: rs2},
+	}
+
+	tick := make(chan time.Time)
+	stop := make(chan bool)
+	go expireCachesLoop(sources, tick, stop)
+
+	// The sends block until the loop is ready, so
+	// all ticks are handled when the loop is stopped.
+	for i := 0; i < 3; i++ {
+		tick <- time.Now()
+	}
+	stop <- true
+
+	for _, rs := range []*expireCountingSource{rs1, rs2} {
+		if rs.Calls() != 3 {
+			t.Error("Expected 3 ExpireCaches calls, got:", rs.Calls())
+		}
+	}
+
+	// The loop is stopped and no longer receives ticks
+	select {
+	case tick <- time.Now():
+		t.Error("Expected the loop to be stopped")
+	default:
+	}
+}
+
+func TestHousekeepingConfigDefaults(t *testing.T) {
+	config, err := loadConfig("../etc/alice-lg/alice.example.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.Housekeeping.ExpireCaches {
+		t.Error("Expected expire caches to be enabled")
+	}
+	if config.Housekeeping.ExpireCachesInterval != 0 {
+		t.Error("Unexpected interval:", config.Housekeeping.ExpireCachesInterval)
+	}
+}
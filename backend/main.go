@@ -3,7 +3,7 @@ package main
 import (
 	"flag"
 	"log"
-	"net/http"
+	"os"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -20,9 +20,18 @@ func main() {
 		"config", "/etc/alice-lg/alice.conf",
 		"Alice looking glass configuration file",
 	)
+	checkConfigFlag := flag.Bool(
+		"check-config", false,
+		"Check the configuration and exit without starting the server",
+	)
 
 	flag.Parse()
 
+	// Only check the configuration
+	if *checkConfigFlag {
+		os.Exit(runConfigCheck(*configFilenameFlag))
+	}
+
 	// Load configuration
 	AliceConfig, err = loadConfig(*configFilenameFlag)
 	if err != nil {
@@ -34,6 +43,9 @@ func main() {
 
 	log.Println("Using configuration:", AliceConfig.File)
 
+	// Close the sources at shutdown
+	HandleShutdown(AliceConfig)
+
 	// Setup local routes store
 	AliceRoutesStore = NewRoutesStore(AliceConfig)
 
@@ -49,6 +61,13 @@ func main() {
 
 	// Start the Housekeeping
 	go Housekeeping(AliceConfig)
+	StartExpireCaches(AliceConfig)
+
+	// Start the scheduled export from the routes store
+	err = StartRoutesExport(AliceConfig, AliceRoutesStore)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Setup request routing
 	router := httprouter.New()
@@ -65,5 +84,6 @@ func main() {
 	}
 
 	// Start http server
-	log.Fatal(http.ListenAndServe(AliceConfig.Server.Listen, router))
+	handler := withResponseHeaders(AliceConfig.Server.ResponseHeaders, router)
+	log.Fatal(listenAndServe(AliceConfig.Server, handler))
 }
@@ -0,0 +1,95 @@
+package main
+
+/*
+Maintenance windows
+
+Refreshing a source is suspended during its daily
+maintenance window, configured as a time range
+like 02:00-04:00. The window may span midnight.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type MaintenanceWindow struct {
+	Start    time.Duration // since midnight
+	End      time.Duration
+	Location *time.Location
+}
+
+// Serialize the maintenance window including the timezone
+func (self *MaintenanceWindow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%s-%s %s",
+		self.Start, self.End, self.Location))
+}
+
+// Parse a time of day (15:04)
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Parse a maintenance window. An empty value
+// is valid and results in no window.
+func parseMaintenanceWindow(
+	value string,
+	timezone string,
+) (*MaintenanceWindow, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	tokens := strings.Split(value, "-")
+	if len(tokens) != 2 {
+		return nil, fmt.Errorf("invalid maintenance window: %s", value)
+	}
+	start, err := parseTimeOfDay(tokens[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window: %s", value)
+	}
+	end, err := parseTimeOfDay(tokens[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window: %s", value)
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MaintenanceWindow{
+		Start:    start,
+		End:      end,
+		Location: location,
+	}, nil
+}
+
+// Check if a point in time is within the window
+func (self *MaintenanceWindow) Contains(t time.Time) bool {
+	t = t.In(self.Location)
+	offset := time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+
+	if self.Start <= self.End {
+		return offset >= self.Start && offset < self.End
+	}
+	// The window spans midnight
+	return offset >= self.Start || offset < self.End
+}
+
+// Check if the source is in maintenance
+func (self *SourceConfig) inMaintenance(t time.Time) bool {
+	if self == nil || self.MaintenanceWindow == nil {
+		return false
+	}
+	return self.MaintenanceWindow.Contains(t)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	window, err := parseMaintenanceWindow("23:30-01:00", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		"2020-01-01T23:29:59Z": false,
+		"2020-01-01T23:30:00Z": true,
+		"2020-01-02T00:42:00Z": true,
+		"2020-01-02T01:00:00Z": false,
+		"2020-01-02T12:00:00Z": false,
+	}
+	for value, inside := range expected {
+		ts, _ := time.Parse(time.RFC3339, value)
+		if window.Contains(ts) != inside {
+			t.Error("Unexpected maintenance state at", value)
+		}
+	}
+
+	// Timezones
+	window, err = parseMaintenanceWindow("02:00-04:00", "Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, _ := time.Parse(time.RFC3339, "2020-01-01T01:30:00Z")
+	if !window.Contains(ts) {
+		t.Error("Expected 02:30 CET to be within the window")
+	}
+
+	// No window
+	window, err = parseMaintenanceWindow("", "UTC")
+	if err != nil || window != nil {
+		t.Error("Expected no window, got:", window, err)
+	}
+
+	for _, value := range []string{"02:00", "02:00-25:00", "foo-bar"} {
+		if _, err := parseMaintenanceWindow(value, "UTC"); err == nil {
+			t.Error("Expected error for invalid window:", value)
+		}
+	}
+}
+
+func TestRoutesStoreMaintenance(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	// The window covers the entire day
+	sourceConfig := &SourceConfig{
+		Id: "rs1",
+		MaintenanceWindow: &MaintenanceWindow{
+			Start:    0,
+			End:      24 * time.Hour,
+			Location: time.UTC,
+		},
+		instance: &liveRoutesSource{
+			routes: loadTestRoutesResponse(),
+		},
+	}
+	store := NewRoutesStore(&Config{
+		Sources: []*SourceConfig{sourceConfig},
+	})
+
+	store.update()
+	status := store.SourceStatus("rs1")
+	if !status.Maintenance || status.State != STATE_INIT {
+		t.Error("Expected source to be suspended, got:",
+			stateToString(status.State))
+	}
+	if len(store.routesMap["rs1"].Imported) != 0 {
+		t.Error("Expected no routes during maintenance")
+	}
+	if !makeRefreshStats(status).Maintenance {
+		t.Error("Expected maintenance in refresh stats")
+	}
+
+	// Outside of the window the source is refreshed
+	sourceConfig.MaintenanceWindow = &MaintenanceWindow{
+		Location: time.UTC,
+	}
+	store.update()
+	status = store.SourceStatus("rs1")
+	if status.Maintenance || status.State != STATE_READY {
+		t.Error("Expected source to be refreshed, got:",
+			stateToString(status.State))
+	}
+	if len(store.routesMap["rs1"].Imported) == 0 {
+		t.Error("Expected routes after maintenance")
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+/*
+MRT export
+
+Export the routes of a source from the routes store
+in MRT TABLE_DUMP_V2 format (RFC6396). A PEER_INDEX_TABLE
+is followed by a RIB record for each prefix.
+*/
+
+import (
+	"io"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+)
+
+// Routes are exported as seen by the neighbour
+type mrtPeer struct {
+	index   uint16
+	address string
+	asn     uint32
+}
+
+// Get the asn of the neighbour, fall back
+// to the first ASN in the path.
+func mrtPeerAsn(sourceId string, route *api.Route) uint32 {
+	if AliceNeighboursStore != nil {
+		neighbour := AliceNeighboursStore.GetNeighbourAt(
+			sourceId, route.NeighbourId)
+		if neighbour != nil && neighbour.Asn > 0 {
+			return uint32(neighbour.Asn)
+		}
+	}
+	if len(route.Bgp.AsPath) > 0 {
+		return uint32(route.Bgp.AsPath[0])
+	}
+	return 0
+}
+
+// Make the MRT prefix from the network
+func mrtPrefix(network string) (bgp.AddrPrefixInterface, mrt.MRTSubTypeTableDumpv2, error) {
+	ip, ipnet, err := net.ParseCIDR(network)
+	if err != nil {
+		return nil, 0, err
+	}
+	length, _ := ipnet.Mask.Size()
+	if ip.To4() != nil {
+		return bgp.NewIPAddrPrefix(uint8(length), ipnet.IP.String()),
+			mrt.RIB_IPV4_UNICAST, nil
+	}
+	return bgp.NewIPv6AddrPrefix(uint8(length), ipnet.IP.String()),
+		mrt.RIB_IPV6_UNICAST, nil
+}
+
+// Encode the BGP attributes of a route
+func mrtPathAttributes(
+	route *api.Route,
+	prefix bgp.AddrPrefixInterface,
+) []bgp.PathAttributeInterface {
+	origin := uint8(bgp.BGP_ORIGIN_ATTR_TYPE_INCOMPLETE)
+	switch route.Bgp.Origin {
+	case "IGP":
+		origin = bgp.BGP_ORIGIN_ATTR_TYPE_IGP
+	case "EGP":
+		origin = bgp.BGP_ORIGIN_ATTR_TYPE_EGP
+	}
+
+	asPath := make([]uint32, 0, len(route.Bgp.AsPath))
+	for _, asn := range route.Bgp.AsPath {
+		asPath = append(asPath, uint32(asn))
+	}
+
+	attrs := []bgp.PathAttributeInterface{
+		bgp.NewPathAttributeOrigin(origin),
+		bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
+			bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, asPath),
+		}),
+	}
+
+	// Next hop
+	nextHop := route.Bgp.NextHop
+	if nextHop == "" {
+		nextHop = route.Gateway
+	}
+	if ip := net.ParseIP(nextHop); ip != nil {
+		if ip.To4() != nil {
+			attrs = append(attrs, bgp.NewPathAttributeNextHop(nextHop))
+		} else {
+			attrs = append(attrs, bgp.NewPathAttributeMpReachNLRI(
+				nextHop, []bgp.AddrPrefixInterface{prefix}))
+		}
+	}
+
+	attrs = append(attrs,
+		bgp.NewPathAttributeMultiExitDisc(uint32(route.Bgp.Med)),
+		bgp.NewPathAttributeLocalPref(uint32(route.Bgp.LocalPref)))
+
+	if len(route.Bgp.Communities) > 0 {
+		communities := make([]uint32, 0, len(route.Bgp.Communities))
+		for _, c := range route.Bgp.Communities {
+			if len(c) != 2 {
+				continue
+			}
+			communities = append(communities, uint32(c[0])<<16|uint32(c[1]))
+		}
+		attrs = append(attrs, bgp.NewPathAttributeCommunities(communities))
+	}
+
+	if len(route.Bgp.LargeCommunities) > 0 {
+		communities := make([]*bgp.LargeCommunity, 0,
+			len(route.Bgp.LargeCommunities))
+		for _, c := range route.Bgp.LargeCommunities {
+			if len(c) != 3 {
+				continue
+			}
+			communities = append(communities, bgp.NewLargeCommunity(
+				uint32(c[0]), uint32(c[1]), uint32(c[2])))
+		}
+		attrs = append(attrs, bgp.NewPathAttributeLargeCommunities(communities))
+	}
+
+	return attrs
+}
+
+// Serialize and write a MRT message
+func writeMrtMessage(
+	w io.Writer,
+	timestamp uint32,
+	subtype mrt.MRTSubTypeTableDumpv2,
+	body mrt.Body,
+) error {
+	msg, err := mrt.NewMRTMessage(timestamp, mrt.TABLE_DUMPv2, subtype, body)
+	if err != nil {
+		return err
+	}
+	data, err := msg.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Write the routes as MRT TABLE_DUMP_V2
+func writeMrtTableDump(
+	w io.Writer,
+	sourceId string,
+	routes api.Routes,
+	now time.Time,
+) error {
+	timestamp := uint32(now.Unix())
+
+	// Collect peers and group routes by prefix
+	peers := make(map[string]*mrtPeer)
+	peersList := []*mrt.Peer{}
+	prefixes := []string{}
+	paths := make(map[string]api.Routes)
+
+	for _, route := range routes {
+		peerId := route.NeighbourId + "@" + route.Gateway
+		if _, ok := peers[peerId]; !ok {
+			peer := &mrtPeer{
+				index:   uint16(len(peersList)),
+				address: route.Gateway,
+				asn:     mrtPeerAsn(sourceId, route),
+			}
+			if net.ParseIP(peer.address) == nil {
+				peer.address = "0.0.0.0" // unknown
+			}
+			peers[peerId] = peer
+			peersList = append(peersList, mrt.NewPeer(
+				"0.0.0.0", peer.address, peer.asn, true))
+		}
+
+		if _, ok := paths[route.Network]; !ok {
+			prefixes = append(prefixes, route.Network)
+		}
+		paths[route.Network] = append(paths[route.Network], route)
+	}
+	sort.Strings(prefixes)
+
+	// Peer index table
+	err := writeMrtMessage(w, timestamp, mrt.PEER_INDEX_TABLE,
+		mrt.NewPeerIndexTable("0.0.0.0", sourceId, peersList))
+	if err != nil {
+		return err
+	}
+
+	// RIB records
+	for seq, network := range prefixes {
+		prefix, subtype, err := mrtPrefix(network)
+		if err != nil {
+			return err
+		}
+
+		entries := make([]*mrt.RibEntry, 0, len(paths[network]))
+		for _, route := range paths[network] {
+			peer := peers[route.NeighbourId+"@"+route.Gateway]
+			originated := uint32(now.Add(-route.Age).Unix())
+			entries = append(entries, mrt.NewRibEntry(
+				peer.index, originated, 0,
+				mrtPathAttributes(route, prefix), false))
+		}
+
+		err = writeMrtMessage(w, timestamp, subtype,
+			mrt.NewRib(uint32(seq), prefix, entries))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+)
+
+func makeMrtTestRoutes() api.Routes {
+	return api.Routes{
+		&api.Route{
+			NeighbourId: "n1",
+			Network:     "10.23.0.0/16",
+			Gateway:     "192.0.2.1",
+			Age:         time.Hour,
+			Bgp: api.BgpInfo{
+				Origin:    "IGP",
+				AsPath:    []int{2342, 23},
+				NextHop:   "192.0.2.1",
+				LocalPref: 100,
+				Communities: api.Communities{
+					api.Community{2342, 42},
+				},
+				LargeCommunities: api.Communities{
+					api.Community{9033, 65666, 1},
+				},
+			},
+		},
+		&api.Route{
+			NeighbourId: "n2",
+			Network:     "10.23.0.0/16",
+			Gateway:     "192.0.2.2",
+			Bgp: api.BgpInfo{
+				Origin:  "IGP",
+				AsPath:  []int{4200000042, 23},
+				NextHop: "192.0.2.2",
+			},
+		},
+		&api.Route{
+			NeighbourId: "n3",
+			Network:     "2001:db8::/32",
+			Gateway:     "2001:db8:ffff::1",
+			Bgp: api.BgpInfo{
+				Origin:  "IGP",
+				AsPath:  []int{2343},
+				NextHop: "2001:db8:ffff::1",
+			},
+		},
+	}
+}
+
+func readMrtMessages(t *testing.T, data []byte) []*mrt.MRTMessage {
+	messages := []*mrt.MRTMessage{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Split(mrt.SplitMrt)
+	for scanner.Scan() {
+		record := scanner.Bytes()
+		header := &mrt.MRTHeader{}
+		if err := header.DecodeFromBytes(record[:mrt.MRT_COMMON_HEADER_LEN]); err != nil {
+			t.Fatal(err)
+		}
+		msg, err := mrt.ParseMRTBody(header, record[mrt.MRT_COMMON_HEADER_LEN:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return messages
+}
+
+func TestWriteMrtTableDump(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceNeighboursStore = nil
+
+	buf := &bytes.Buffer{}
+	err := writeMrtTableDump(buf, "rs1", makeMrtTestRoutes(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := readMrtMessages(t, buf.Bytes())
+	if len(messages) != 3 {
+		t.Fatal("Expected 3 MRT records, got:", len(messages))
+	}
+
+	// Peer index table
+	table, ok := messages[0].Body.(*mrt.PeerIndexTable)
+	if !ok {
+		t.Fatal("Expected peer index table, got:", messages[0].Body)
+	}
+	if table.ViewName != "rs1" {
+		t.Error("Unexpected view name:", table.ViewName)
+	}
+	if len(table.Peers) != 3 {
+		t.Error("Expected 3 peers, got:", len(table.Peers))
+	}
+	if table.Peers[1].AS != 4200000042 {
+		t.Error("Unexpected peer AS:", table.Peers[1].AS)
+	}
+
+	// IPv4 RIB
+	rib, ok := messages[1].Body.(*mrt.Rib)
+	if !ok {
+		t.Fatal("Expected RIB, got:", messages[1].Body)
+	}
+	if rib.Prefix.String() != "10.23.0.0/16" {
+		t.Error("Unexpected prefix:", rib.Prefix)
+	}
+	if len(rib.Entries) != 2 {
+		t.Fatal("Expected 2 RIB entries, got:", len(rib.Entries))
+	}
+
+	hasLargeCommunity := false
+	for _, attr := range rib.Entries[0].PathAttributes {
+		switch a := attr.(type) {
+		case *bgp.PathAttributeNextHop:
+			if a.Value.String() != "192.0.2.1" {
+				t.Error("Unexpected next hop:", a.Value)
+			}
+		case *bgp.PathAttributeLargeCommunities:
+			hasLargeCommunity = len(a.Values) == 1 && a.Values[0].ASN == 9033
+		}
+	}
+	if !hasLargeCommunity {
+		t.Error("Expected large community in RIB entry")
+	}
+
+	// IPv6 RIB
+	rib, ok = messages[2].Body.(*mrt.Rib)
+	if !ok {
+		t.Fatal("Expected RIB, got:", messages[2].Body)
+	}
+	if rib.Prefix.String() != "2001:db8::/32" {
+		t.Error("Unexpected prefix:", rib.Prefix)
+	}
+	if messages[2].Header.SubType != uint16(mrt.RIB_IPV6_UNICAST) {
+		t.Error("Unexpected subtype:", messages[2].Header.SubType)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+/*
+Neighbours annotations
+
+Neighbours retrieved from a source are post-processed,
+e.g. a fallback description is provided if the backend
+does not provide one.
+*/
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Render the description fallback template.
+// Supported placeholders are {asn} and {address}.
+func neighbourDescriptionFallback(
+	neighbour *api.Neighbour,
+	template string,
+) string {
+	return strings.NewReplacer(
+		"{asn}", strconv.Itoa(neighbour.Asn),
+		"{address}", neighbour.Address,
+	).Replace(template)
+}
+
+// Fill in empty descriptions. An empty template
+// disables the fallback.
+func annotateNeighboursDescription(
+	neighbours api.Neighbours,
+	template string,
+) {
+	if template == "" {
+		return
+	}
+	for _, neighbour := range neighbours {
+		if strings.TrimSpace(neighbour.Description) != "" {
+			continue
+		}
+		neighbour.Description = neighbourDescriptionFallback(
+			neighbour, template)
+		neighbour.DescriptionFallback = true
+	}
+}
+
+// Add the ASN in asdot notation, if configured
+func annotateNeighboursAsnNotation(
+	neighbours api.Neighbours,
+	notation string,
+) {
+	if notation != api.ASN_NOTATION_ASDOT {
+		return
+	}
+	for _, neighbour := range neighbours {
+		neighbour.AsnAsdot = api.FormatAsn(neighbour.Asn, notation)
+	}
+}
+
+// Annotate all neighbours in a neighbours response
+// from a source. Hidden neighbours are removed.
+func annotateNeighboursResponse(
+	source *SourceConfig,
+	response *api.NeighboursResponse,
+) {
+	if response == nil {
+		return
+	}
+
+	if source != nil {
+		response.Neighbours = filterHiddenNeighbours(
+			response.Neighbours, source.HiddenNeighbours)
+	}
+
+	annotateNeighboursDescription(
+		response.Neighbours,
+		AliceConfig.Server.NeighbourDescriptionFallback)
+	annotateNeighboursAsnNotation(
+		response.Neighbours,
+		AliceConfig.Server.AsnNotation)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestAnnotateNeighboursDescription(t *testing.T) {
+	neighbours := api.Neighbours{
+		&api.Neighbour{
+			Id:          "n1",
+			Asn:         2342,
+			Address:     "192.168.1.1",
+			Description: "Some Peer",
+		},
+		&api.Neighbour{
+			Id:      "n2",
+			Asn:     2343,
+			Address: "192.168.1.2",
+		},
+	}
+
+	annotateNeighboursDescription(neighbours, "AS{asn} ({address})")
+
+	// Fallback not applied
+	if neighbours[0].Description != "Some Peer" {
+		t.Error("Unexpected description:", neighbours[0].Description)
+	}
+	if neighbours[0].DescriptionFallback {
+		t.Error("Description should not be flagged as fallback")
+	}
+
+	// Fallback applied
+	if neighbours[1].Description != "AS2343 (192.168.1.2)" {
+		t.Error("Unexpected description:", neighbours[1].Description)
+	}
+	if !neighbours[1].DescriptionFallback {
+		t.Error("Description should be flagged as fallback")
+	}
+}
+
+func TestAnnotateNeighboursDescriptionDisabled(t *testing.T) {
+	neighbours := api.Neighbours{
+		&api.Neighbour{
+			Id:  "n1",
+			Asn: 2342,
+		},
+	}
+
+	annotateNeighboursDescription(neighbours, "")
+	if neighbours[0].Description != "" || neighbours[0].DescriptionFallback {
+		t.Error("Fallback should be disabled without template")
+	}
+}
+
+func TestAnnotateNeighboursAsnNotation(t *testing.T) {
+	neighbours := api.Neighbours{
+		&api.Neighbour{Asn: 65537},
+		&api.Neighbour{Asn: 2342},
+	}
+
+	annotateNeighboursAsnNotation(neighbours, api.ASN_NOTATION_ASPLAIN)
+	if neighbours[0].AsnAsdot != "" {
+		t.Error("Expected no asdot ASN in asplain notation")
+	}
+
+	annotateNeighboursAsnNotation(neighbours, api.ASN_NOTATION_ASDOT)
+	if neighbours[0].AsnAsdot != "1.1" || neighbours[1].AsnAsdot != "2342" {
+		t.Error("Unexpected asdot ASNs:",
+			neighbours[0].AsnAsdot, neighbours[1].AsnAsdot)
+	}
+}
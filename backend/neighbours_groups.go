@@ -0,0 +1,68 @@
+package main
+
+/*
+Neighbours grouped by the group of their route server
+with aggregated session and route counts.
+*/
+
+import (
+	"sort"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Get the neighbours of all sources in a group, with
+// totals per group. If the group is empty, all groups
+// are included. Sources without a group are skipped.
+func (self *NeighboursStore) NeighboursGroups(
+	group string,
+) api.NeighboursGroups {
+	groups := make(map[string]*api.NeighboursGroup)
+
+	self.RLock()
+	for sourceId, neighbours := range self.neighboursMap {
+		sourceConfig, ok := self.configMap[sourceId]
+		if !ok || sourceConfig.Group == "" {
+			continue
+		}
+		if group != "" && sourceConfig.Group != group {
+			continue
+		}
+
+		g, ok := groups[sourceConfig.Group]
+		if !ok {
+			g = &api.NeighboursGroup{
+				Group:      sourceConfig.Group,
+				Sources:    []string{},
+				Neighbours: api.Neighbours{},
+			}
+			groups[sourceConfig.Group] = g
+		}
+		g.Sources = append(g.Sources, sourceId)
+
+		for _, neighbour := range neighbours {
+			g.Neighbours = append(g.Neighbours, neighbour)
+			g.TotalNeighbours++
+			if isNeighbourUp(neighbour) {
+				g.NeighboursUp++
+			}
+			g.RoutesReceived += neighbour.RoutesReceived
+			g.RoutesFiltered += neighbour.RoutesFiltered
+			g.RoutesExported += neighbour.RoutesExported
+			g.RoutesAccepted += neighbour.RoutesAccepted
+		}
+	}
+	self.RUnlock()
+
+	results := make(api.NeighboursGroups, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.Sources)
+		sort.Sort(g.Neighbours)
+		results = append(results, g)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Group < results[j].Group
+	})
+
+	return results
+}
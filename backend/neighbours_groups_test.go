@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestNeighboursGroups(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	store := makeTestNeighboursStore()
+	store.neighboursMap["rs3"] = NeighboursIndex{
+		"ID2233_AS1111": &api.Neighbour{
+			Id:            "ID2233_AS1111",
+			Asn:           1111,
+			State:         "down",
+			RouteServerId: "rs3",
+		},
+	}
+	store.configMap = map[string]*SourceConfig{
+		"rs1": &SourceConfig{Id: "rs1", Group: "FRA"},
+		"rs2": &SourceConfig{Id: "rs2", Group: "FRA"},
+		"rs3": &SourceConfig{Id: "rs3", Group: "AMS"},
+	}
+
+	// Set some counts
+	store.neighboursMap["rs1"]["ID2233_AS2342"].State = "up"
+	store.neighboursMap["rs1"]["ID2233_AS2342"].RoutesReceived = 10
+	store.neighboursMap["rs1"]["ID2233_AS2342"].RoutesAccepted = 8
+	store.neighboursMap["rs2"]["ID2233_AS4223"].State = "up"
+	store.neighboursMap["rs2"]["ID2233_AS4223"].RoutesReceived = 5
+	store.neighboursMap["rs2"]["ID2233_AS4223"].RoutesFiltered = 2
+
+	groups := store.NeighboursGroups("")
+	if len(groups) != 2 {
+		t.Fatal("Expected 2 groups, got:", len(groups))
+	}
+	if groups[0].Group != "AMS" || groups[1].Group != "FRA" {
+		t.Error("Unexpected groups order:", groups[0].Group, groups[1].Group)
+	}
+
+	fra := groups[1]
+	if len(fra.Sources) != 2 || fra.Sources[0] != "rs1" || fra.Sources[1] != "rs2" {
+		t.Error("Unexpected sources in group:", fra.Sources)
+	}
+	if fra.TotalNeighbours != 5 || len(fra.Neighbours) != 5 {
+		t.Error("Expected 5 neighbours, got:", fra.TotalNeighbours)
+	}
+	if fra.NeighboursUp != 2 {
+		t.Error("Expected 2 neighbours up, got:", fra.NeighboursUp)
+	}
+	if fra.RoutesReceived != 15 ||
+		fra.RoutesAccepted != 8 ||
+		fra.RoutesFiltered != 2 {
+		t.Error("Unexpected route counts:", fra)
+	}
+
+	// Filter by group
+	groups = store.NeighboursGroups("AMS")
+	if len(groups) != 1 || groups[0].TotalNeighbours != 1 {
+		t.Error("Expected only group AMS, got:", groups)
+	}
+	if groups[0].NeighboursUp != 0 {
+		t.Error("Expected no neighbours up in AMS")
+	}
+
+	groups = store.NeighboursGroups("unknown")
+	if len(groups) != 0 {
+		t.Error("Expected no groups, got:", groups)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+/*
+Distinct next hops of a neighbour
+
+A neighbour usually announces its routes with a single
+next hop per session. Many distinct next hops may hint
+at an issue, e.g. a misconfigured next-hop-self.
+*/
+
+import (
+	"net/http"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Get the next hop of a route, falling back
+// to the gateway if the next hop is not known.
+func routeNextHop(route *api.Route) string {
+	if route.Bgp.NextHop != "" {
+		return route.Bgp.NextHop
+	}
+	return route.Gateway
+}
+
+// Count the distinct next hops of the routes per neighbour
+func countDistinctNextHops(routes ...api.Routes) map[string]int {
+	nextHops := make(map[string]map[string]bool)
+	for _, rs := range routes {
+		for _, route := range rs {
+			nextHop := routeNextHop(route)
+			if nextHop == "" {
+				continue
+			}
+			seen, ok := nextHops[route.NeighbourId]
+			if !ok {
+				seen = make(map[string]bool)
+				nextHops[route.NeighbourId] = seen
+			}
+			seen[nextHop] = true
+		}
+	}
+
+	counts := make(map[string]int, len(nextHops))
+	for neighbourId, seen := range nextHops {
+		counts[neighbourId] = len(seen)
+	}
+	return counts
+}
+
+// Get the number of distinct next hops in the received
+// routes for each neighbour of a source.
+func (self *RoutesStore) DistinctNextHopsAt(sourceId string) map[string]int {
+	self.RLock()
+	response, ok := self.routesMap[sourceId]
+	self.RUnlock()
+	if !ok {
+		return map[string]int{}
+	}
+	return countDistinctNextHops(response.Imported, response.Filtered)
+}
+
+// Add the number of distinct next hops to the neighbours.
+// The neighbours are copied, as they are shared with the store.
+func annotateNeighboursNextHops(
+	neighbours api.Neighbours,
+	counts map[string]int,
+) api.Neighbours {
+	annotated := make(api.Neighbours, 0, len(neighbours))
+	for _, neighbour := range neighbours {
+		n := *neighbour
+		n.DistinctNextHops = counts[n.Id]
+		annotated = append(annotated, &n)
+	}
+	return annotated
+}
+
+// Filter neighbours with at least min_distinct_next_hops
+func apiQueryFilterDistinctNextHops(
+	req *http.Request,
+	neighbours api.Neighbours,
+) api.Neighbours {
+	minNextHops := apiQueryMustInt(req, "min_distinct_next_hops", -1)
+	if minNextHops < 0 {
+		return neighbours
+	}
+
+	results := make(api.Neighbours, 0, len(neighbours))
+	for _, neighbour := range neighbours {
+		if neighbour.DistinctNextHops >= minNextHops {
+			results = append(results, neighbour)
+		}
+	}
+	return results
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestCountDistinctNextHops(t *testing.T) {
+	imported := api.Routes{
+		&api.Route{NeighbourId: "n1",
+			Bgp: api.BgpInfo{NextHop: "192.0.2.1"}},
+		&api.Route{NeighbourId: "n1",
+			Bgp: api.BgpInfo{NextHop: "192.0.2.1"}},
+		&api.Route{NeighbourId: "n2",
+			Bgp: api.BgpInfo{NextHop: "192.0.2.2"}},
+		&api.Route{NeighbourId: "n2", Gateway: "192.0.2.3"},
+	}
+	filtered := api.Routes{
+		&api.Route{NeighbourId: "n1",
+			Bgp: api.BgpInfo{NextHop: "192.0.2.1"}},
+		&api.Route{NeighbourId: "n2",
+			Bgp: api.BgpInfo{NextHop: "192.0.2.4"}},
+		&api.Route{NeighbourId: "n3"},
+	}
+
+	counts := countDistinctNextHops(imported, filtered)
+	expected := map[string]int{"n1": 1, "n2": 3}
+	for id, count := range expected {
+		if counts[id] != count {
+			t.Error("Expected", count, "next hops for", id,
+				"got:", counts[id])
+		}
+	}
+	if _, ok := counts["n3"]; ok {
+		t.Error("Expected routes without next hop to be ignored")
+	}
+}
+
+func TestApiQueryFilterDistinctNextHops(t *testing.T) {
+	neighbours := annotateNeighboursNextHops(api.Neighbours{
+		&api.Neighbour{Id: "n1"},
+		&api.Neighbour{Id: "n2"},
+		&api.Neighbour{Id: "n3"},
+	}, map[string]int{"n1": 1, "n2": 3})
+
+	if neighbours[2].DistinctNextHops != 0 {
+		t.Error("Expected no next hops for n3")
+	}
+
+	req, _ := http.NewRequest("GET",
+		"/api/v1/routeservers/rs1/neighbors?min_distinct_next_hops=2", nil)
+	filtered := apiQueryFilterDistinctNextHops(req, neighbours)
+	if len(filtered) != 1 || filtered[0].Id != "n2" {
+		t.Error("Expected only n2, got:", filtered)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/routeservers/rs1/neighbors", nil)
+	if len(apiQueryFilterDistinctNextHops(req, neighbours)) != 3 {
+		t.Error("Expected neighbours not to be filtered")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+/*
+Sort neighbours by multiple keys
+*/
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Compare two neighbours by a single key: The result
+// is negative, if a is less than b; zero if equal.
+type neighbourCompareFunc func(a, b *api.Neighbour) int
+
+var NEIGHBOUR_SORT_KEYS = map[string]neighbourCompareFunc{
+	"asn": func(a, b *api.Neighbour) int {
+		return compareInts(a.Asn, b.Asn)
+	},
+	"state": func(a, b *api.Neighbour) int {
+		return strings.Compare(
+			strings.ToLower(a.State), strings.ToLower(b.State))
+	},
+	"address": func(a, b *api.Neighbour) int {
+		return strings.Compare(a.Address, b.Address)
+	},
+	"description": func(a, b *api.Neighbour) int {
+		return strings.Compare(
+			strings.ToLower(a.Description), strings.ToLower(b.Description))
+	},
+	"routes_received": func(a, b *api.Neighbour) int {
+		return compareInts(a.RoutesReceived, b.RoutesReceived)
+	},
+	"routes_filtered": func(a, b *api.Neighbour) int {
+		return compareInts(a.RoutesFiltered, b.RoutesFiltered)
+	},
+	"routes_accepted": func(a, b *api.Neighbour) int {
+		return compareInts(a.RoutesAccepted, b.RoutesAccepted)
+	},
+	"uptime": func(a, b *api.Neighbour) int {
+		return compareInts(int(a.Uptime), int(b.Uptime))
+	},
+	"distinct_next_hops": func(a, b *api.Neighbour) int {
+		return compareInts(a.DistinctNextHops, b.DistinctNextHops)
+	},
+}
+
+// Parse the list of neighbour sort keys
+func parseNeighbourSortKeys(value string) ([]sortKey, error) {
+	return parseSortKeys(value, func(name string) bool {
+		_, ok := NEIGHBOUR_SORT_KEYS[name]
+		return ok
+	})
+}
+
+// Sort neighbours stable by the sort keys
+func sortNeighbours(neighbours api.Neighbours, keys []sortKey) {
+	sort.SliceStable(neighbours, func(i, j int) bool {
+		for _, key := range keys {
+			compare := NEIGHBOUR_SORT_KEYS[key.name]
+			c := key.apply(compare(neighbours[i], neighbours[j]))
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+// Sort the neighbours as requested by the sort query
+// parameter, falling back to the default sort of the
+// source. Without either the neighbours are sorted by ASN.
+func apiQuerySortNeighbours(
+	req *http.Request,
+	neighbours api.Neighbours,
+	defaultSort string,
+) error {
+	sort.Sort(neighbours)
+
+	value := req.URL.Query().Get("sort")
+	if value == "" {
+		value = defaultSort
+	}
+	if value == "" {
+		return nil
+	}
+
+	keys, err := parseNeighbourSortKeys(value)
+	if err != nil {
+		return err
+	}
+
+	sortNeighbours(neighbours, keys)
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestSortNeighboursMultiKey(t *testing.T) {
+	neighbours := api.Neighbours{
+		&api.Neighbour{Id: "n1", Asn: 2342, State: "up"},
+		&api.Neighbour{Id: "n2", Asn: 23, State: "down"},
+		&api.Neighbour{Id: "n3", Asn: 42, State: "up"},
+		&api.Neighbour{Id: "n4", Asn: 1111, State: "down"},
+		&api.Neighbour{Id: "n5", Asn: 42, State: "up"},
+	}
+
+	req, _ := http.NewRequest("GET", "/?sort=state:desc,asn:asc", nil)
+	if err := apiQuerySortNeighbours(req, neighbours, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Up first, then ascending by ASN. Equal keys keep
+	// their order of the default sort.
+	expected := []string{"n3", "n5", "n1", "n2", "n4"}
+	for i, id := range expected {
+		if neighbours[i].Id != id {
+			t.Error("Expected", id, "at", i, "got:", neighbours[i].Id)
+		}
+	}
+
+	req, _ = http.NewRequest("GET", "/?sort=state,asn:desc", nil)
+	if err := apiQuerySortNeighbours(req, neighbours, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{"n4", "n2", "n1", "n3", "n5"}
+	for i, id := range expected {
+		if neighbours[i].Id != id {
+			t.Error("Expected", id, "at", i, "got:", neighbours[i].Id)
+		}
+	}
+}
+
+func TestSortNeighboursDefault(t *testing.T) {
+	neighbours := api.Neighbours{
+		&api.Neighbour{Id: "n1", Asn: 2342},
+		&api.Neighbour{Id: "n2", Asn: 23},
+	}
+	req, _ := http.NewRequest("GET", "/", nil)
+	if err := apiQuerySortNeighbours(req, neighbours, ""); err != nil {
+		t.Fatal(err)
+	}
+	if neighbours[0].Id != "n2" {
+		t.Error("Expected neighbours to be sorted by ASN")
+	}
+}
+
+func TestSortNeighboursInvalid(t *testing.T) {
+	for _, value := range []string{"foo", "asn:up", "state,bar:desc"} {
+		req, _ := http.NewRequest("GET", "/?sort="+value, nil)
+		err := apiQuerySortNeighbours(req, api.Neighbours{}, "")
+		if _, ok := err.(*InvalidSortError); !ok {
+			t.Error("Expected InvalidSortError for:", value, "got:", err)
+		}
+	}
+}
+
+func TestSortNeighboursSourceDefault(t *testing.T) {
+	neighbours := api.Neighbours{
+		&api.Neighbour{Id: "n1", Asn: 2342, State: "up"},
+		&api.Neighbour{Id: "n2", Asn: 23, State: "down"},
+		&api.Neighbour{Id: "n3", Asn: 42, State: "up"},
+	}
+
+	// The default sort of the source is applied
+	req, _ := http.NewRequest("GET", "/", nil)
+	if err := apiQuerySortNeighbours(req, neighbours, "asn:desc"); err != nil {
+		t.Fatal(err)
+	}
+	if neighbours[0].Id != "n1" || neighbours[2].Id != "n2" {
+		t.Error("Expected neighbours sorted by ASN descending")
+	}
+
+	// The requested sort overrides the default
+	req, _ = http.NewRequest("GET", "/?sort=state", nil)
+	if err := apiQuerySortNeighbours(req, neighbours, "asn:desc"); err != nil {
+		t.Fatal(err)
+	}
+	if neighbours[0].Id != "n2" || neighbours[1].Id != "n3" {
+		t.Error("Expected neighbours sorted by state, then ASN")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -68,6 +69,44 @@ func (self *NeighboursStore) Start() {
 	go self.init()
 }
 
+// Get the refresh interval of a source. Sources
+// without an interval use the interval of the store.
+func (self *NeighboursStore) SourceRefreshInterval(
+	sourceId string,
+) time.Duration {
+	config, ok := self.configMap[sourceId]
+	if !ok || config.NeighboursStoreRefreshInterval <= 0 {
+		return self.refreshInterval
+	}
+	return config.NeighboursStoreRefreshInterval
+}
+
+// The store is checked for sources due for a refresh
+// in the shortest interval of all sources.
+func (self *NeighboursStore) updateInterval() time.Duration {
+	interval := time.Duration(0)
+	for sourceId, _ := range self.neighboursMap {
+		sourceInterval := self.SourceRefreshInterval(sourceId)
+		if interval == 0 || sourceInterval < interval {
+			interval = sourceInterval
+		}
+	}
+	if interval == 0 {
+		return self.refreshInterval
+	}
+	return interval
+}
+
+// Check if the last refresh of a source is
+// older than its refresh interval
+func (self *NeighboursStore) refreshDue(sourceId string) bool {
+	lastRefresh := self.statusMap[sourceId].LastRefresh
+	if lastRefresh.IsZero() {
+		return true
+	}
+	return time.Since(lastRefresh) >= self.SourceRefreshInterval(sourceId)
+}
+
 func (self *NeighboursStore) init() {
 	// Perform initial update
 	self.update()
@@ -77,7 +116,7 @@ func (self *NeighboursStore) init() {
 
 	// Periodically update store
 	for {
-		time.Sleep(self.refreshInterval)
+		time.Sleep(self.updateInterval())
 		self.update()
 	}
 }
@@ -106,18 +145,33 @@ func (self *NeighboursStore) update() {
 		if self.statusMap[sourceId].State == STATE_UPDATING {
 			continue // nothing to do here. really.
 		}
-
-		// Start updating
-		self.Lock()
-		self.statusMap[sourceId] = StoreStatus{
-			State: STATE_UPDATING,
+		if !self.refreshDue(sourceId) {
+			continue
 		}
-		self.Unlock()
 
 		sourceConfig := self.configMap[sourceId]
 		source := sourceConfig.getInstance()
 
-		neighboursRes, err := source.Neighbours()
+		// Refreshing is suspended during maintenance
+		if sourceConfig.inMaintenance(time.Now()) {
+			self.Lock()
+			self.statusMap[sourceId] = self.statusMap[sourceId].maintenance()
+			self.Unlock()
+			continue
+		}
+
+		// Start updating
+		self.Lock()
+		retries := self.statusMap[sourceId].fetchRetries(sourceConfig)
+		self.statusMap[sourceId] = self.statusMap[sourceId].refreshing()
+		self.Unlock()
+
+		var neighboursRes *api.NeighboursResponse
+		err := retryWithBackoff(retries, sourceConfig.StartupRetryDelay,
+			func() (err error) {
+				neighboursRes, err = source.Neighbours()
+				return err
+			})
 		if err != nil {
 			log.Println(
 				"Refreshing the neighbors store failed for:",
@@ -127,17 +181,18 @@ func (self *NeighboursStore) update() {
 			)
 			// That's sad.
 			self.Lock()
-			self.statusMap[sourceId] = StoreStatus{
-				State:       STATE_ERROR,
-				LastError:   err,
-				LastRefresh: time.Now(),
+			status := self.statusMap[sourceId].failed(err, sourceConfig)
+			if status.staleExpired(sourceConfig) {
+				self.neighboursMap[sourceId] = make(NeighboursIndex)
 			}
+			self.statusMap[sourceId] = status
 			self.Unlock()
 
 			errorCount++
 			continue
 		}
 
+		annotateNeighboursResponse(sourceConfig, neighboursRes)
 		neighbours := neighboursRes.Neighbours
 
 		// Update data
@@ -151,8 +206,10 @@ func (self *NeighboursStore) update() {
 		self.neighboursMap[sourceId] = index
 		// Update state
 		self.statusMap[sourceId] = StoreStatus{
-			LastRefresh: time.Now(),
-			State:       STATE_READY,
+			LastRefresh:           time.Now(),
+			State:                 STATE_READY,
+			LastRefreshDuration:   self.statusMap[sourceId].refreshDuration(),
+			LastSuccessfulRefresh: time.Now(),
 		}
 		self.lastRefresh = time.Now().UTC()
 		self.Unlock()
@@ -299,32 +356,138 @@ func (self *NeighboursStore) FilterNeighbors(
 	return results
 }
 
+// Helper: Check if the state of the neighbour is
+// one of the configured up states (default: up)
+func isNeighbourUp(neighbour *api.Neighbour) bool {
+	upStates := []string{"up"}
+	if AliceConfig != nil && len(AliceConfig.Server.NeighbourUpStates) > 0 {
+		upStates = AliceConfig.Server.NeighbourUpStates
+	}
+	for _, state := range upStates {
+		if strings.EqualFold(neighbour.State, strings.TrimSpace(state)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper: An established session without any
+// received routes. The configured up states are
+// not considered, as those may include sessions
+// which are not established.
+func isEmptyNeighbour(neighbour *api.Neighbour) bool {
+	return strings.ToLower(neighbour.State) == "up" &&
+		neighbour.RoutesReceived == 0
+}
+
+/*
+ Get neighbours with an established session, not
+ receiving any routes from a single route server.
+*/
+func (self *NeighboursStore) EmptyNeighboursAt(
+	sourceId string,
+) api.Neighbours {
+	results := api.Neighbours{}
+
+	self.RLock()
+	neighbours := self.neighboursMap[sourceId]
+	self.RUnlock()
+
+	for _, neighbour := range neighbours {
+		if isEmptyNeighbour(neighbour) {
+			results = append(results, neighbour)
+		}
+	}
+
+	return results
+}
+
+/*
+ Get empty neighbours from all route servers.
+*/
+func (self *NeighboursStore) EmptyNeighbours() api.Neighbours {
+	results := api.Neighbours{}
+
+	self.RLock()
+	sourceIds := make([]string, 0, len(self.neighboursMap))
+	for sourceId, _ := range self.neighboursMap {
+		sourceIds = append(sourceIds, sourceId)
+	}
+	self.RUnlock()
+
+	for _, sourceId := range sourceIds {
+		results = append(results, self.EmptyNeighboursAt(sourceId)...)
+	}
+
+	return results
+}
+
+/*
+ Get the states of all neighbours from all
+ route servers as a compact map.
+*/
+func (self *NeighboursStore) NeighboursStates() api.NeighboursStatesMap {
+	self.RLock()
+	defer self.RUnlock()
+
+	states := make(api.NeighboursStatesMap, len(self.neighboursMap))
+	for sourceId, neighbours := range self.neighboursMap {
+		sourceStates := make(map[string]string, len(neighbours))
+		for neighbourId, neighbour := range neighbours {
+			sourceStates[neighbourId] = neighbour.State
+		}
+		states[sourceId] = sourceStates
+	}
+
+	return states
+}
+
 // Build some stats for monitoring
 func (self *NeighboursStore) Stats() NeighboursStoreStats {
 	totalNeighbours := 0
+	totalNeighboursUp := 0
 	rsStats := []RouteServerNeighboursStats{}
 
 	self.RLock()
 	for sourceId, neighbours := range self.neighboursMap {
 		status := self.statusMap[sourceId]
+
+		neighboursUp := 0
+		for _, neighbour := range neighbours {
+			if isNeighbourUp(neighbour) {
+				neighboursUp++
+			}
+		}
+
 		totalNeighbours += len(neighbours)
+		totalNeighboursUp += neighboursUp
 		serverStats := RouteServerNeighboursStats{
-			Name:       self.configMap[sourceId].Name,
-			State:      stateToString(status.State),
-			Neighbours: len(neighbours),
-			UpdatedAt:  status.LastRefresh,
+			Name:         self.configMap[sourceId].Name,
+			State:        stateToString(status.State),
+			Neighbours:   len(neighbours),
+			NeighboursUp: neighboursUp,
+			UpdatedAt:    status.LastRefresh,
+			Refresh:      makeRefreshStats(status),
 		}
 		rsStats = append(rsStats, serverStats)
 	}
 	self.RUnlock()
 
 	storeStats := NeighboursStoreStats{
-		TotalNeighbours: totalNeighbours,
-		RouteServers:    rsStats,
+		TotalNeighbours:   totalNeighbours,
+		TotalNeighboursUp: totalNeighboursUp,
+		RouteServers:      rsStats,
 	}
 	return storeStats
 }
 
+// Check if any source was not refreshed yet
+func (self *NeighboursStore) IsLoading() bool {
+	self.RLock()
+	defer self.RUnlock()
+	return anyStoreStatusLoading(self.statusMap)
+}
+
 func (self *NeighboursStore) CachedAt() time.Time {
 	return self.lastRefresh
 }
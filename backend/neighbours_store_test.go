@@ -2,9 +2,12 @@ package main
 
 import (
 	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
 
+	"fmt"
 	"sort"
 	"testing"
+	"time"
 )
 
 /*
@@ -184,3 +187,269 @@ func TestNeighborFilter(t *testing.T) {
 	}
 
 }
+
+func TestEmptyNeighbours(t *testing.T) {
+	store := &NeighboursStore{
+		neighboursMap: map[string]NeighboursIndex{
+			"rs1": NeighboursIndex{
+				"empty": &api.Neighbour{
+					Id:             "empty",
+					State:          "up",
+					RoutesReceived: 0,
+				},
+				"down": &api.Neighbour{
+					Id:             "down",
+					State:          "down",
+					RoutesReceived: 0,
+				},
+				"normal": &api.Neighbour{
+					Id:             "normal",
+					State:          "up",
+					RoutesReceived: 23,
+				},
+			},
+			"rs2": NeighboursIndex{
+				"empty2": &api.Neighbour{
+					Id:             "empty2",
+					State:          "Up",
+					RoutesReceived: 0,
+				},
+				"start": &api.Neighbour{
+					Id:             "start",
+					State:          "start",
+					RoutesReceived: 0,
+				},
+			},
+		},
+	}
+
+	neighbours := store.EmptyNeighboursAt("rs1")
+	if len(neighbours) != 1 || neighbours[0].Id != "empty" {
+		t.Error("Expected only the empty neighbour, got:", neighbours)
+	}
+
+	neighbours = store.EmptyNeighbours()
+	sort.Slice(neighbours, func(i, j int) bool {
+		return neighbours[i].Id < neighbours[j].Id
+	})
+	if len(neighbours) != 2 ||
+		neighbours[0].Id != "empty" ||
+		neighbours[1].Id != "empty2" {
+		t.Error("Expected empty neighbours from all sources, got:", neighbours)
+	}
+
+	if len(store.EmptyNeighboursAt("rs3")) != 0 {
+		t.Error("Expected no neighbours for unknown source")
+	}
+}
+
+func TestNeighboursStates(t *testing.T) {
+	store := makeTestNeighboursStore()
+	store.neighboursMap["rs1"]["ID2233_AS2342"].State = "up"
+	store.neighboursMap["rs1"]["ID2233_AS2343"].State = "down"
+	store.neighboursMap["rs2"]["ID2233_AS4223"].State = "start"
+
+	states := store.NeighboursStates()
+	if len(states) != 2 {
+		t.Fatal("Expected states for 2 sources, got:", len(states))
+	}
+	if len(states["rs1"]) != 3 {
+		t.Error("Expected 3 neighbours for rs1, got:", len(states["rs1"]))
+	}
+	if states["rs1"]["ID2233_AS2342"] != "up" ||
+		states["rs1"]["ID2233_AS2343"] != "down" ||
+		states["rs2"]["ID2233_AS4223"] != "start" {
+		t.Error("Unexpected states:", states)
+	}
+}
+
+func TestNeighboursStatsUpStates(t *testing.T) {
+	defer saveTestGlobals()()
+	store := &NeighboursStore{
+		neighboursMap: map[string]NeighboursIndex{
+			"rs1": NeighboursIndex{
+				"n1": &api.Neighbour{Id: "n1", State: "up"},
+				"n2": &api.Neighbour{Id: "n2", State: "start"},
+				"n3": &api.Neighbour{Id: "n3", State: "down"},
+			},
+		},
+		statusMap: map[string]StoreStatus{},
+		configMap: map[string]*SourceConfig{
+			"rs1": &SourceConfig{Id: "rs1", Name: "rs1"},
+		},
+	}
+
+	// Default: only up counts
+	AliceConfig = &Config{}
+	stats := store.Stats()
+	if stats.TotalNeighbours != 3 || stats.TotalNeighboursUp != 1 {
+		t.Error("Expected 1 of 3 neighbours up, got:",
+			stats.TotalNeighboursUp, "of", stats.TotalNeighbours)
+	}
+
+	// Transitional states count as up
+	AliceConfig.Server.NeighbourUpStates = []string{"up", "start"}
+	stats = store.Stats()
+	if stats.TotalNeighboursUp != 2 ||
+		stats.RouteServers[0].NeighboursUp != 2 {
+		t.Error("Expected 2 neighbours up, got:", stats.TotalNeighboursUp)
+	}
+
+	// Sessions in transitional states are not empty
+	empty := store.EmptyNeighboursAt("rs1")
+	if len(empty) != 1 || empty[0].Id != "n1" {
+		t.Error("Expected only n1 to be empty, got:", empty)
+	}
+}
+
+// A source without any neighbours
+type emptyNeighboursSource struct {
+	expireCountingSource
+}
+
+func (self *emptyNeighboursSource) Neighbours() (*api.NeighboursResponse, error) {
+	return &api.NeighboursResponse{Neighbours: api.Neighbours{}}, nil
+}
+
+func TestNeighboursStoreLoading(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:       "rs1",
+				instance: &emptyNeighboursSource{},
+			},
+		},
+	}
+	AliceNeighboursStore = NewNeighboursStore(AliceConfig)
+
+	params := httprouter.Params{httprouter.Param{Key: "id", Value: "rs1"}}
+
+	// The source was never refreshed
+	result, err := apiNeighborsListEmpty(nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := result.(*api.NeighboursResponse)
+	if !response.Api.Loading {
+		t.Error("Expected a never refreshed source to be loading")
+	}
+	result, _ = apiLookupEmptyNeighborsGlobal(nil, nil)
+	if !result.(*api.NeighboursResponse).Api.Loading {
+		t.Error("Expected the lookup to be loading")
+	}
+
+	// The source is refreshed, but genuinely empty
+	AliceNeighboursStore.update()
+
+	result, err = apiNeighborsListEmpty(nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response = result.(*api.NeighboursResponse)
+	if response.Api.Loading {
+		t.Error("Expected a refreshed source not to be loading")
+	}
+	if len(response.Neighbours) != 0 {
+		t.Error("Expected no neighbours, got:", response.Neighbours)
+	}
+	result, _ = apiLookupEmptyNeighborsGlobal(nil, nil)
+	if result.(*api.NeighboursResponse).Api.Loading {
+		t.Error("Expected the lookup not to be loading")
+	}
+}
+
+func TestNeighboursStoreRefreshDue(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:                             "rs1",
+				NeighboursStoreRefreshInterval: time.Hour,
+				instance:                       &emptyNeighboursSource{},
+			},
+			&SourceConfig{
+				Id:       "rs2",
+				instance: &emptyNeighboursSource{},
+			},
+		},
+	}
+	store := NewNeighboursStore(AliceConfig)
+	store.update()
+
+	// Pretend the last refresh was 10 minutes ago
+	for _, id := range []string{"rs1", "rs2"} {
+		status := store.statusMap[id]
+		status.LastRefresh = time.Now().Add(-10 * time.Minute)
+		store.statusMap[id] = status
+	}
+
+	if store.refreshDue("rs1") {
+		t.Error("Expected rs1 not to be due for a refresh")
+	}
+	if !store.refreshDue("rs2") {
+		t.Error("Expected rs2 to be due for a refresh")
+	}
+}
+
+// A source failing to provide neighbours when flagged
+type failingNeighboursSource struct {
+	expireCountingSource
+	fail bool
+}
+
+func (self *failingNeighboursSource) Neighbours() (*api.NeighboursResponse, error) {
+	if self.fail {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &api.NeighboursResponse{
+		Neighbours: api.Neighbours{
+			&api.Neighbour{Id: "ID2233_AS2342", Asn: 2342},
+		},
+	}, nil
+}
+
+func TestNeighboursStoreStaleGracePeriod(t *testing.T) {
+	defer saveTestGlobals()()
+	source := &failingNeighboursSource{}
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:               "rs1",
+				StaleGracePeriod: 10 * time.Minute,
+				instance:         source,
+			},
+		},
+	}
+	store := NewNeighboursStore(AliceConfig)
+	store.update()
+
+	// Within the grace period the neighbours are served stale
+	source.fail = true
+	store.statusMap["rs1"] = StoreStatus{
+		State:                 STATE_READY,
+		LastSuccessfulRefresh: time.Now().Add(-5 * time.Minute),
+	}
+	store.update()
+	status := store.SourceStatus("rs1")
+	if !status.Stale || !status.IsAvailable() {
+		t.Error("Expected stale neighbours to be available")
+	}
+	if len(store.GetNeighborsAt("rs1")) != 1 {
+		t.Error("Expected the previous neighbours to be kept")
+	}
+
+	// After the grace period the neighbours are cleared
+	store.statusMap["rs1"] = StoreStatus{
+		State:                 STATE_ERROR,
+		LastSuccessfulRefresh: time.Now().Add(-time.Hour),
+	}
+	store.update()
+	status = store.SourceStatus("rs1")
+	if status.Stale || status.IsAvailable() {
+		t.Error("Expected neighbours not to be available")
+	}
+	if len(store.GetNeighborsAt("rs1")) != 0 {
+		t.Error("Expected neighbours to be cleared")
+	}
+}
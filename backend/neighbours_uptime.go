@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Format the uptime of the neighbours as requested by
+// the uptime_format query parameter, falling back to the
+// configured format. The neighbours are copied, as they
+// are shared with the store.
+func apiQueryFormatUptime(
+	req *http.Request,
+	neighbours api.Neighbours,
+) (api.Neighbours, error) {
+	format := req.URL.Query().Get("uptime_format")
+	if format == "" {
+		format = AliceConfig.Server.UptimeFormat
+	}
+
+	switch format {
+	case "", api.UPTIME_FORMAT_NANOSECONDS:
+		return neighbours, nil
+	case api.UPTIME_FORMAT_SECONDS, api.UPTIME_FORMAT_HUMAN:
+	default:
+		return nil, &InvalidFormatError{Format: format}
+	}
+
+	formatted := make(api.Neighbours, 0, len(neighbours))
+	for _, neighbour := range neighbours {
+		n := *neighbour
+		n.FormatUptime(format)
+		formatted = append(formatted, &n)
+	}
+	return formatted, nil
+}
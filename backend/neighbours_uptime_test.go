@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestApiQueryFormatUptime(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Server: ServerConfig{
+			UptimeFormat: api.UPTIME_FORMAT_SECONDS,
+		},
+	}
+	neighbours := api.Neighbours{
+		&api.Neighbour{Id: "n1", Uptime: 90 * time.Second},
+	}
+
+	// Configured format
+	req, _ := http.NewRequest("GET", "/", nil)
+	formatted, err := apiQueryFormatUptime(req, neighbours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *formatted[0].UptimeSeconds != 90 {
+		t.Error("Unexpected uptime:", *formatted[0].UptimeSeconds)
+	}
+	if neighbours[0].UptimeSeconds != nil {
+		t.Error("The neighbours of the store must not be modified")
+	}
+
+	// Requested format
+	req, _ = http.NewRequest("GET", "/?uptime_format=human", nil)
+	formatted, err = apiQueryFormatUptime(req, neighbours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if formatted[0].UptimeHuman != "1m30s" || formatted[0].UptimeSeconds != nil {
+		t.Error("Unexpected uptime:", formatted[0].UptimeHuman)
+	}
+
+	req, _ = http.NewRequest("GET", "/?uptime_format=nanoseconds", nil)
+	formatted, _ = apiQueryFormatUptime(req, neighbours)
+	if formatted[0] != neighbours[0] {
+		t.Error("Expected neighbours to be unchanged")
+	}
+
+	req, _ = http.NewRequest("GET", "/?uptime_format=weeks", nil)
+	if _, err := apiQueryFormatUptime(req, neighbours); err == nil {
+		t.Error("Expected error for invalid format")
+	}
+}
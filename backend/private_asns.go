@@ -0,0 +1,88 @@
+package main
+
+/*
+Private ASNs can be stripped from the displayed AS paths
+of a source. This is presentation only: The routes in the
+store and caches keep the complete AS path, so the origin
+and the filters are not affected.
+*/
+
+import (
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Check if the ASN is in one of the private ranges
+// (RFC 6996)
+func isPrivateAsn(asn int) bool {
+	return (asn >= 64512 && asn <= 65534) ||
+		(asn >= 4200000000 && asn <= 4294967294)
+}
+
+// Remove private ASNs from the AS path. The origin is
+// kept if requested, even if it is private.
+func stripPrivateAsns(path []int, keepOrigin bool) []int {
+	stripped := make([]int, 0, len(path))
+	for i, asn := range path {
+		isOrigin := i == len(path)-1
+		if isPrivateAsn(asn) && !(keepOrigin && isOrigin) {
+			continue
+		}
+		stripped = append(stripped, asn)
+	}
+	return stripped
+}
+
+// Strip the private ASNs from the AS path of the BGP info.
+// The AS path in asdot notation is stripped as well, if
+// it was annotated.
+func stripBgpPrivateAsns(bgp api.BgpInfo, keepOrigin bool) api.BgpInfo {
+	bgp.AsPath = stripPrivateAsns(bgp.AsPath, keepOrigin)
+	if bgp.AsPathAsdot != nil {
+		asPath := make([]string, 0, len(bgp.AsPath))
+		for _, asn := range bgp.AsPath {
+			asPath = append(asPath,
+				api.FormatAsn(asn, api.ASN_NOTATION_ASDOT))
+		}
+		bgp.AsPathAsdot = asPath
+	}
+	return bgp
+}
+
+// Strip the private ASNs from the AS paths of the
+// routes, if configured for the source.
+// The routes are copied, as they are shared with the cache.
+func stripRoutesPrivateAsns(
+	source *SourceConfig,
+	routes api.Routes,
+) api.Routes {
+	if source == nil || !source.StripPrivateAsns {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, r := range routes {
+		route := *r
+		route.Bgp = stripBgpPrivateAsns(
+			r.Bgp, source.StripPrivateAsnsKeepOrigin)
+		results = append(results, &route)
+	}
+	return results
+}
+
+// Same for lookup routes from different sources
+func stripLookupRoutesPrivateAsns(routes api.LookupRoutes) api.LookupRoutes {
+	results := make(api.LookupRoutes, 0, len(routes))
+	for _, r := range routes {
+		source := AliceConfig.SourceById(r.Routeserver.Id)
+		if source == nil || !source.StripPrivateAsns {
+			results = append(results, r)
+			continue
+		}
+
+		route := *r
+		route.Bgp = stripBgpPrivateAsns(
+			r.Bgp, source.StripPrivateAsnsKeepOrigin)
+		results = append(results, &route)
+	}
+	return results
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestIsPrivateAsn(t *testing.T) {
+	for asn, expected := range map[int]bool{
+		9033:       false,
+		64511:      false,
+		64512:      true,
+		65534:      true,
+		65535:      false,
+		4199999999: false,
+		4200000000: true,
+		4294967294: true,
+		4294967295: false,
+	} {
+		if isPrivateAsn(asn) != expected {
+			t.Error("unexpected private asn check for", asn)
+		}
+	}
+}
+
+func TestStripPrivateAsns(t *testing.T) {
+	path := []int{64512, 9033, 4200000000, 64600, 65001}
+
+	stripped := stripPrivateAsns(path, false)
+	if !reflect.DeepEqual(stripped, []int{9033}) {
+		t.Error("unexpected path:", stripped)
+	}
+
+	stripped = stripPrivateAsns(path, true)
+	if !reflect.DeepEqual(stripped, []int{9033, 65001}) {
+		t.Error("expected origin to be kept, got:", stripped)
+	}
+}
+
+func TestStripRoutesPrivateAsns(t *testing.T) {
+	routes := api.Routes{
+		&api.Route{Id: "r1", Bgp: api.BgpInfo{AsPath: []int{9033, 64512, 2342}}},
+	}
+
+	// Disabled
+	source := &SourceConfig{}
+	results := stripRoutesPrivateAsns(source, routes)
+	if !reflect.DeepEqual(results[0].Bgp.AsPath, []int{9033, 64512, 2342}) {
+		t.Error("expected private asns to be retained, got:",
+			results[0].Bgp.AsPath)
+	}
+
+	// Enabled
+	source.StripPrivateAsns = true
+	results = stripRoutesPrivateAsns(source, routes)
+	if !reflect.DeepEqual(results[0].Bgp.AsPath, []int{9033, 2342}) {
+		t.Error("expected private asns to be removed, got:",
+			results[0].Bgp.AsPath)
+	}
+
+	// The original route is not modified
+	if len(routes[0].Bgp.AsPath) != 3 {
+		t.Error("expected original route to be unchanged")
+	}
+}
+
+func TestStripLookupRoutesPrivateAsns(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{Id: "rs1", StripPrivateAsns: true},
+			&SourceConfig{Id: "rs2"},
+		},
+	}
+
+	routes := api.LookupRoutes{
+		&api.LookupRoute{
+			Routeserver: api.Routeserver{Id: "rs1"},
+			Bgp:         api.BgpInfo{AsPath: []int{9033, 65000}},
+		},
+		&api.LookupRoute{
+			Routeserver: api.Routeserver{Id: "rs2"},
+			Bgp:         api.BgpInfo{AsPath: []int{9033, 65000}},
+		},
+	}
+
+	results := stripLookupRoutesPrivateAsns(routes)
+	if !reflect.DeepEqual(results[0].Bgp.AsPath, []int{9033}) {
+		t.Error("expected private asns to be removed, got:",
+			results[0].Bgp.AsPath)
+	}
+	if !reflect.DeepEqual(results[1].Bgp.AsPath, []int{9033, 65000}) {
+		t.Error("expected private asns to be retained, got:",
+			results[1].Bgp.AsPath)
+	}
+}
+
+func TestStripRoutesPrivateAsnsAsdot(t *testing.T) {
+	source := &SourceConfig{Id: "rs1", StripPrivateAsns: true}
+	routes := api.Routes{
+		&api.Route{
+			Bgp: api.BgpInfo{AsPath: []int{9033, 4200000000, 196608}},
+		},
+	}
+	annotateAsPathNotation(routes, api.ASN_NOTATION_ASDOT)
+
+	results := stripRoutesPrivateAsns(source, routes)
+	expected := []string{"9033", "3.0"}
+	if !reflect.DeepEqual(results[0].Bgp.AsPathAsdot, expected) {
+		t.Error("expected private asns to be removed from asdot, got:",
+			results[0].Bgp.AsPathAsdot)
+	}
+
+	// Without the annotation, no asdot path is added
+	routes[0].Bgp.AsPathAsdot = nil
+	results = stripRoutesPrivateAsns(source, routes)
+	if results[0].Bgp.AsPathAsdot != nil {
+		t.Error("expected no asdot path, got:", results[0].Bgp.AsPathAsdot)
+	}
+}
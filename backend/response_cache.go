@@ -0,0 +1,194 @@
+package main
+
+/*
+Cache api responses for a configurable time.
+
+The endpoints are grouped (status, neighbours, routes and
+lookup) and each group has its own TTL, as endpoints
+tolerate different staleness.
+*/
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/alice-lg/alice-lg/backend/caches"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	RESPONSE_CACHE_STATUS     = "status"
+	RESPONSE_CACHE_NEIGHBOURS = "neighbours"
+	RESPONSE_CACHE_ROUTES     = "routes"
+	RESPONSE_CACHE_LOOKUP     = "lookup"
+)
+
+// Sweep expired entries at most once per interval
+const RESPONSE_CACHE_SWEEP_INTERVAL = time.Minute
+
+// Keep at most this many responses, if not configured
+const RESPONSE_CACHE_MAX_ENTRIES = 1000
+
+type ResponseCacheConfig struct {
+	// Default TTL in seconds, 0 disables the cache
+	Ttl int `ini:"ttl"`
+
+	// TTLs per endpoint group. A negative value
+	// falls back to the default TTL.
+	StatusTtl     int `ini:"status_ttl"`
+	NeighboursTtl int `ini:"neighbours_ttl"`
+	RoutesTtl     int `ini:"routes_ttl"`
+	LookupTtl     int `ini:"lookup_ttl"`
+
+	// Upper bound for the number of cached responses.
+	// The least recently used response is evicted.
+	MaxEntries int `ini:"max_entries"`
+}
+
+// Get the TTL for an endpoint group
+func (self ResponseCacheConfig) GroupTtl(group string) time.Duration {
+	ttl := -1
+	switch group {
+	case RESPONSE_CACHE_STATUS:
+		ttl = self.StatusTtl
+	case RESPONSE_CACHE_NEIGHBOURS:
+		ttl = self.NeighboursTtl
+	case RESPONSE_CACHE_ROUTES:
+		ttl = self.RoutesTtl
+	case RESPONSE_CACHE_LOOKUP:
+		ttl = self.LookupTtl
+	}
+	if ttl < 0 {
+		ttl = self.Ttl
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+type responseCacheEntry struct {
+	response  api.Response
+	expiresAt time.Time
+}
+
+type responseCache struct {
+	config ResponseCacheConfig
+
+	entries    map[string]*responseCacheEntry
+	accessedAt caches.LRUMap
+	maxEntries int
+	lastSweep  time.Time
+	sync.Mutex
+}
+
+func newResponseCache(config ResponseCacheConfig) *responseCache {
+	maxEntries := config.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = RESPONSE_CACHE_MAX_ENTRIES
+	}
+	return &responseCache{
+		config:     config,
+		entries:    make(map[string]*responseCacheEntry),
+		accessedAt: make(caches.LRUMap),
+		maxEntries: maxEntries,
+		lastSweep:  time.Now(),
+	}
+}
+
+// Get a response, if present and not expired
+func (self *responseCache) Get(key string) api.Response {
+	self.Lock()
+	defer self.Unlock()
+
+	entry, ok := self.entries[key]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	if now.After(entry.expiresAt) {
+		self.remove(key)
+		return nil
+	}
+	self.accessedAt[key] = now
+	return entry.response
+}
+
+// Remove an entry, the lock must be held
+func (self *responseCache) remove(key string) {
+	delete(self.entries, key)
+	delete(self.accessedAt, key)
+}
+
+// Set a response and remove expired entries. When the
+// cache is full, the least recently used entry is evicted.
+func (self *responseCache) Set(
+	key string,
+	response api.Response,
+	ttl time.Duration,
+) {
+	self.Lock()
+	defer self.Unlock()
+
+	now := time.Now()
+	if now.Sub(self.lastSweep) > RESPONSE_CACHE_SWEEP_INTERVAL {
+		for k, entry := range self.entries {
+			if now.After(entry.expiresAt) {
+				self.remove(k)
+			}
+		}
+		self.lastSweep = now
+	}
+
+	_, exists := self.entries[key]
+	if !exists && len(self.entries) >= self.maxEntries {
+		self.remove(self.accessedAt.LRU())
+	}
+
+	self.accessedAt[key] = now
+	self.entries[key] = &responseCacheEntry{
+		response:  response,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+// Derive the cache key from the path and the
+// normalized query of the request
+func responseCacheKey(req *http.Request) string {
+	return req.URL.Path + "?" + req.URL.Query().Encode()
+}
+
+// Wrap an api endpoint with the cache for an
+// endpoint group. Errors are not cached.
+func cachedEndpoint(
+	cache *responseCache,
+	group string,
+	wrapped apiEndpoint,
+) apiEndpoint {
+	if cache == nil {
+		return wrapped
+	}
+	ttl := cache.config.GroupTtl(group)
+	if ttl <= 0 {
+		return wrapped
+	}
+	return func(
+		req *http.Request,
+		params httprouter.Params,
+	) (api.Response, error) {
+		key := responseCacheKey(req)
+		if response := cache.Get(key); response != nil {
+			return response, nil
+		}
+
+		response, err := wrapped(req, params)
+		if err != nil {
+			return nil, err
+		}
+		cache.Set(key, response, ttl)
+
+		return response, nil
+	}
+}
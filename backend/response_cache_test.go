@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/go-ini/ini"
+	"github.com/julienschmidt/httprouter"
+)
+
+func countingEndpoint(calls *int) apiEndpoint {
+	return func(
+		req *http.Request,
+		params httprouter.Params,
+	) (api.Response, error) {
+		*calls++
+		return &api.StatusResponse{}, nil
+	}
+}
+
+func TestResponseCacheGroupTtl(t *testing.T) {
+	config := ResponseCacheConfig{
+		Ttl:           5,
+		StatusTtl:     60,
+		NeighboursTtl: -1,
+		RoutesTtl:     0,
+		LookupTtl:     10,
+	}
+
+	expected := map[string]time.Duration{
+		RESPONSE_CACHE_STATUS:     60 * time.Second,
+		RESPONSE_CACHE_NEIGHBOURS: 5 * time.Second,
+		RESPONSE_CACHE_ROUTES:     0,
+		RESPONSE_CACHE_LOOKUP:     10 * time.Second,
+	}
+	for group, ttl := range expected {
+		if config.GroupTtl(group) != ttl {
+			t.Error("expected ttl", ttl, "for", group,
+				"got:", config.GroupTtl(group))
+		}
+	}
+}
+
+func TestCachedEndpointHonorsGroupTtl(t *testing.T) {
+	cache := newResponseCache(ResponseCacheConfig{
+		Ttl:           0,
+		StatusTtl:     60,
+		NeighboursTtl: 30,
+		RoutesTtl:     0,
+		LookupTtl:     10,
+	})
+
+	expected := map[string]time.Duration{
+		RESPONSE_CACHE_STATUS:     60 * time.Second,
+		RESPONSE_CACHE_NEIGHBOURS: 30 * time.Second,
+		RESPONSE_CACHE_LOOKUP:     10 * time.Second,
+	}
+
+	for group, ttl := range expected {
+		calls := 0
+		handler := cachedEndpoint(cache, group, countingEndpoint(&calls))
+
+		req, _ := http.NewRequest("GET", "/api/v1/"+group+"?b=2&a=1", nil)
+		handler(req, nil)
+		t0 := time.Now()
+
+		// Same query in a different order hits the cache
+		req, _ = http.NewRequest("GET", "/api/v1/"+group+"?a=1&b=2", nil)
+		handler(req, nil)
+		if calls != 1 {
+			t.Error(group, "expected a cached response, calls:", calls)
+		}
+
+		// The entry expires after the group ttl
+		key := responseCacheKey(req)
+		expiresAt := cache.entries[key].expiresAt
+		if expiresAt.Sub(t0) > ttl || t0.Add(ttl).Sub(expiresAt) > time.Second {
+			t.Error(group, "unexpected expiry:", expiresAt.Sub(t0))
+		}
+
+		cache.entries[key].expiresAt = time.Now().Add(-time.Second)
+		handler(req, nil)
+		if calls != 2 {
+			t.Error(group, "expected expired response to be refreshed")
+		}
+	}
+
+	// Routes are not cached
+	calls := 0
+	handler := cachedEndpoint(
+		cache, RESPONSE_CACHE_ROUTES, countingEndpoint(&calls))
+	req, _ := http.NewRequest("GET", "/api/v1/routes", nil)
+	handler(req, nil)
+	handler(req, nil)
+	if calls != 2 {
+		t.Error("expected routes not to be cached, calls:", calls)
+	}
+}
+
+func TestResponseCacheMaxEntries(t *testing.T) {
+	cache := newResponseCache(ResponseCacheConfig{
+		Ttl:        60,
+		MaxEntries: 2,
+	})
+	ttl := time.Minute
+
+	cache.Set("/a?", &api.StatusResponse{}, ttl)
+	time.Sleep(time.Millisecond)
+	cache.Set("/b?", &api.StatusResponse{}, ttl)
+	time.Sleep(time.Millisecond)
+
+	// Access the first entry, so the second is evicted
+	if cache.Get("/a?") == nil {
+		t.Fatal("Expected cached response")
+	}
+	time.Sleep(time.Millisecond)
+	cache.Set("/c?", &api.StatusResponse{}, ttl)
+
+	if len(cache.entries) != 2 {
+		t.Error("Expected 2 entries, got:", len(cache.entries))
+	}
+	if cache.Get("/b?") != nil {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if cache.Get("/a?") == nil || cache.Get("/c?") == nil {
+		t.Error("Expected recently used entries to be kept")
+	}
+
+	// Replacing an entry does not evict another
+	cache.Set("/c?", &api.StatusResponse{}, ttl)
+	if len(cache.entries) != 2 {
+		t.Error("Expected 2 entries, got:", len(cache.entries))
+	}
+}
+
+func TestResponseCacheDefaultMaxEntries(t *testing.T) {
+	cache := newResponseCache(ResponseCacheConfig{Ttl: 60})
+	if cache.maxEntries != RESPONSE_CACHE_MAX_ENTRIES {
+		t.Error("Unexpected max entries:", cache.maxEntries)
+	}
+}
+
+func TestGetResponseCacheConfig(t *testing.T) {
+	parsed, err := ini.Load([]byte(
+		"[response_cache]\nttl = 5\nstatus_ttl = 60\nroutes_ttl = 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := getResponseCacheConfig(parsed)
+
+	if config.GroupTtl(RESPONSE_CACHE_STATUS) != 60*time.Second {
+		t.Error("unexpected status ttl:", config.StatusTtl)
+	}
+	if config.GroupTtl(RESPONSE_CACHE_ROUTES) != 0 {
+		t.Error("unexpected routes ttl:", config.RoutesTtl)
+	}
+	if config.GroupTtl(RESPONSE_CACHE_LOOKUP) != 5*time.Second {
+		t.Error("expected lookup ttl to fall back to default")
+	}
+}
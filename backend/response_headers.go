@@ -0,0 +1,78 @@
+package main
+
+/*
+Add static headers to all responses, e.g.
+X-Frame-Options or Content-Security-Policy.
+
+The headers are added when the response is written,
+so headers set by the handlers are not overwritten.
+*/
+
+import (
+	"net/http"
+
+	"github.com/go-ini/ini"
+)
+
+// Read the headers from the [server.response_headers] section
+func getResponseHeaders(config *ini.File) map[string]string {
+	headers := make(map[string]string)
+	section := config.Section("server.response_headers")
+	for _, key := range section.Keys() {
+		headers[http.CanonicalHeaderKey(key.Name())] = key.String()
+	}
+	return headers
+}
+
+type headersResponseWriter struct {
+	http.ResponseWriter
+	headers     map[string]string
+	wroteHeader bool
+}
+
+// Add the headers not set by the handler
+func (self *headersResponseWriter) WriteHeader(status int) {
+	if !self.wroteHeader {
+		self.wroteHeader = true
+		header := self.ResponseWriter.Header()
+		for name, value := range self.headers {
+			if _, ok := header[name]; !ok {
+				header.Set(name, value)
+			}
+		}
+	}
+	self.ResponseWriter.WriteHeader(status)
+}
+
+func (self *headersResponseWriter) Write(data []byte) (int, error) {
+	if !self.wroteHeader {
+		self.WriteHeader(http.StatusOK)
+	}
+	return self.ResponseWriter.Write(data)
+}
+
+// Streamed responses are flushed to the client
+func (self *headersResponseWriter) Flush() {
+	if !self.wroteHeader {
+		self.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := self.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Wrap a handler to add the response headers
+func withResponseHeaders(
+	headers map[string]string,
+	handler http.Handler,
+) http.Handler {
+	if len(headers) == 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		handler.ServeHTTP(&headersResponseWriter{
+			ResponseWriter: res,
+			headers:        headers,
+		}, req)
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestGetResponseHeaders(t *testing.T) {
+	parsed, err := ini.Load([]byte(
+		"[server]\n" +
+			"listen_http = :7340\n" +
+			"[server.response_headers]\n" +
+			"x-frame-options = DENY\n" +
+			"Content-Security-Policy = frame-ancestors 'none'\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := getResponseHeaders(parsed)
+
+	if headers["X-Frame-Options"] != "DENY" {
+		t.Error("unexpected X-Frame-Options:", headers["X-Frame-Options"])
+	}
+	if headers["Content-Security-Policy"] != "frame-ancestors 'none'" {
+		t.Error("unexpected Content-Security-Policy:",
+			headers["Content-Security-Policy"])
+	}
+	if len(headers) != 2 {
+		t.Error("expected 2 headers, got:", headers)
+	}
+}
+
+func TestWithResponseHeaders(t *testing.T) {
+	headers := map[string]string{
+		"X-Frame-Options": "DENY",
+		"Content-Type":    "text/plain",
+	}
+	handler := withResponseHeaders(headers, http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Set("Content-Type", "application/json")
+			res.Write([]byte("{}"))
+		}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("X-Frame-Options") != "DENY" {
+		t.Error("expected configured header on response")
+	}
+	// Headers set by the handler are not replaced
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Error("unexpected Content-Type:", rec.Header().Get("Content-Type"))
+	}
+	if rec.Body.String() != "{}" {
+		t.Error("unexpected body:", rec.Body.String())
+	}
+}
+
+func TestWithResponseHeadersStatus(t *testing.T) {
+	headers := map[string]string{"X-Frame-Options": "DENY"}
+	handler := withResponseHeaders(headers, http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusNotFound)
+		}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/missing", nil)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Error("unexpected status:", rec.Code)
+	}
+	if rec.Header().Get("X-Frame-Options") != "DENY" {
+		t.Error("expected configured header on error response")
+	}
+
+	// Streamed responses can still be flushed
+	handler = withResponseHeaders(headers, http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			flusher, ok := res.(http.Flusher)
+			if !ok {
+				t.Fatal("expected response writer to be a flusher")
+			}
+			flusher.Flush()
+		}))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !rec.Flushed || rec.Header().Get("X-Frame-Options") != "DENY" {
+		t.Error("expected flushed response with configured header")
+	}
+}
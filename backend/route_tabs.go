@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Route tabs shown by the frontend for a neighbour
+const (
+	ROUTE_TAB_IMPORTED     = "imported"
+	ROUTE_TAB_FILTERED     = "filtered"
+	ROUTE_TAB_NOT_EXPORTED = "not_exported"
+)
+
+var DEFAULT_ROUTE_TABS = []string{
+	ROUTE_TAB_IMPORTED,
+	ROUTE_TAB_FILTERED,
+	ROUTE_TAB_NOT_EXPORTED,
+}
+
+// Parse a list of route tabs, an empty
+// list selects the default tabs.
+func parseRouteTabs(value string) ([]string, error) {
+	tabs := TrimmedStringList(value)
+	if len(tabs) == 0 {
+		return nil, nil
+	}
+
+	for _, tab := range tabs {
+		if !MemberOf(DEFAULT_ROUTE_TABS, tab) {
+			return nil, fmt.Errorf(
+				"unknown route tab: %s - valid tabs are: %s, %s, %s",
+				tab,
+				ROUTE_TAB_IMPORTED,
+				ROUTE_TAB_FILTERED,
+				ROUTE_TAB_NOT_EXPORTED)
+		}
+	}
+
+	return tabs, nil
+}
+
+// Get the route tabs meaningful for the source
+func (self *SourceConfig) getRouteTabs() []string {
+	if len(self.RouteTabs) == 0 {
+		return DEFAULT_ROUTE_TABS
+	}
+	return self.RouteTabs
+}
@@ -0,0 +1,80 @@
+package main
+
+/*
+Route counts per address family
+
+The sources do not distinguish between address
+families, so the AFI is derived from the prefix.
+*/
+
+import (
+	"net"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Get the address family of a network. The mask is used,
+// as IPv4-mapped IPv6 prefixes would be taken for IPv4.
+// An empty string is returned for invalid prefixes.
+func networkAfi(network string) string {
+	_, prefix, err := net.ParseCIDR(network)
+	if err != nil {
+		return ""
+	}
+	if _, bits := prefix.Mask.Size(); bits == 8*net.IPv4len {
+		return api.AFI_IPV4
+	}
+	return api.AFI_IPV6
+}
+
+// Count the imported and filtered routes
+// and their distinct prefixes per AFI.
+func countRoutesByAfi(routes *api.RoutesResponse) *api.AfiCounts {
+	counts := &api.AfiCounts{}
+	if routes == nil {
+		return counts
+	}
+
+	prefixes := make(map[string]bool)
+	for _, set := range []api.Routes{routes.Imported, routes.Filtered} {
+		for _, route := range set {
+			var count *api.AfiCount
+			switch networkAfi(route.Network) {
+			case api.AFI_IPV4:
+				count = &counts.Ipv4
+			case api.AFI_IPV6:
+				count = &counts.Ipv6
+			default:
+				continue
+			}
+
+			count.Routes++
+			if !prefixes[route.Network] {
+				prefixes[route.Network] = true
+				count.Prefixes++
+			}
+		}
+	}
+
+	return counts
+}
+
+// Get the route counts per AFI of all sources
+// and the network wide counts.
+func (self *RoutesStore) AfiCounts() (
+	map[string]*api.AfiCounts,
+	*api.AfiCounts,
+) {
+	self.RLock()
+	defer self.RUnlock()
+
+	sources := make(map[string]*api.AfiCounts, len(self.routesMap))
+	total := &api.AfiCounts{}
+	for sourceId, routes := range self.routesMap {
+		counts := countRoutesByAfi(routes)
+		sources[sourceId] = counts
+		total.Merge(counts)
+	}
+
+	return sources, total
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestCountRoutesByAfi(t *testing.T) {
+	routes := &api.RoutesResponse{
+		Imported: api.Routes{
+			&api.Route{Id: "r1", Network: "10.23.0.0/16"},
+			&api.Route{Id: "r2", Network: "10.23.0.0/16"},
+			&api.Route{Id: "r3", Network: "192.0.2.0/24"},
+			&api.Route{Id: "r4", Network: "2001:db8::/32"},
+		},
+		Filtered: api.Routes{
+			&api.Route{Id: "r5", Network: "2001:db8:23::/48"},
+			&api.Route{Id: "r6", Network: "::ffff:192.0.2.0/120"},
+			&api.Route{Id: "r7", Network: "invalid"},
+		},
+	}
+
+	counts := countRoutesByAfi(routes)
+	if counts.Ipv4.Routes != 3 || counts.Ipv4.Prefixes != 2 {
+		t.Error("Unexpected IPv4 counts:", counts.Ipv4)
+	}
+	if counts.Ipv6.Routes != 3 || counts.Ipv6.Prefixes != 3 {
+		t.Error("Unexpected IPv6 counts:", counts.Ipv6)
+	}
+}
+
+func TestRoutesStoreAfiCounts(t *testing.T) {
+	store := makeTestRoutesStore()
+	store.routesMap["rs2"] = &api.RoutesResponse{
+		Imported: api.Routes{
+			&api.Route{Id: "r1", Network: "2001:db8::/32"},
+		},
+	}
+
+	sources, total := store.AfiCounts()
+	if sources["rs2"].Ipv6.Routes != 1 || sources["rs2"].Ipv4.Routes != 0 {
+		t.Error("Unexpected counts for rs2:", sources["rs2"])
+	}
+	if total.Ipv6.Routes != sources["rs1"].Ipv6.Routes+1 {
+		t.Error("Unexpected total:", total)
+	}
+	if total.Ipv4.Routes != sources["rs1"].Ipv4.Routes {
+		t.Error("Unexpected total:", total)
+	}
+	if sources["rs1"].Ipv4.Routes == 0 {
+		t.Error("Expected IPv4 routes in rs1")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+/*
+Routes covered by aggregates
+
+More-specific routes, covered by one of a list of
+aggregate prefixes, are likely redundant announcements.
+*/
+
+import (
+	"net"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Parse a comma separated list of prefixes
+func parsePrefixList(value string) ([]*net.IPNet, error) {
+	prefixes := []*net.IPNet{}
+	for _, token := range strings.Split(value, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		_, prefix, err := net.ParseCIDR(token)
+		if err != nil {
+			return nil, &InvalidPrefixError{Prefix: token}
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// Find the aggregate covering a network. The network
+// must be more specific than the aggregate.
+func coveringAggregate(
+	network string,
+	aggregates []*net.IPNet,
+) *net.IPNet {
+	ip, prefix, err := net.ParseCIDR(network)
+	if err != nil {
+		return nil
+	}
+	ones, bits := prefix.Mask.Size()
+
+	for _, aggregate := range aggregates {
+		aggOnes, aggBits := aggregate.Mask.Size()
+		if aggBits != bits || aggOnes >= ones {
+			continue
+		}
+		if aggregate.Contains(ip) {
+			return aggregate
+		}
+	}
+	return nil
+}
+
+// Get all routes covered by an aggregate. The routes
+// are copied and flagged with the covering aggregate.
+func filterCoveredRoutes(
+	routes api.Routes,
+	aggregates []*net.IPNet,
+) api.Routes {
+	results := api.Routes{}
+	if len(aggregates) == 0 {
+		return results
+	}
+	for _, r := range routes {
+		aggregate := coveringAggregate(r.Network, aggregates)
+		if aggregate == nil {
+			continue
+		}
+		route := *r
+		route.CoveringAggregate = aggregate.String()
+		results = append(results, &route)
+	}
+	return results
+}
+
+// Get the imported and filtered routes of a source
+// covered by any of the aggregates.
+func (self *RoutesStore) CoveredRoutesAt(
+	sourceId string,
+	aggregates []*net.IPNet,
+) (api.Routes, api.Routes) {
+	self.RLock()
+	routes, ok := self.routesMap[sourceId]
+	self.RUnlock()
+
+	if !ok {
+		return api.Routes{}, api.Routes{}
+	}
+
+	imported := filterCoveredRoutes(routes.Imported, aggregates)
+	filtered := filterCoveredRoutes(routes.Filtered, aggregates)
+
+	return imported, filtered
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestCoveredRoutes(t *testing.T) {
+	aggregates, err := parsePrefixList("10.0.0.0/8, 2001:db8::/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := api.Routes{
+		&api.Route{Id: "covered", Network: "10.23.0.0/16"},
+		&api.Route{Id: "covered_v6", Network: "2001:db8:42::/48"},
+		&api.Route{Id: "aggregate", Network: "10.0.0.0/8"},
+		&api.Route{Id: "uncovered", Network: "11.23.0.0/16"},
+		&api.Route{Id: "less_specific", Network: "10.0.0.0/7"},
+		&api.Route{Id: "uncovered_v6", Network: "2001:db9::/48"},
+	}
+
+	covered := filterCoveredRoutes(routes, aggregates)
+	if len(covered) != 2 {
+		t.Fatal("Expected 2 covered routes, got:", len(covered))
+	}
+	if covered[0].Id != "covered" ||
+		covered[0].CoveringAggregate != "10.0.0.0/8" {
+		t.Error("Unexpected covered route:", covered[0])
+	}
+	if covered[1].Id != "covered_v6" ||
+		covered[1].CoveringAggregate != "2001:db8::/32" {
+		t.Error("Unexpected covered route:", covered[1])
+	}
+
+	// The original routes are not modified
+	if routes[0].CoveringAggregate != "" {
+		t.Error("Expected routes to be copied")
+	}
+}
+
+func TestCoveredRoutesAt(t *testing.T) {
+	store := makeTestRoutesStore()
+
+	aggregates, _ := parsePrefixList("42.0.0.0/8")
+	imported, filtered := store.CoveredRoutesAt("rs1", aggregates)
+	if len(imported) != 0 {
+		t.Error("Expected no imported routes, got:", len(imported))
+	}
+	if len(filtered) != 1 || filtered[0].Network != "42.23.0.0/16" {
+		t.Error("Expected filtered route 42.23.0.0/16, got:", filtered)
+	}
+
+	// Without aggregates nothing is covered
+	imported, filtered = store.CoveredRoutesAt("rs1", nil)
+	if len(imported) != 0 || len(filtered) != 0 {
+		t.Error("Expected no covered routes without aggregates")
+	}
+}
+
+func TestParsePrefixListInvalid(t *testing.T) {
+	_, err := parsePrefixList("10.0.0.0/8,foo")
+	if _, ok := err.(*InvalidPrefixError); !ok {
+		t.Error("Expected InvalidPrefixError, got:", err)
+	}
+}
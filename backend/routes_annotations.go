@@ -0,0 +1,186 @@
+package main
+
+/*
+Routes annotations
+
+Routes retrieved from a source are post-processed
+and annotated with additional information, like the
+blackhole state, bogon prefixes or the length of the AS path.
+Routes hidden by community are removed.
+*/
+
+import (
+	"net"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Collapse an IPv4-mapped IPv6 address (::ffff:192.0.2.1)
+// to its IPv4 form. Other addresses are not changed.
+func collapseIPv4MappedAddress(address string) string {
+	if !strings.HasPrefix(strings.ToLower(address), "::ffff:") {
+		return address
+	}
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return address
+	}
+	ipv4 := ip.To4()
+	if ipv4 == nil {
+		return address
+	}
+	return ipv4.String()
+}
+
+// Normalize the next hops of all routes
+func annotateIPv4MappedNextHops(routes api.Routes) {
+	for _, route := range routes {
+		route.Bgp.NextHop = collapseIPv4MappedAddress(route.Bgp.NextHop)
+	}
+}
+
+// Calculate the AS path length and flag routes
+// exceeding the threshold. A threshold of 0 disables
+// the check.
+//
+// As AS_SETs are flattened by the sources, every member
+// of a set is counted. This is consistent for all backends.
+func annotateAsPathLength(routes api.Routes, maxLength int) {
+	for _, route := range routes {
+		route.AsPathLength = len(route.Bgp.AsPath)
+		route.AsPathTooLong = maxLength > 0 && route.AsPathLength > maxLength
+	}
+}
+
+// Count the prepends of the AS path: The maximum number
+// of consecutive repetitions of an ASN.
+//
+// AS_SETs are flattened by the sources. As the members of
+// a set are distinct, only an ASN adjacent to a set with
+// the same ASN could be taken for a prepend.
+func asPathPrependCount(asPath []int) int {
+	maxCount := 0
+	count := 0
+	for i := 1; i < len(asPath); i++ {
+		if asPath[i] == asPath[i-1] {
+			count++
+		} else {
+			count = 0
+		}
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	return maxCount
+}
+
+// Annotate the prepend count of the routes
+func annotateAsPathPrepends(routes api.Routes) {
+	for _, route := range routes {
+		route.PrependCount = asPathPrependCount(route.Bgp.AsPath)
+	}
+}
+
+// Remove all routes carrying any of the hide communities
+func filterHiddenRoutes(
+	routes api.Routes,
+	communities api.Communities,
+) api.Routes {
+	if len(communities) == 0 {
+		return routes
+	}
+
+	results := make(api.Routes, 0, len(routes))
+	for _, route := range routes {
+		if routeHasAnyCommunity(route, communities) {
+			continue
+		}
+		results = append(results, route)
+	}
+	return results
+}
+
+// Add the AS path in asdot notation, if configured.
+// The AS path is always provided in asplain.
+func annotateAsPathNotation(routes api.Routes, notation string) {
+	if notation != api.ASN_NOTATION_ASDOT {
+		return
+	}
+	for _, route := range routes {
+		asPath := make([]string, 0, len(route.Bgp.AsPath))
+		for _, asn := range route.Bgp.AsPath {
+			asPath = append(asPath, api.FormatAsn(asn, notation))
+		}
+		route.Bgp.AsPathAsdot = asPath
+	}
+}
+
+// Copy the routes, the routes of a source response
+// may be shared through the caches of the source.
+func copyRoutes(routes api.Routes) api.Routes {
+	if routes == nil {
+		return nil
+	}
+	results := make(api.Routes, 0, len(routes))
+	for _, route := range routes {
+		r := *route
+		results = append(results, &r)
+	}
+	return results
+}
+
+// Annotate all routes in a routes response from a source.
+// The response is not modified, the annotated routes
+// are returned in a copy of the response.
+func annotateRoutesResponse(
+	source *SourceConfig,
+	response *api.RoutesResponse,
+) *api.RoutesResponse {
+	if source == nil || response == nil {
+		return response
+	}
+
+	nextHops := source.getBlackholeNextHops()
+	communities := source.getBlackholeCommunities()
+	maxAsPathLength := AliceConfig.Server.MaxAsPathLength
+	collapseNextHops := AliceConfig.Server.CollapseIPv4MappedNextHops
+
+	annotated := *response
+	response = &annotated
+
+	// Hidden routes are excluded from all responses
+	hidden := AliceConfig.HiddenRoutes.Communities
+	response.Imported = filterHiddenRoutes(response.Imported, hidden)
+	response.Filtered = filterHiddenRoutes(response.Filtered, hidden)
+	response.NotExported = filterHiddenRoutes(response.NotExported, hidden)
+
+	// Same for routes from hidden neighbours of the source
+	response.Imported = filterHiddenNeighbourRoutes(
+		response.Imported, source.HiddenNeighbours)
+	response.Filtered = filterHiddenNeighbourRoutes(
+		response.Filtered, source.HiddenNeighbours)
+	response.NotExported = filterHiddenNeighbourRoutes(
+		response.NotExported, source.HiddenNeighbours)
+
+	response.Imported = copyRoutes(response.Imported)
+	response.Filtered = copyRoutes(response.Filtered)
+	response.NotExported = copyRoutes(response.NotExported)
+
+	for _, routes := range []api.Routes{
+		response.Imported,
+		response.Filtered,
+		response.NotExported,
+	} {
+		if collapseNextHops {
+			annotateIPv4MappedNextHops(routes)
+		}
+		annotateBlackholedRoutes(routes, nextHops, communities)
+		annotateBogonRoutes(routes, AliceConfig.Bogons.Prefixes)
+		annotateAsPathLength(routes, maxAsPathLength)
+		annotateAsPathPrepends(routes)
+		annotateAsPathNotation(routes, AliceConfig.Server.AsnNotation)
+	}
+
+	return response
+}
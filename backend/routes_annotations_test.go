@@ -0,0 +1,284 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func makeAsPathTestRoutes() api.Routes {
+	return api.Routes{
+		&api.Route{
+			Id: "short",
+			Bgp: api.BgpInfo{
+				AsPath: []int{2342},
+			},
+		},
+		&api.Route{
+			Id: "medium",
+			Bgp: api.BgpInfo{
+				AsPath: []int{2342, 23, 42},
+			},
+		},
+		&api.Route{
+			Id: "long",
+			Bgp: api.BgpInfo{
+				AsPath: []int{2342, 23, 42, 42, 42, 42, 42},
+			},
+		},
+	}
+}
+
+func TestAnnotateAsPathLength(t *testing.T) {
+	routes := makeAsPathTestRoutes()
+	annotateAsPathLength(routes, 5)
+
+	expected := []struct {
+		length  int
+		tooLong bool
+	}{
+		{1, false},
+		{3, false},
+		{7, true},
+	}
+
+	for i, e := range expected {
+		if routes[i].AsPathLength != e.length {
+			t.Error("Expected length", e.length, "got:", routes[i].AsPathLength)
+		}
+		if routes[i].AsPathTooLong != e.tooLong {
+			t.Error("Unexpected too long flag for route:", routes[i].Id)
+		}
+	}
+
+	// A threshold of 0 disables the check
+	routes = makeAsPathTestRoutes()
+	annotateAsPathLength(routes, 0)
+	if routes[2].AsPathTooLong {
+		t.Error("Threshold check should be disabled")
+	}
+}
+
+func TestAsPathPrependCount(t *testing.T) {
+	tests := []struct {
+		asPath   []int
+		expected int
+	}{
+		{[]int{}, 0},
+		{[]int{2342}, 0},
+		{[]int{2342, 23, 42}, 0},
+		{[]int{2342, 23, 42, 42, 42}, 2},
+		{[]int{2342, 2342, 23, 42, 42, 42, 42}, 3},
+		{[]int{2342, 42, 23, 42}, 0},
+	}
+
+	for _, test := range tests {
+		count := asPathPrependCount(test.asPath)
+		if count != test.expected {
+			t.Error(test.asPath, "- expected", test.expected, "got:", count)
+		}
+	}
+}
+
+func TestApiQueryFilterPrependCount(t *testing.T) {
+	routes := makeAsPathTestRoutes()
+	annotateAsPathPrepends(routes)
+
+	u, _ := url.Parse("http://alice/api?min_prepend_count=1")
+	filtered := apiQueryFilterPrependCount(&http.Request{URL: u}, routes)
+	if len(filtered) != 1 || filtered[0].Id != "long" {
+		t.Error("Expected only the prepended route, got:", filtered)
+	}
+
+	u, _ = url.Parse("http://alice/api?max_prepend_count=0")
+	filtered = apiQueryFilterPrependCount(&http.Request{URL: u}, routes)
+	if len(filtered) != 2 {
+		t.Error("Expected 2 routes without prepends, got:", len(filtered))
+	}
+
+	// Sort by prepend count
+	req, _ := http.NewRequest("GET", "/?sort=prepend_count:desc", nil)
+	if err := apiQuerySortRoutes(req, routes, ""); err != nil {
+		t.Fatal(err)
+	}
+	if routes[0].Id != "long" || routes[0].PrependCount != 4 {
+		t.Error("Unexpected first route:", routes[0].Id)
+	}
+}
+
+func TestApiQueryFilterAsPathLength(t *testing.T) {
+	routes := makeAsPathTestRoutes()
+
+	expected := map[string][]string{
+		"":                                    {"short", "medium", "long"},
+		"min_as_path_len=2":                   {"medium", "long"},
+		"max_as_path_len=3":                   {"short", "medium"},
+		"min_as_path_len=2&max_as_path_len=3": {"medium"},
+		"min_as_path_len=10":                  {},
+	}
+
+	for query, ids := range expected {
+		u, _ := url.Parse("http://alice/api?" + query)
+		filtered := apiQueryFilterAsPathLength(&http.Request{URL: u}, routes)
+		if len(filtered) != len(ids) {
+			t.Error(query, "- expected", ids, "got:", len(filtered), "routes")
+			continue
+		}
+		for i, id := range ids {
+			if filtered[i].Id != id {
+				t.Error(query, "- expected", id, "got:", filtered[i].Id)
+			}
+		}
+	}
+}
+
+func TestCollapseIPv4MappedAddress(t *testing.T) {
+	expected := map[string]string{
+		"::ffff:192.0.2.1": "192.0.2.1",
+		"::FFFF:192.0.2.1": "192.0.2.1",
+		"192.0.2.1":        "192.0.2.1",
+		"2001:db8::1":      "2001:db8::1",
+		"::ffff:foo":       "::ffff:foo",
+		"":                 "",
+	}
+	for address, result := range expected {
+		if collapseIPv4MappedAddress(address) != result {
+			t.Error("Expected", address, "to collapse to", result,
+				"got:", collapseIPv4MappedAddress(address))
+		}
+	}
+
+	routes := api.Routes{
+		&api.Route{
+			Bgp: api.BgpInfo{NextHop: "::ffff:192.0.2.1"},
+		},
+	}
+	annotateIPv4MappedNextHops(routes)
+	if routes[0].Bgp.NextHop != "192.0.2.1" {
+		t.Error("Unexpected next hop:", routes[0].Bgp.NextHop)
+	}
+}
+
+func TestHiddenRoutes(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	AliceConfig.HiddenRoutes.Communities = api.Communities{
+		api.Community{9033, 65535, 1},
+	}
+	startTestNeighboursStore()
+
+	hidden := &api.Route{
+		Id:          "hidden",
+		NeighbourId: "ID2233_AS2342",
+		Network:     "10.23.0.0/16",
+		Bgp: api.BgpInfo{
+			LargeCommunities: api.Communities{
+				api.Community{9033, 65535, 1},
+			},
+		},
+	}
+	visible := &api.Route{
+		Id:          "visible",
+		NeighbourId: "ID2233_AS2342",
+		Network:     "10.42.0.0/16",
+	}
+
+	source := &SourceConfig{Id: "rs1", Name: "rs1.test"}
+
+	// Routes response
+	response := &api.RoutesResponse{
+		Imported:    api.Routes{hidden, visible},
+		Filtered:    api.Routes{hidden},
+		NotExported: api.Routes{hidden, visible},
+	}
+	response = annotateRoutesResponse(source, response)
+
+	if len(response.Imported) != 1 || response.Imported[0].Id != "visible" {
+		t.Error("Expected hidden route to be removed from imported routes")
+	}
+	if len(response.Filtered) != 0 {
+		t.Error("Expected hidden route to be removed from filtered routes")
+	}
+	if len(response.NotExported) != 1 {
+		t.Error("Expected hidden route to be removed from not exported routes")
+	}
+
+	// All routes and lookup, served from the store
+	allRoutes := &api.RoutesResponse{
+		Imported: api.Routes{hidden, visible},
+	}
+	allRoutes = annotateRoutesResponse(source, allRoutes)
+
+	store := &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{"rs1": allRoutes},
+		statusMap: map[string]StoreStatus{},
+		configMap: map[string]*SourceConfig{"rs1": source},
+	}
+
+	if len(store.ImportedRoutesAt("rs1")) != 1 {
+		t.Error("Expected hidden route to be removed from all routes")
+	}
+
+	results := store.LookupPrefix("10.23.0.0/16")
+	if len(results) != 0 {
+		t.Error("Expected hidden route not to be found in lookup")
+	}
+	results = store.LookupPrefix("10.42.0.0/16")
+	if len(results) != 1 {
+		t.Error("Expected visible route to be found in lookup")
+	}
+}
+
+func TestAnnotateAsPathNotation(t *testing.T) {
+	routes := api.Routes{
+		&api.Route{
+			Bgp: api.BgpInfo{AsPath: []int{2342, 65537}},
+		},
+	}
+
+	annotateAsPathNotation(routes, api.ASN_NOTATION_ASPLAIN)
+	if routes[0].Bgp.AsPathAsdot != nil {
+		t.Error("Expected no asdot path in asplain notation")
+	}
+
+	annotateAsPathNotation(routes, api.ASN_NOTATION_ASDOT)
+	asPath := routes[0].Bgp.AsPathAsdot
+	if len(asPath) != 2 || asPath[0] != "2342" || asPath[1] != "1.1" {
+		t.Error("Unexpected asdot path:", asPath)
+	}
+	if routes[0].Bgp.AsPath[1] != 65537 {
+		t.Error("Expected asplain path to be unchanged")
+	}
+}
+
+func TestAnnotateRoutesResponseCopies(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Server: ServerConfig{MaxAsPathLength: 1},
+	}
+	source := &SourceConfig{Id: "rs1"}
+
+	// A response shared through the cache of a source
+	cached := &api.RoutesResponse{
+		Imported: api.Routes{
+			&api.Route{Id: "r1",
+				Bgp: api.BgpInfo{AsPath: []int{2342, 23, 42}}},
+		},
+	}
+
+	response := annotateRoutesResponse(source, cached)
+	if !response.Imported[0].AsPathTooLong ||
+		response.Imported[0].AsPathLength != 3 {
+		t.Error("Expected annotated route, got:", response.Imported[0])
+	}
+
+	if cached.Imported[0].AsPathTooLong ||
+		cached.Imported[0].AsPathLength != 0 {
+		t.Error("Expected cached route not to be modified")
+	}
+	if response == cached || response.Imported[0] == cached.Imported[0] {
+		t.Error("Expected a copy of the response")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+/*
+Detect duplicate paths: Misconfigured peers may send the
+same path for many prefixes. The number of routes sharing
+an (AS path, next hop) tuple with a previous route of the
+neighbour is reported as duplicate paths.
+*/
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Helper: Identify a path by AS path and next hop
+func routePathKey(route *api.Route) string {
+	return fmt.Sprintf("%v@%s", route.Bgp.AsPath, route.Bgp.NextHop)
+}
+
+// Count the routes with an already seen path
+func countDuplicatePaths(routes api.Routes) int {
+	seen := make(map[string]bool, len(routes))
+	duplicates := 0
+	for _, route := range routes {
+		key := routePathKey(route)
+		if seen[key] {
+			duplicates++
+			continue
+		}
+		seen[key] = true
+	}
+	return duplicates
+}
+
+// Get the duplicate paths of all neighbours of a source,
+// having at least minDuplicates duplicate paths.
+// The neighbours with most duplicates come first.
+func (self *RoutesStore) DuplicatePathsAt(
+	sourceId string,
+	minDuplicates int,
+) []*api.NeighbourDuplicatePaths {
+	self.RLock()
+	response, ok := self.routesMap[sourceId]
+	self.RUnlock()
+
+	results := []*api.NeighbourDuplicatePaths{}
+	if !ok {
+		return results
+	}
+
+	// Group received routes by neighbour
+	received := make(map[string]api.Routes)
+	for _, route := range response.Imported {
+		received[route.NeighbourId] = append(received[route.NeighbourId], route)
+	}
+	for _, route := range response.Filtered {
+		received[route.NeighbourId] = append(received[route.NeighbourId], route)
+	}
+
+	for neighbourId, routes := range received {
+		duplicates := countDuplicatePaths(routes)
+		if duplicates < minDuplicates {
+			continue
+		}
+		results = append(results, &api.NeighbourDuplicatePaths{
+			NeighbourId:    neighbourId,
+			Routes:         len(routes),
+			DuplicatePaths: duplicates,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].DuplicatePaths != results[j].DuplicatePaths {
+			return results[i].DuplicatePaths > results[j].DuplicatePaths
+		}
+		return results[i].NeighbourId < results[j].NeighbourId
+	})
+
+	return results
+}
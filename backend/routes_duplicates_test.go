@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestDuplicatePathsAt(t *testing.T) {
+	path := func(id, neighbourId, nextHop string, asPath ...int) *api.Route {
+		return &api.Route{
+			Id:          id,
+			NeighbourId: neighbourId,
+			Bgp: api.BgpInfo{
+				AsPath:  asPath,
+				NextHop: nextHop,
+			},
+		}
+	}
+
+	response := &api.RoutesResponse{
+		Imported: api.Routes{
+			// Many duplicates
+			path("1", "dup", "10.0.0.1", 2342, 23),
+			path("2", "dup", "10.0.0.1", 2342, 23),
+			path("3", "dup", "10.0.0.1", 2342, 23),
+			path("4", "dup", "10.0.0.1", 2342, 42),
+			path("5", "dup", "10.0.0.2", 2342, 42),
+
+			// No duplicates
+			path("6", "clean", "10.0.1.1", 4242),
+			path("7", "clean", "10.0.1.1", 4242, 23),
+			path("8", "clean", "10.0.1.2", 4242),
+		},
+		Filtered: api.Routes{
+			path("9", "dup", "10.0.0.2", 2342, 42),
+		},
+	}
+
+	store := &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{"rs1": response},
+	}
+
+	results := store.DuplicatePathsAt("rs1", 1)
+	if len(results) != 1 {
+		t.Fatal("Expected only the neighbour with duplicates, got:", len(results))
+	}
+	if results[0].NeighbourId != "dup" ||
+		results[0].Routes != 6 ||
+		results[0].DuplicatePaths != 3 {
+		t.Error("Unexpected duplicates:", results[0])
+	}
+
+	// Include all neighbours
+	results = store.DuplicatePathsAt("rs1", 0)
+	if len(results) != 2 {
+		t.Fatal("Expected all neighbours, got:", len(results))
+	}
+	if results[1].NeighbourId != "clean" || results[1].DuplicatePaths != 0 {
+		t.Error("Expected no duplicates for clean neighbour:", results[1])
+	}
+}
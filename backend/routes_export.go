@@ -0,0 +1,223 @@
+package main
+
+/*
+Scheduled export of neighbour routes
+
+The routes received from the configured neighbours are
+periodically written from the routes store to files
+in the export directory. The filename is a template
+with the placeholders {source}, {neighbour} and {timestamp}.
+
+Neighbours are configured as <source id>:<neighbour id>,
+all neighbours of a source are exported with <source id>:*
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+const (
+	EXPORT_FORMAT_JSON   = "json"
+	EXPORT_FORMAT_NDJSON = "ndjson"
+)
+
+const EXPORT_TIMESTAMP_FORMAT = "20060102T150405Z"
+
+var REGEX_EXPORT_UNSAFE_CHARS = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+type exportTarget struct {
+	SourceId    string
+	NeighbourId string
+}
+
+// Parse the configured export neighbours
+func parseExportTargets(neighbours []string) ([]exportTarget, error) {
+	targets := []exportTarget{}
+	for _, n := range neighbours {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+		tokens := strings.SplitN(n, ":", 2)
+		if len(tokens) != 2 || tokens[0] == "" || tokens[1] == "" {
+			return nil, fmt.Errorf("invalid export neighbour: %s", n)
+		}
+		targets = append(targets, exportTarget{
+			SourceId:    tokens[0],
+			NeighbourId: tokens[1],
+		})
+	}
+	return targets, nil
+}
+
+// Make the filename for an export from the template
+func exportFilename(
+	template string,
+	sourceId string,
+	neighbourId string,
+	now time.Time,
+) string {
+	filename := strings.NewReplacer(
+		"{source}", REGEX_EXPORT_UNSAFE_CHARS.ReplaceAllString(sourceId, "_"),
+		"{neighbour}", REGEX_EXPORT_UNSAFE_CHARS.ReplaceAllString(neighbourId, "_"),
+		"{timestamp}", now.UTC().Format(EXPORT_TIMESTAMP_FORMAT),
+	).Replace(template)
+
+	return filepath.Base(filename)
+}
+
+// Write routes as json array or as one json
+// object per line
+func writeRoutesExport(w io.Writer, format string, routes api.Routes) error {
+	encoder := json.NewEncoder(w)
+	if format != EXPORT_FORMAT_NDJSON {
+		return encoder.Encode(routes)
+	}
+
+	for _, route := range routes {
+		if err := encoder.Encode(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write the file atomically, by writing to a temporary
+// file first.
+func writeExportFile(
+	path string,
+	format string,
+	routes api.Routes,
+) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".export-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeRoutesExport(tmp, format, routes); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get the ids of all neighbours with routes in the store
+func (self *RoutesStore) NeighbourIdsAt(sourceId string) []string {
+	self.RLock()
+	routes, ok := self.routesMap[sourceId]
+	self.RUnlock()
+	if !ok {
+		return []string{}
+	}
+
+	ids := map[string]bool{}
+	for _, route := range routes.Imported {
+		ids[route.NeighbourId] = true
+	}
+	for _, route := range routes.Filtered {
+		ids[route.NeighbourId] = true
+	}
+
+	result := make([]string, 0, len(ids))
+	for id, _ := range ids {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// Export the routes of all targets, returns the
+// written files.
+func exportRoutes(
+	store *RoutesStore,
+	config ExportConfig,
+	targets []exportTarget,
+	now time.Time,
+) ([]string, error) {
+	files := []string{}
+	for _, target := range targets {
+		neighbourIds := []string{target.NeighbourId}
+		if target.NeighbourId == "*" {
+			neighbourIds = store.NeighbourIdsAt(target.SourceId)
+		}
+
+		for _, neighbourId := range neighbourIds {
+			received, _ := store.NeighbourRoutesAt(target.SourceId, neighbourId)
+			sort.Sort(received)
+
+			filename := exportFilename(
+				config.Filename, target.SourceId, neighbourId, now)
+			path := filepath.Join(config.Directory, filename)
+
+			err := writeExportFile(path, config.Format, received)
+			if err != nil {
+				return files, err
+			}
+			files = append(files, path)
+		}
+	}
+
+	return files, nil
+}
+
+// Periodically export routes, until stopped
+func exportRoutesLoop(
+	store *RoutesStore,
+	config ExportConfig,
+	targets []exportTarget,
+	stop chan bool,
+) {
+	ticker := time.NewTicker(time.Duration(config.Interval) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			files, err := exportRoutes(store, config, targets, time.Now())
+			if err != nil {
+				log.Println("Error while exporting routes:", err)
+			}
+			log.Println("Exported routes to", len(files), "file(s)")
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Start the scheduled export of routes, if configured
+func StartRoutesExport(config *Config, store *RoutesStore) error {
+	export := config.Export
+	if !export.Enabled {
+		return nil
+	}
+
+	targets, err := parseExportTargets(export.Neighbours)
+	if err != nil {
+		return err
+	}
+
+	log.Println("Exporting routes to", export.Directory,
+		"every", export.Interval, "minute(s)")
+
+	go exportRoutesLoop(store, export, targets, nil)
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestParseExportTargets(t *testing.T) {
+	targets, err := parseExportTargets([]string{"rs1:ID163_AS31078", " rs2:* "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 ||
+		targets[0].SourceId != "rs1" ||
+		targets[0].NeighbourId != "ID163_AS31078" ||
+		targets[1].SourceId != "rs2" ||
+		targets[1].NeighbourId != "*" {
+		t.Error("Unexpected targets:", targets)
+	}
+
+	if _, err := parseExportTargets([]string{"rs1"}); err == nil {
+		t.Error("Expected error for invalid target")
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	now := time.Date(2018, 10, 23, 13, 37, 42, 0, time.UTC)
+	filename := exportFilename(
+		"{source}_{neighbour}_{timestamp}.json", "rs1", "../ID163", now)
+	if filename != "rs1_.._ID163_20181023T133742Z.json" {
+		t.Error("Unexpected filename:", filename)
+	}
+}
+
+func TestExportRoutes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alice-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := makeTestRoutesStore()
+	config := ExportConfig{
+		Directory: dir,
+		Format:    EXPORT_FORMAT_NDJSON,
+		Filename:  "{source}_{neighbour}_{timestamp}.ndjson",
+	}
+	targets := []exportTarget{{SourceId: "rs1", NeighbourId: "ID163_AS31078"}}
+	now := time.Date(2018, 10, 23, 13, 37, 42, 0, time.UTC)
+
+	files, err := exportRoutes(store, config, targets, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := filepath.Join(dir, "rs1_ID163_AS31078_20181023T133742Z.ndjson")
+	if len(files) != 1 || files[0] != expected {
+		t.Fatal("Unexpected files:", files)
+	}
+
+	f, err := os.Open(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		route := api.Route{}
+		if err := json.Unmarshal(scanner.Bytes(), &route); err != nil {
+			t.Fatal(err)
+		}
+		if route.NeighbourId != "ID163_AS31078" {
+			t.Error("Unexpected neighbour:", route.NeighbourId)
+		}
+		count++
+	}
+	if count != 8 {
+		t.Error("Expected 8 routes, got:", count)
+	}
+}
+
+func TestExportRoutesAllNeighbours(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alice-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := makeTestRoutesStore()
+	config := ExportConfig{
+		Directory: dir,
+		Format:    EXPORT_FORMAT_JSON,
+		Filename:  "{neighbour}.json",
+	}
+	targets := []exportTarget{{SourceId: "rs1", NeighbourId: "*"}}
+
+	files, err := exportRoutes(store, config, targets, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatal("Expected a file per neighbour, got:", files)
+	}
+
+	payload, err := ioutil.ReadFile(filepath.Join(dir, "ID7254_AS31334.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := api.Routes{}
+	if err := json.Unmarshal(payload, &routes); err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Network != "42.23.0.0/16" {
+		t.Error("Unexpected routes:", routes)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+/*
+Limit the number of sources queried in a lookup.
+
+With max_lookup_sources set, only the most relevant
+sources are queried. The relevance is defined by
+the lookup_sources_order policy:
+
+  config - the order of the sources in the config (default)
+  routes - the sources with the most routes first
+*/
+
+import (
+	"sort"
+)
+
+const (
+	LOOKUP_SOURCES_ORDER_CONFIG = "config"
+	LOOKUP_SOURCES_ORDER_ROUTES = "routes"
+)
+
+// Get the ids of the sources to query in a lookup and
+// the ids of the sources skipped due to the limit.
+func (self *RoutesStore) lookupSourceIds() ([]string, []string) {
+	self.RLock()
+	ids := make([]string, 0, len(self.routesMap))
+	for id, _ := range self.routesMap {
+		ids = append(ids, id)
+	}
+
+	switch self.lookupSourcesOrder {
+	case LOOKUP_SOURCES_ORDER_ROUTES:
+		counts := make(map[string]int, len(ids))
+		for _, id := range ids {
+			counts[id] = countRoutes(self.routesMap[id])
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if counts[ids[i]] == counts[ids[j]] {
+				return ids[i] < ids[j]
+			}
+			return counts[ids[i]] > counts[ids[j]]
+		})
+	default:
+		order := make(map[string]int, len(ids))
+		for _, id := range ids {
+			if source, ok := self.configMap[id]; ok {
+				order[id] = source.Order
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if order[ids[i]] == order[ids[j]] {
+				return ids[i] < ids[j]
+			}
+			return order[ids[i]] < order[ids[j]]
+		})
+	}
+	self.RUnlock()
+
+	if self.maxLookupSources <= 0 || len(ids) <= self.maxLookupSources {
+		return ids, []string{}
+	}
+
+	skipped := ids[self.maxLookupSources:]
+	sort.Strings(skipped)
+
+	return ids[:self.maxLookupSources], skipped
+}
+
+// Get the ids of the sources not queried in lookups
+func (self *RoutesStore) SkippedLookupSources() []string {
+	_, skipped := self.lookupSourceIds()
+	return skipped
+}
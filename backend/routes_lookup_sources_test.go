@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func makeTestLookupSourcesStore(max int, order string) *RoutesStore {
+	routes := loadTestRoutesResponse()
+	store := &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{
+			"rs1": &api.RoutesResponse{},
+			"rs2": routes,
+			"rs3": &api.RoutesResponse{Imported: routes.Imported[:1]},
+		},
+		statusMap: make(map[string]StoreStatus),
+		configMap: map[string]*SourceConfig{
+			"rs1": &SourceConfig{Id: "rs1", Order: 0},
+			"rs2": &SourceConfig{Id: "rs2", Order: 1},
+			"rs3": &SourceConfig{Id: "rs3", Order: 2},
+		},
+		maxLookupSources:   max,
+		lookupSourcesOrder: order,
+	}
+	return store
+}
+
+func TestLookupSourceIds(t *testing.T) {
+	store := makeTestLookupSourcesStore(0, LOOKUP_SOURCES_ORDER_CONFIG)
+	ids, skipped := store.lookupSourceIds()
+	if len(ids) != 3 || len(skipped) != 0 {
+		t.Error("expected all sources without a limit, got:", ids, skipped)
+	}
+
+	store = makeTestLookupSourcesStore(2, LOOKUP_SOURCES_ORDER_CONFIG)
+	ids, skipped = store.lookupSourceIds()
+	if !reflect.DeepEqual(ids, []string{"rs1", "rs2"}) {
+		t.Error("unexpected sources:", ids)
+	}
+	if !reflect.DeepEqual(skipped, []string{"rs3"}) {
+		t.Error("unexpected skipped sources:", skipped)
+	}
+
+	store = makeTestLookupSourcesStore(2, LOOKUP_SOURCES_ORDER_ROUTES)
+	ids, skipped = store.lookupSourceIds()
+	if !reflect.DeepEqual(ids, []string{"rs2", "rs3"}) {
+		t.Error("expected sources with most routes, got:", ids)
+	}
+	if !reflect.DeepEqual(skipped, []string{"rs1"}) {
+		t.Error("unexpected skipped sources:", skipped)
+	}
+}
+
+func TestLookupPrefixMaxSources(t *testing.T) {
+	defer saveTestGlobals()()
+	startTestNeighboursStore()
+
+	// Without a limit, the prefix is found in rs2 and rs3
+	store := makeTestLookupSourcesStore(0, LOOKUP_SOURCES_ORDER_CONFIG)
+	prefix := store.routesMap["rs3"].Imported[0].Network
+	results := store.LookupPrefix(prefix)
+	sources := map[string]bool{}
+	for _, r := range results {
+		sources[r.Routeserver.Id] = true
+	}
+	if !sources["rs2"] || !sources["rs3"] {
+		t.Fatal("expected results from rs2 and rs3, got:", sources)
+	}
+
+	// The limit excludes rs3
+	store = makeTestLookupSourcesStore(2, LOOKUP_SOURCES_ORDER_CONFIG)
+	results = store.LookupPrefix(prefix)
+	for _, r := range results {
+		if r.Routeserver.Id == "rs3" {
+			t.Error("expected rs3 to be skipped")
+		}
+	}
+	if len(results) == 0 {
+		t.Error("expected results from rs2")
+	}
+	if !reflect.DeepEqual(store.SkippedLookupSources(), []string{"rs3"}) {
+		t.Error("unexpected skipped sources:", store.SkippedLookupSources())
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+/*
+Multiple origin AS (MOAS) prefixes
+
+A prefix originated by different ASNs on different paths
+can be a sign of a hijack or a misconfiguration. The
+routes of the store are checked for prefixes with more
+than one distinct origin ASN.
+*/
+
+import (
+	"sort"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Collect the origins of the prefixes in the
+// imported and filtered routes of a source.
+func collectPrefixOrigins(
+	prefixes map[string]*api.MoasPrefix,
+	sourceId string,
+	response *api.RoutesResponse,
+) {
+	for _, routes := range []api.Routes{
+		response.Imported,
+		response.Filtered,
+	} {
+		for _, route := range routes {
+			origin := routeOriginAsn(route)
+			if origin == 0 {
+				continue
+			}
+			prefix, ok := prefixes[route.Network]
+			if !ok {
+				prefix = &api.MoasPrefix{
+					Network: route.Network,
+					Origins: []int{},
+					Sources: []string{},
+				}
+				prefixes[route.Network] = prefix
+			}
+			if !MemberOfInt(prefix.Origins, origin) {
+				prefix.Origins = append(prefix.Origins, origin)
+			}
+			if !MemberOf(prefix.Sources, sourceId) {
+				prefix.Sources = append(prefix.Sources, sourceId)
+			}
+		}
+	}
+}
+
+// Get the prefixes with multiple origin ASNs, sorted
+// by network. The origins are compared within the
+// source or, without a source id, across all sources.
+func (self *RoutesStore) MoasPrefixes(sourceId string) []*api.MoasPrefix {
+	prefixes := make(map[string]*api.MoasPrefix)
+
+	self.RLock()
+	for id, response := range self.routesMap {
+		if sourceId != "" && id != sourceId {
+			continue
+		}
+		collectPrefixOrigins(prefixes, id, response)
+	}
+	self.RUnlock()
+
+	results := []*api.MoasPrefix{}
+	for _, prefix := range prefixes {
+		if len(prefix.Origins) < 2 {
+			continue
+		}
+		sort.Ints(prefix.Origins)
+		sort.Strings(prefix.Sources)
+		results = append(results, prefix)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Network < results[j].Network
+	})
+
+	return results
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestRoutesStoreMoasPrefixes(t *testing.T) {
+	store := &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{
+			"rs1": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Network: "10.23.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{2342, 64512}}},
+					&api.Route{Network: "10.42.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{2342, 64512}}},
+					&api.Route{Network: "10.42.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{23, 64512}}},
+				},
+				Filtered: api.Routes{
+					&api.Route{Network: "10.23.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{23, 64666}}},
+				},
+			},
+			"rs2": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Network: "10.42.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{42, 64600}}},
+					&api.Route{Network: "2001:db8::/32",
+						Bgp: api.BgpInfo{AsPath: []int{42, 64512}}},
+				},
+			},
+		},
+	}
+
+	// Instance wide: 10.23.0.0/16 differs within rs1,
+	// 10.42.0.0/16 differs across rs1 and rs2.
+	prefixes := store.MoasPrefixes("")
+	if len(prefixes) != 2 {
+		t.Fatal("Expected 2 MOAS prefixes, got:", len(prefixes))
+	}
+	if prefixes[0].Network != "10.23.0.0/16" ||
+		!reflect.DeepEqual(prefixes[0].Origins, []int{64512, 64666}) ||
+		!reflect.DeepEqual(prefixes[0].Sources, []string{"rs1"}) {
+		t.Error("Unexpected MOAS prefix:", prefixes[0])
+	}
+	if prefixes[1].Network != "10.42.0.0/16" ||
+		!reflect.DeepEqual(prefixes[1].Origins, []int{64512, 64600}) ||
+		!reflect.DeepEqual(prefixes[1].Sources, []string{"rs1", "rs2"}) {
+		t.Error("Unexpected MOAS prefix:", prefixes[1])
+	}
+
+	// Per source, the single origin prefixes are ignored
+	prefixes = store.MoasPrefixes("rs1")
+	if len(prefixes) != 1 || prefixes[0].Network != "10.23.0.0/16" {
+		t.Error("Expected only 10.23.0.0/16 for rs1, got:", prefixes)
+	}
+	if len(store.MoasPrefixes("rs2")) != 0 {
+		t.Error("Expected no MOAS prefixes for rs2")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Parse a next hop query: Either an address
+// or a network in CIDR notation.
+func parseNextHopQuery(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, &InvalidPrefixError{Prefix: value}
+		}
+		return network, nil
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, &InvalidPrefixError{Prefix: value}
+	}
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+	return &net.IPNet{
+		IP:   ip,
+		Mask: net.CIDRMask(bits, bits),
+	}, nil
+}
+
+// Check if the gateway or the next hop of
+// the route is within the network.
+func routeNextHopIn(route *api.Route, network *net.IPNet) bool {
+	for _, address := range []string{route.Gateway, route.Bgp.NextHop} {
+		ip := net.ParseIP(address)
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the sources with routes via the next hop and
+// the number of imported and filtered routes,
+// sorted by source id.
+func (self *RoutesStore) NextHopSources(
+	network *net.IPNet,
+) []*api.NextHopSource {
+	results := []*api.NextHopSource{}
+
+	self.RLock()
+	for sourceId, response := range self.routesMap {
+		usage := &api.NextHopSource{
+			SourceId: sourceId,
+		}
+		if source, ok := self.configMap[sourceId]; ok {
+			usage.SourceName = source.Name
+		}
+
+		for _, route := range response.Imported {
+			if routeNextHopIn(route, network) {
+				usage.Imported++
+			}
+		}
+		for _, route := range response.Filtered {
+			if routeNextHopIn(route, network) {
+				usage.Filtered++
+			}
+		}
+
+		usage.Routes = usage.Imported + usage.Filtered
+		if usage.Routes > 0 {
+			results = append(results, usage)
+		}
+	}
+	self.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].SourceId < results[j].SourceId
+	})
+
+	return results
+}
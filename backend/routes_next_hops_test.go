@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestParseNextHopQuery(t *testing.T) {
+	for value, expected := range map[string]string{
+		"192.0.2.1":     "192.0.2.1/32",
+		"192.0.2.0/24":  "192.0.2.0/24",
+		"2001:db8::1":   "2001:db8::1/128",
+		"2001:db8::/64": "2001:db8::/64",
+	} {
+		network, err := parseNextHopQuery(value)
+		if err != nil {
+			t.Error(value, err)
+			continue
+		}
+		if network.String() != expected {
+			t.Error("expected", expected, "got:", network)
+		}
+	}
+
+	if _, err := parseNextHopQuery("foo"); err == nil {
+		t.Error("expected invalid next hop to be rejected")
+	}
+}
+
+func TestRoutesStoreNextHopSources(t *testing.T) {
+	store := &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{
+			"rs1": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Network: "10.23.0.0/16", Gateway: "192.0.2.1"},
+					&api.Route{Network: "10.42.0.0/16", Gateway: "192.0.2.1"},
+					&api.Route{Network: "10.66.0.0/16", Gateway: "192.0.2.2"},
+				},
+				Filtered: api.Routes{
+					&api.Route{Network: "10.99.0.0/16", Gateway: "192.0.2.1"},
+				},
+			},
+			"rs2": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Network: "10.23.0.0/16",
+						Bgp: api.BgpInfo{NextHop: "192.0.2.1"}},
+					&api.Route{Network: "2001:db8::/32",
+						Gateway: "2001:db8::1"},
+				},
+			},
+			"rs3": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Network: "10.23.0.0/16", Gateway: "198.51.100.1"},
+				},
+			},
+		},
+		configMap: map[string]*SourceConfig{
+			"rs1": &SourceConfig{Id: "rs1", Name: "rs1.example.com"},
+		},
+	}
+
+	network, _ := parseNextHopQuery("192.0.2.1")
+	sources := store.NextHopSources(network)
+	if len(sources) != 2 {
+		t.Fatal("expected 2 sources, got:", len(sources))
+	}
+	if sources[0].SourceId != "rs1" || sources[0].SourceName != "rs1.example.com" ||
+		sources[0].Imported != 2 || sources[0].Filtered != 1 ||
+		sources[0].Routes != 3 {
+		t.Error("unexpected next hop usage:", sources[0])
+	}
+	if sources[1].SourceId != "rs2" || sources[1].Routes != 1 {
+		t.Error("unexpected next hop usage:", sources[1])
+	}
+
+	// Networks include all next hops within
+	network, _ = parseNextHopQuery("192.0.2.0/24")
+	sources = store.NextHopSources(network)
+	if len(sources) != 2 || sources[0].Imported != 3 {
+		t.Error("unexpected next hop usage:", sources)
+	}
+
+	// Unused next hop
+	network, _ = parseNextHopQuery("203.0.113.1")
+	if len(store.NextHopSources(network)) != 0 {
+		t.Error("expected no sources")
+	}
+}
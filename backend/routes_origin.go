@@ -0,0 +1,84 @@
+package main
+
+/*
+Prefixes originated by an ASN
+
+The routes of all sources are searched for the origin
+ASN (the last ASN of the AS path) and deduplicated by
+prefix, listing the sources carrying the prefix.
+*/
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Get the origin ASN of a route: The last ASN
+// of the AS path or 0 if the path is empty.
+func routeOriginAsn(route *api.Route) int {
+	asPath := route.Bgp.AsPath
+	if len(asPath) == 0 {
+		return 0
+	}
+	return asPath[len(asPath)-1]
+}
+
+// Parse an ASN with an optional AS prefix (AS2342)
+func parseAsn(value string) (int, error) {
+	value = strings.TrimPrefix(strings.ToUpper(value), "AS")
+	asn, err := strconv.Atoi(value)
+	if err != nil || asn <= 0 {
+		return 0, &InvalidAsnError{Asn: value}
+	}
+	return asn, nil
+}
+
+// Get all prefixes originated by the ASN in imported
+// or filtered routes of all sources, deduplicated by
+// prefix and sorted by network.
+func (self *RoutesStore) OriginatedPrefixes(
+	asn int,
+) []*api.OriginatedPrefix {
+	prefixes := make(map[string]*api.OriginatedPrefix)
+
+	self.RLock()
+	for sourceId, response := range self.routesMap {
+		for _, routes := range []api.Routes{
+			response.Imported,
+			response.Filtered,
+		} {
+			for _, route := range routes {
+				if routeOriginAsn(route) != asn {
+					continue
+				}
+				prefix, ok := prefixes[route.Network]
+				if !ok {
+					prefix = &api.OriginatedPrefix{
+						Network: route.Network,
+						Sources: []string{},
+					}
+					prefixes[route.Network] = prefix
+				}
+				prefix.Paths++
+				if !MemberOf(prefix.Sources, sourceId) {
+					prefix.Sources = append(prefix.Sources, sourceId)
+				}
+			}
+		}
+	}
+	self.RUnlock()
+
+	results := make([]*api.OriginatedPrefix, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		sort.Strings(prefix.Sources)
+		results = append(results, prefix)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Network < results[j].Network
+	})
+
+	return results
+}
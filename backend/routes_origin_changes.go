@@ -0,0 +1,100 @@
+package main
+
+/*
+Origin ASN changes
+
+A prefix announced by a different origin ASN than on the
+previous refresh can be a sign of a hijack or of a
+reassignment of the prefix. When the routes store is
+refreshed, the origins of the prefixes are compared
+with the previous routes of the source.
+*/
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Get the sorted origin ASNs of the prefixes in
+// the imported and filtered routes.
+func prefixOrigins(response *api.RoutesResponse) map[string][]int {
+	origins := make(map[string][]int)
+	if response == nil {
+		return origins
+	}
+	for _, routes := range []api.Routes{
+		response.Imported,
+		response.Filtered,
+	} {
+		for _, route := range routes {
+			origin := routeOriginAsn(route)
+			if origin == 0 {
+				continue
+			}
+			if !MemberOfInt(origins[route.Network], origin) {
+				origins[route.Network] = append(origins[route.Network], origin)
+			}
+		}
+	}
+	for _, asns := range origins {
+		sort.Ints(asns)
+	}
+	return origins
+}
+
+// Get the prefixes present in both routes, where
+// the origins differ, sorted by network. Prefixes
+// which appeared or disappeared are not changes.
+func originChanges(
+	previous *api.RoutesResponse,
+	current *api.RoutesResponse,
+) []*api.OriginChange {
+	before := prefixOrigins(previous)
+	after := prefixOrigins(current)
+
+	changes := []*api.OriginChange{}
+	for network, origins := range after {
+		previousOrigins, ok := before[network]
+		if !ok || reflect.DeepEqual(previousOrigins, origins) {
+			continue
+		}
+		changes = append(changes, &api.OriginChange{
+			Network:         network,
+			PreviousOrigins: previousOrigins,
+			Origins:         origins,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Network < changes[j].Network
+	})
+
+	return changes
+}
+
+// Get the origin changes of a source since the
+// previous refresh.
+func (self *RoutesStore) OriginChangesAt(sourceId string) []*api.OriginChange {
+	self.RLock()
+	defer self.RUnlock()
+
+	changes, ok := self.originChanges[sourceId]
+	if !ok {
+		return []*api.OriginChange{}
+	}
+	return changes
+}
+
+// Compare the refreshed routes of a source with the
+// previous routes, the caller must hold the lock.
+func (self *RoutesStore) updateOriginChanges(
+	sourceId string,
+	routes *api.RoutesResponse,
+) {
+	if self.originChanges == nil {
+		self.originChanges = make(map[string][]*api.OriginChange)
+	}
+	self.originChanges[sourceId] = originChanges(
+		self.routesMap[sourceId], routes)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func makeTestOriginRoute(network string, origin int) *api.Route {
+	return &api.Route{
+		Network: network,
+		Bgp:     api.BgpInfo{AsPath: []int{2342, origin}},
+	}
+}
+
+func TestOriginChanges(t *testing.T) {
+	previous := &api.RoutesResponse{
+		Imported: api.Routes{
+			makeTestOriginRoute("10.23.0.0/16", 64512),
+			makeTestOriginRoute("10.42.0.0/16", 64512),
+			makeTestOriginRoute("10.66.0.0/16", 64512),
+			makeTestOriginRoute("10.77.0.0/16", 64512),
+		},
+	}
+	current := &api.RoutesResponse{
+		Imported: api.Routes{
+			makeTestOriginRoute("10.42.0.0/16", 64512),
+			makeTestOriginRoute("10.66.0.0/16", 64512),
+			makeTestOriginRoute("10.99.0.0/16", 64600),
+		},
+		Filtered: api.Routes{
+			makeTestOriginRoute("10.23.0.0/16", 64666),
+			makeTestOriginRoute("10.66.0.0/16", 64600),
+		},
+	}
+
+	// 10.23.0.0/16 moved to a different origin and
+	// 10.66.0.0/16 gained an additional origin. New and
+	// withdrawn prefixes are not changes.
+	changes := originChanges(previous, current)
+	if len(changes) != 2 {
+		t.Fatal("Expected 2 origin changes, got:", len(changes))
+	}
+	if changes[0].Network != "10.23.0.0/16" ||
+		!reflect.DeepEqual(changes[0].PreviousOrigins, []int{64512}) ||
+		!reflect.DeepEqual(changes[0].Origins, []int{64666}) {
+		t.Error("Unexpected origin change:", changes[0])
+	}
+	if changes[1].Network != "10.66.0.0/16" ||
+		!reflect.DeepEqual(changes[1].PreviousOrigins, []int{64512}) ||
+		!reflect.DeepEqual(changes[1].Origins, []int{64512, 64600}) {
+		t.Error("Unexpected origin change:", changes[1])
+	}
+}
+
+func TestRoutesStoreOriginChanges(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	source := &liveRoutesSource{
+		routes: &api.RoutesResponse{
+			Imported: api.Routes{
+				makeTestOriginRoute("10.23.0.0/16", 64512),
+				makeTestOriginRoute("10.42.0.0/16", 64512),
+			},
+		},
+	}
+	store := NewRoutesStore(&Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:       "rs1",
+				instance: source,
+			},
+		},
+	})
+
+	// Nothing to compare with on the first refresh
+	store.update()
+	if len(store.OriginChangesAt("rs1")) != 0 {
+		t.Error("Expected no origin changes after the first refresh")
+	}
+
+	source.routes = &api.RoutesResponse{
+		Imported: api.Routes{
+			makeTestOriginRoute("10.23.0.0/16", 64512),
+			makeTestOriginRoute("10.42.0.0/16", 64666),
+		},
+	}
+	store.update()
+
+	changes := store.OriginChangesAt("rs1")
+	if len(changes) != 1 {
+		t.Fatal("Expected 1 origin change, got:", len(changes))
+	}
+	if changes[0].Network != "10.42.0.0/16" ||
+		!reflect.DeepEqual(changes[0].PreviousOrigins, []int{64512}) ||
+		!reflect.DeepEqual(changes[0].Origins, []int{64666}) {
+		t.Error("Unexpected origin change:", changes[0])
+	}
+
+	// The change is only reported until the next refresh
+	store.update()
+	if len(store.OriginChangesAt("rs1")) != 0 {
+		t.Error("Expected no origin changes without a change")
+	}
+
+	if len(store.OriginChangesAt("rs2")) != 0 {
+		t.Error("Expected no origin changes for an unknown source")
+	}
+}
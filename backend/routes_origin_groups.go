@@ -0,0 +1,81 @@
+package main
+
+/*
+Routes grouped by origin ASN
+
+For an overview of the RIB of a source, the imported
+routes are grouped by their origin ASN, counting the
+distinct prefixes and the routes of each origin.
+*/
+
+import (
+	"sort"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Group routes by origin ASN. The networks of each
+// group are collected deduplicated and sorted.
+func groupRoutesByOriginAsn(routes api.Routes) []*api.OriginAsnGroup {
+	groups := make(map[int]*api.OriginAsnGroup)
+	networks := make(map[int]map[string]bool)
+
+	for _, route := range routes {
+		origin := routeOriginAsn(route)
+		if origin == 0 {
+			continue
+		}
+		group, ok := groups[origin]
+		if !ok {
+			group = &api.OriginAsnGroup{
+				Asn: origin,
+			}
+			groups[origin] = group
+			networks[origin] = make(map[string]bool)
+		}
+		group.Routes++
+		if !networks[origin][route.Network] {
+			networks[origin][route.Network] = true
+			group.Networks = append(group.Networks, route.Network)
+		}
+	}
+
+	results := make([]*api.OriginAsnGroup, 0, len(groups))
+	for _, group := range groups {
+		group.Prefixes = len(group.Networks)
+		sort.Strings(group.Networks)
+		results = append(results, group)
+	}
+
+	// Largest origins first
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Prefixes != results[j].Prefixes {
+			return results[i].Prefixes > results[j].Prefixes
+		}
+		return results[i].Asn < results[j].Asn
+	})
+
+	return results
+}
+
+// Get the imported routes of a source grouped by origin ASN
+func (self *RoutesStore) OriginAsnGroups(
+	sourceId string,
+) []*api.OriginAsnGroup {
+	self.RLock()
+	response, ok := self.routesMap[sourceId]
+	self.RUnlock()
+	if !ok {
+		return []*api.OriginAsnGroup{}
+	}
+	return groupRoutesByOriginAsn(response.Imported)
+}
+
+// Only keep the networks of the expanded group
+func expandOriginAsnGroups(groups []*api.OriginAsnGroup, asn int) {
+	for _, group := range groups {
+		if group.Asn != asn {
+			group.Networks = nil
+		}
+	}
+}
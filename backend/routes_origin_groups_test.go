@@ -0,0 +1,121 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+func makeTestOriginGroupsStore() *RoutesStore {
+	return &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{
+			"rs1": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Network: "10.23.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{2342, 64512}}},
+					&api.Route{Network: "10.23.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{23, 64512}}},
+					&api.Route{Network: "10.42.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{2342, 64512}}},
+					&api.Route{Network: "2001:db8::/32",
+						Bgp: api.BgpInfo{AsPath: []int{42}}},
+					&api.Route{Network: "10.66.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{}}},
+				},
+				Filtered: api.Routes{
+					&api.Route{Network: "10.99.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{23, 64666}}},
+				},
+			},
+		},
+		statusMap: map[string]StoreStatus{
+			"rs1": StoreStatus{State: STATE_READY},
+		},
+	}
+}
+
+func TestRoutesStoreOriginAsnGroups(t *testing.T) {
+	store := makeTestOriginGroupsStore()
+
+	groups := store.OriginAsnGroups("rs1")
+	if len(groups) != 2 {
+		t.Fatal("Expected 2 origin groups, got:", len(groups))
+	}
+
+	// AS64512 originates 2 prefixes on 3 routes
+	if groups[0].Asn != 64512 ||
+		groups[0].Prefixes != 2 ||
+		groups[0].Routes != 3 ||
+		!reflect.DeepEqual(groups[0].Networks,
+			[]string{"10.23.0.0/16", "10.42.0.0/16"}) {
+		t.Error("Unexpected group:", groups[0])
+	}
+	if groups[1].Asn != 42 ||
+		groups[1].Prefixes != 1 ||
+		groups[1].Routes != 1 {
+		t.Error("Unexpected group:", groups[1])
+	}
+
+	if len(store.OriginAsnGroups("rs2")) != 0 {
+		t.Error("Expected no groups for unknown source")
+	}
+}
+
+func TestApiRoutesOriginAsnGroups(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{Id: "rs1"},
+		},
+	}
+	AliceConfig.Ui.Pagination.MaxPageSize = 100
+	AliceRoutesStore = makeTestOriginGroupsStore()
+
+	params := httprouter.Params{httprouter.Param{Key: "id", Value: "rs1"}}
+
+	req, _ := http.NewRequest("GET",
+		"/api/v1/routeservers/rs1/origin-asns?page_size=1&expand=AS42", nil)
+	result, err := apiRoutesOriginAsnGroups(req, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := result.(*api.OriginAsnGroupsResponse)
+	if response.Pagination.TotalResults != 2 ||
+		response.Pagination.TotalPages != 2 {
+		t.Error("Unexpected pagination:", response.Pagination)
+	}
+	if len(response.Groups) != 1 || response.Groups[0].Asn != 64512 {
+		t.Fatal("Unexpected groups:", response.Groups)
+	}
+	if response.Groups[0].Networks != nil {
+		t.Error("Expected group not to be expanded")
+	}
+
+	// The expanded group includes its prefixes
+	req, _ = http.NewRequest("GET",
+		"/api/v1/routeservers/rs1/origin-asns?page_size=1&page=1&expand=AS42",
+		nil)
+	result, err = apiRoutesOriginAsnGroups(req, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response = result.(*api.OriginAsnGroupsResponse)
+	if len(response.Groups) != 1 ||
+		!reflect.DeepEqual(response.Groups[0].Networks,
+			[]string{"2001:db8::/32"}) {
+		t.Error("Expected AS42 to be expanded, got:", response.Groups)
+	}
+
+	// Invalid expand
+	req, _ = http.NewRequest("GET",
+		"/api/v1/routeservers/rs1/origin-asns?expand=foo", nil)
+	_, err = apiRoutesOriginAsnGroups(req, params)
+	if _, ok := err.(*InvalidAsnError); !ok {
+		t.Error("Expected InvalidAsnError, got:", err)
+	}
+
+	AliceRoutesStore = nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func TestRoutesStoreOriginatedPrefixes(t *testing.T) {
+	store := &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{
+			"rs1": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Network: "10.23.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{2342, 64512}}},
+					&api.Route{Network: "10.23.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{23, 64512}}},
+					&api.Route{Network: "10.42.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{64512, 2342}}},
+				},
+				Filtered: api.Routes{
+					&api.Route{Network: "192.0.2.0/24",
+						Bgp: api.BgpInfo{AsPath: []int{64512}}},
+				},
+			},
+			"rs2": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Network: "10.23.0.0/16",
+						Bgp: api.BgpInfo{AsPath: []int{42, 64512}}},
+					&api.Route{Network: "2001:db8::/32",
+						Bgp: api.BgpInfo{AsPath: []int{64512}}},
+				},
+			},
+		},
+	}
+
+	prefixes := store.OriginatedPrefixes(64512)
+	expected := []struct {
+		network string
+		sources []string
+		paths   int
+	}{
+		{"10.23.0.0/16", []string{"rs1", "rs2"}, 3},
+		{"192.0.2.0/24", []string{"rs1"}, 1},
+		{"2001:db8::/32", []string{"rs2"}, 1},
+	}
+
+	if len(prefixes) != len(expected) {
+		t.Fatal("Expected", len(expected), "prefixes, got:", len(prefixes))
+	}
+	for i, e := range expected {
+		prefix := prefixes[i]
+		if prefix.Network != e.network || prefix.Paths != e.paths {
+			t.Error("Unexpected prefix:", prefix)
+		}
+		if len(prefix.Sources) != len(e.sources) {
+			t.Error("Unexpected sources:", prefix.Sources)
+			continue
+		}
+		for j, sourceId := range e.sources {
+			if prefix.Sources[j] != sourceId {
+				t.Error("Unexpected sources:", prefix.Sources)
+			}
+		}
+	}
+}
+
+func TestParseAsn(t *testing.T) {
+	for value, expected := range map[string]int{
+		"2342":    2342,
+		"AS2342":  2342,
+		"as64512": 64512,
+	} {
+		asn, err := parseAsn(value)
+		if err != nil || asn != expected {
+			t.Error("Unexpected asn for", value, ":", asn, err)
+		}
+	}
+	if _, err := parseAsn("ASfoo"); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+/*
+Pre-serialized payloads of all routes of a source
+
+Serializing the entire RIB of a source is expensive.
+If enabled, the json payload (and a gzip compressed
+variant) is built once and served until the store
+is refreshed for the source. Otherwise the payload
+is compressed while it is served.
+*/
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+type routesPayload struct {
+	Json []byte
+	Gzip []byte
+}
+
+// Make the payload from a routes response, the gzip
+// variant is only built if requested.
+func makeRoutesPayload(
+	response *api.RoutesResponse,
+	compress bool,
+) (*routesPayload, error) {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	if !compress {
+		return &routesPayload{Json: payload}, nil
+	}
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	if _, err := gz.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return &routesPayload{
+		Json: payload,
+		Gzip: buf.Bytes(),
+	}, nil
+}
+
+// Drop the payload of a source, the caller
+// must hold the lock.
+func (self *RoutesStore) invalidatePayload(sourceId string) {
+	delete(self.payloads, sourceId)
+}
+
+// Get the payload of all routes of a source
+func (self *RoutesStore) AllRoutesPayloadAt(
+	sourceId string,
+) (*routesPayload, error) {
+	self.RLock()
+	cached, ok := self.payloads[sourceId]
+	routes := self.routesMap[sourceId]
+	status := self.statusMap[sourceId]
+	self.RUnlock()
+
+	if ok {
+		return cached, nil
+	}
+
+	response := &api.RoutesResponse{
+		Api: api.ApiStatus{
+			Version: version,
+			CacheStatus: api.CacheStatus{
+				CachedAt: status.LastRefresh,
+			},
+			ResultFromCache: true,
+			Ttl:             status.LastRefresh.Add(self.refreshInterval),
+			Stale:           status.Stale,
+			Loading:         status.IsLoading(),
+		},
+	}
+	if routes != nil {
+		response.Imported = routes.Imported
+		response.Filtered = routes.Filtered
+	}
+
+	payload, err := makeRoutesPayload(response, self.cachePayloads)
+	if err != nil {
+		return nil, err
+	}
+
+	if !self.cachePayloads {
+		return payload, nil
+	}
+
+	// Only keep the payload, if the routes were
+	// not refreshed in the meantime.
+	self.Lock()
+	if self.routesMap[sourceId] == routes {
+		self.payloads[sourceId] = payload
+	}
+	self.Unlock()
+
+	return payload, nil
+}
+
+// Write the payload, compressed if accepted by the client
+func (self *routesPayload) WriteTo(
+	res http.ResponseWriter,
+	req *http.Request,
+) error {
+	res.Header().Set("Content-Type", "application/json")
+	if !strings.Contains(req.Header.Get("Accept-Encoding"), "gzip") {
+		_, err := res.Write(self.Json)
+		return err
+	}
+
+	res.Header().Set("Content-Encoding", "gzip")
+	if self.Gzip != nil {
+		_, err := res.Write(self.Gzip)
+		return err
+	}
+	gz := gzip.NewWriter(res)
+	if _, err := gz.Write(self.Json); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/julienschmidt/httprouter"
+
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAllRoutesPayloadCache(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	store := makeTestRoutesStore()
+	store.cachePayloads = true
+
+	p1, err := store.AllRoutesPayloadAt("rs1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := store.AllRoutesPayloadAt("rs1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The cached bytes should be served
+	if &p1.Json[0] != &p2.Json[0] {
+		t.Error("Expected the cached payload to be served")
+	}
+
+	response := &api.RoutesResponse{}
+	if err := json.Unmarshal(p1.Json, response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Imported) != 8 || len(response.Filtered) != 1 {
+		t.Error("Unexpected routes in payload:",
+			len(response.Imported), len(response.Filtered))
+	}
+
+	// The gzip variant should decompress to the json payload
+	gz, err := gzip.NewReader(bytes.NewReader(p1.Gzip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, p1.Json) {
+		t.Error("Expected gzip payload to match json payload")
+	}
+
+	// Refreshing the store invalidates the payload
+	store.configMap["rs1"].instance = &liveRoutesSource{
+		routes: &api.RoutesResponse{
+			Imported: api.Routes{
+				&api.Route{
+					Id:          "live_route",
+					NeighbourId: "ID2233_AS4223",
+					Network:     "193.200.230.0/24",
+				},
+			},
+		},
+	}
+	store.update()
+
+	p3, err := store.AllRoutesPayloadAt("rs1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(p1.Json, p3.Json) {
+		t.Error("Expected payload to be rebuilt after refresh")
+	}
+
+	response = &api.RoutesResponse{}
+	if err := json.Unmarshal(p3.Json, response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Imported) != 1 || len(response.Filtered) != 0 {
+		t.Error("Unexpected routes in payload:",
+			len(response.Imported), len(response.Filtered))
+	}
+}
+
+func TestAllRoutesPayloadUncached(t *testing.T) {
+	store := makeTestRoutesStore()
+
+	p1, _ := store.AllRoutesPayloadAt("rs1")
+	p2, _ := store.AllRoutesPayloadAt("rs1")
+	if &p1.Json[0] == &p2.Json[0] {
+		t.Error("Expected payload to be rebuilt without caching")
+	}
+	if len(store.payloads) != 0 {
+		t.Error("Expected no cached payloads")
+	}
+	if p1.Gzip != nil {
+		t.Error("Expected no gzip variant without caching")
+	}
+}
+
+func TestRoutesPayloadWriteTo(t *testing.T) {
+	payload := &routesPayload{Json: []byte(`{"imported":[]}`)}
+
+	req := httptest.NewRequest("GET", "/api/v1/routeservers/rs1/routes", nil)
+	res := httptest.NewRecorder()
+	if err := payload.WriteTo(res, req); err != nil {
+		t.Fatal(err)
+	}
+	if res.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected an uncompressed response")
+	}
+	if !bytes.Equal(res.Body.Bytes(), payload.Json) {
+		t.Error("Unexpected body:", res.Body.String())
+	}
+
+	// Compressed while served
+	req.Header.Set("Accept-Encoding", "gzip")
+	res = httptest.NewRecorder()
+	if err := payload.WriteTo(res, req); err != nil {
+		t.Fatal(err)
+	}
+	if res.Header().Get("Content-Encoding") != "gzip" {
+		t.Error("Expected a gzip response")
+	}
+	gz, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, payload.Json) {
+		t.Error("Expected gzip payload to match json payload")
+	}
+}
+
+func TestApiRoutesAllRefreshing(t *testing.T) {
+	defer saveTestGlobals()()
+	store := makeTestRoutesStore()
+	store.statusMap["rs1"] = StoreStatus{
+		State:       STATE_INIT,
+		LastRefresh: time.Now(),
+	}.refreshing()
+
+	AliceConfig = &Config{
+		Sources: []*SourceConfig{store.configMap["rs1"]},
+	}
+	AliceRoutesStore = store
+
+	// The last routes are served while the store refreshes
+	req := httptest.NewRequest("GET", "/api/v1/routeservers/rs1/routes", nil)
+	res := httptest.NewRecorder()
+	streamEndpoint(nil, apiRoutesAll)(res, req, httprouter.Params{
+		httprouter.Param{Key: "id", Value: "rs1"},
+	})
+	if res.Code != http.StatusOK {
+		t.Fatal("Expected status 200, got:", res.Code, res.Body.String())
+	}
+
+	response := &api.RoutesResponse{}
+	if err := json.Unmarshal(res.Body.Bytes(), response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Imported) != 8 || response.Api.Loading {
+		t.Error("Unexpected response:", len(response.Imported),
+			response.Api.Loading)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+/*
+Sort routes by multiple keys. Routes can be sorted
+by any column path, e.g. bgp.local_pref.
+*/
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Compare two routes by a single key
+type routeCompareFunc func(a, b *api.Route) int
+
+// Sort keys, which are not an attribute of the route
+var ROUTE_SORT_KEYS = map[string]routeCompareFunc{
+	"as_path_length": func(a, b *api.Route) int {
+		return compareInts(len(a.Bgp.AsPath), len(b.Bgp.AsPath))
+	},
+}
+
+// Sort keys naming an attribute of the route by its
+// column path. Any other column path is a sort key.
+var ROUTE_SORT_PATHS = map[string]string{
+	"local_pref": "bgp.local_pref",
+	"med":        "bgp.med",
+}
+
+// Get the compare function of a sort key
+func routeSortCompare(name string) (routeCompareFunc, bool) {
+	if compare, ok := ROUTE_SORT_KEYS[name]; ok {
+		return compare, true
+	}
+
+	path, ok := ROUTE_SORT_PATHS[name]
+	if !ok {
+		path = name
+	}
+	if !isColumnPath(reflect.TypeOf(api.Route{}), path) {
+		return nil, false
+	}
+
+	return func(a, b *api.Route) int {
+		valueA, _ := resolveColumnPath(a, path)
+		valueB, _ := resolveColumnPath(b, path)
+		return compareColumnValues(valueA, valueB)
+	}, true
+}
+
+// Parse the list of route sort keys
+func parseRouteSortKeys(value string) ([]sortKey, error) {
+	return parseSortKeys(value, func(name string) bool {
+		_, ok := routeSortCompare(name)
+		return ok
+	})
+}
+
+// Sort routes stable by the sort keys
+func sortRoutes(routes api.Routes, keys []sortKey) {
+	compares := make([]routeCompareFunc, len(keys))
+	for i, key := range keys {
+		compares[i], _ = routeSortCompare(key.name)
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		for k, key := range keys {
+			c := key.apply(compares[k](routes[i], routes[j]))
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+// Sort the routes as requested by the sort query
+// parameter, falling back to the default sort of the
+// source. Without either the order is unchanged.
+func apiQuerySortRoutes(
+	req *http.Request,
+	routes api.Routes,
+	defaultSort string,
+) error {
+	value := req.URL.Query().Get("sort")
+	if value == "" {
+		value = defaultSort
+	}
+	if value == "" {
+		return nil
+	}
+
+	keys, err := parseRouteSortKeys(value)
+	if err != nil {
+		return err
+	}
+
+	sortRoutes(routes, keys)
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func makeTestSortRoutes() api.Routes {
+	return api.Routes{
+		&api.Route{Id: "r1", Network: "10.0.0.0/8",
+			Bgp: api.BgpInfo{AsPath: []int{1, 2, 3}}},
+		&api.Route{Id: "r2", Network: "192.168.0.0/16",
+			Bgp: api.BgpInfo{AsPath: []int{1}}},
+		&api.Route{Id: "r3", Network: "172.16.0.0/12",
+			Bgp: api.BgpInfo{AsPath: []int{1, 2}}},
+	}
+}
+
+func TestSortRoutesDefault(t *testing.T) {
+	routes := makeTestSortRoutes()
+
+	// The default sort of the source is applied
+	req, _ := http.NewRequest("GET", "/", nil)
+	if err := apiQuerySortRoutes(req, routes, "as_path_length"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"r2", "r3", "r1"}
+	for i, id := range expected {
+		if routes[i].Id != id {
+			t.Error("Expected", id, "at", i, "got:", routes[i].Id)
+		}
+	}
+
+	// The requested sort overrides the default
+	req, _ = http.NewRequest("GET", "/?sort=network:desc", nil)
+	if err := apiQuerySortRoutes(req, routes, "as_path_length"); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"r2", "r3", "r1"}
+	for i, id := range expected {
+		if routes[i].Id != id {
+			t.Error("Expected", id, "at", i, "got:", routes[i].Id)
+		}
+	}
+
+	req, _ = http.NewRequest("GET", "/?sort=network", nil)
+	if err := apiQuerySortRoutes(req, routes, "as_path_length"); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"r1", "r3", "r2"}
+	for i, id := range expected {
+		if routes[i].Id != id {
+			t.Error("Expected", id, "at", i, "got:", routes[i].Id)
+		}
+	}
+}
+
+func TestSortRoutesUnsorted(t *testing.T) {
+	routes := makeTestSortRoutes()
+	req, _ := http.NewRequest("GET", "/", nil)
+	if err := apiQuerySortRoutes(req, routes, ""); err != nil {
+		t.Fatal(err)
+	}
+	if routes[0].Id != "r1" || routes[1].Id != "r2" || routes[2].Id != "r3" {
+		t.Error("Expected routes to be unchanged")
+	}
+
+	req, _ = http.NewRequest("GET", "/?sort=unicorns", nil)
+	err := apiQuerySortRoutes(req, routes, "")
+	if _, ok := err.(*InvalidSortError); !ok {
+		t.Error("Expected InvalidSortError, got:", err)
+	}
+}
+
+func TestSortRoutesAttributeBytes(t *testing.T) {
+	routes := api.Routes{
+		&api.Route{Id: "r1", AttributeCount: 4, AttributeBytes: 42},
+		&api.Route{Id: "r2", AttributeCount: 12, AttributeBytes: 4096},
+		&api.Route{Id: "r3", AttributeCount: 5, AttributeBytes: 128},
+	}
+
+	req, _ := http.NewRequest("GET", "/?sort=attribute_bytes:desc", nil)
+	if err := apiQuerySortRoutes(req, routes, ""); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"r2", "r3", "r1"}
+	for i, id := range expected {
+		if routes[i].Id != id {
+			t.Error("Expected", id, "at", i, "got:", routes[i].Id)
+		}
+	}
+
+	// Filter oversized updates
+	req, _ = http.NewRequest("GET", "/?min_attribute_bytes=128", nil)
+	filtered := apiQueryFilterAttributeBytes(req, routes)
+	if len(filtered) != 2 || filtered[0].Id != "r2" || filtered[1].Id != "r3" {
+		t.Error("Unexpected filtered routes:", filtered)
+	}
+}
+
+func TestSortRoutesColumnPath(t *testing.T) {
+	routes := api.Routes{
+		&api.Route{Id: "r1", Bgp: api.BgpInfo{LocalPref: 100}},
+		&api.Route{Id: "r2", Bgp: api.BgpInfo{LocalPref: 200}},
+		&api.Route{Id: "r3", Bgp: api.BgpInfo{LocalPref: 90}},
+	}
+
+	req, _ := http.NewRequest("GET", "/?sort=bgp.local_pref:desc", nil)
+	if err := apiQuerySortRoutes(req, routes, ""); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"r2", "r1", "r3"}
+	for i, id := range expected {
+		if routes[i].Id != id {
+			t.Error("Expected", id, "at", i, "got:", routes[i].Id)
+		}
+	}
+
+	req, _ = http.NewRequest("GET", "/?sort=bgp.local_preference", nil)
+	err := apiQuerySortRoutes(req, routes, "")
+	if _, ok := err.(*InvalidSortError); !ok {
+		t.Error("Expected InvalidSortError, got:", err)
+	}
+}
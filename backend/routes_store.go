@@ -2,6 +2,8 @@ package main
 
 import (
 	"log"
+	"net"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -17,6 +19,27 @@ type RoutesStore struct {
 	refreshInterval time.Duration
 	lastRefresh     time.Time
 
+	// Pre-serialized payloads of all routes
+	payloads      map[string]*routesPayload
+	cachePayloads bool
+
+	// RPKI validation summaries, recomputed on refresh
+	rpkiSummaries map[string]*api.RpkiSummary
+
+	// Prefixes with a changed origin since the last refresh
+	originChanges map[string][]*api.OriginChange
+
+	// Networks of the accepted routes, parsed on refresh
+	destinationNetworks map[string][]*destinationNetwork
+
+	// Filtered routes of the previous refresh
+	previousFiltered   map[string]api.Routes
+	previousFilteredAt map[string]time.Time
+
+	// Limit the sources queried in lookups
+	maxLookupSources   int
+	lookupSourcesOrder string
+
 	sync.RWMutex
 }
 
@@ -50,6 +73,18 @@ func NewRoutesStore(config *Config) *RoutesStore {
 		statusMap:       statusMap,
 		configMap:       configMap,
 		refreshInterval: refreshInterval,
+		payloads:        make(map[string]*routesPayload),
+		cachePayloads:   config.Server.CacheAllRoutesPayload,
+		rpkiSummaries:   make(map[string]*api.RpkiSummary),
+		originChanges:   make(map[string][]*api.OriginChange),
+
+		destinationNetworks: make(map[string][]*destinationNetwork),
+
+		previousFiltered:   make(map[string]api.Routes),
+		previousFilteredAt: make(map[string]time.Time),
+
+		maxLookupSources:   config.Server.MaxLookupSources,
+		lookupSourcesOrder: config.Server.LookupSourcesOrder,
 	}
 	return store
 }
@@ -75,6 +110,15 @@ func (self *RoutesStore) init() {
 	}
 }
 
+// Get the store status of a source
+func (self *RoutesStore) SourceStatus(sourceId string) StoreStatus {
+	self.RLock()
+	status := self.statusMap[sourceId]
+	self.RUnlock()
+
+	return status
+}
+
 // Update all routes
 func (self *RoutesStore) update() {
 	successCount := 0
@@ -90,14 +134,26 @@ func (self *RoutesStore) update() {
 			continue // nothing to do here
 		}
 
+		// Refreshing is suspended during maintenance
+		if sourceConfig.inMaintenance(time.Now()) {
+			self.Lock()
+			self.statusMap[sourceId] = self.statusMap[sourceId].maintenance()
+			self.Unlock()
+			continue
+		}
+
 		// Set update state
 		self.Lock()
-		self.statusMap[sourceId] = StoreStatus{
-			State: STATE_UPDATING,
-		}
+		retries := self.statusMap[sourceId].fetchRetries(sourceConfig)
+		self.statusMap[sourceId] = self.statusMap[sourceId].refreshing()
 		self.Unlock()
 
-		routes, err := source.AllRoutes()
+		var routes *api.RoutesResponse
+		err := retryWithBackoff(retries, sourceConfig.StartupRetryDelay,
+			func() (err error) {
+				routes, err = source.AllRoutes()
+				return err
+			})
 		if err != nil {
 			log.Println(
 				"Refreshing the routes store failed for:", sourceConfig.Name,
@@ -107,24 +163,65 @@ func (self *RoutesStore) update() {
 			)
 
 			self.Lock()
-			self.statusMap[sourceId] = StoreStatus{
-				State:       STATE_ERROR,
-				LastError:   err,
-				LastRefresh: time.Now(),
+			status := self.statusMap[sourceId].failed(err, sourceConfig)
+			if status.staleExpired(sourceConfig) {
+				self.routesMap[sourceId] = &api.RoutesResponse{}
+				self.updateRpkiSummary(sourceId, self.routesMap[sourceId])
+				self.updateDestinationNetworks(sourceId, self.routesMap[sourceId])
 			}
+			self.statusMap[sourceId] = status
+			self.invalidatePayload(sourceId)
 			self.Unlock()
 
 			errorCount++
 			continue
 		}
 
+		// Flag blackholed routes, calculate path lengths, ...
+		routes = annotateRoutesResponse(sourceConfig, routes)
+
+		belowMinRoutes := sourceConfig.belowMinRoutes(routes)
+		if belowMinRoutes {
+			log.Println(
+				"WARNING: Refreshing the routes store for:", sourceConfig.Name,
+				"(", sourceConfig.Id, ")",
+				"returned", countRoutes(routes), "routes,",
+				"expected at least", sourceConfig.MinRoutes,
+			)
+		}
+
 		self.Lock()
+		// Keep the previous routes, if any
+		if belowMinRoutes && sourceConfig.MinRoutesKeepPrevious &&
+			countRoutes(self.routesMap[sourceId]) > 0 {
+			status := self.statusMap[sourceId]
+			self.statusMap[sourceId] = StoreStatus{
+				LastRefresh:           status.LastRefresh,
+				State:                 STATE_READY,
+				LastRefreshDuration:   status.refreshDuration(),
+				LastSuccessfulRefresh: status.LastSuccessfulRefresh,
+				BelowMinRoutes:        true,
+			}
+			self.Unlock()
+
+			errorCount++
+			continue
+		}
+
 		// Update data
+		self.updateOriginChanges(sourceId, routes)
+		self.updatePreviousFiltered(sourceId)
 		self.routesMap[sourceId] = routes
+		self.invalidatePayload(sourceId)
+		self.updateRpkiSummary(sourceId, routes)
+		self.updateDestinationNetworks(sourceId, routes)
 		// Update state
 		self.statusMap[sourceId] = StoreStatus{
-			LastRefresh: time.Now(),
-			State:       STATE_READY,
+			LastRefresh:           time.Now(),
+			State:                 STATE_READY,
+			LastRefreshDuration:   self.statusMap[sourceId].refreshDuration(),
+			LastSuccessfulRefresh: time.Now(),
+			BelowMinRoutes:        belowMinRoutes,
 		}
 		self.lastRefresh = time.Now().UTC()
 		self.Unlock()
@@ -164,6 +261,8 @@ func (self *RoutesStore) Stats() RoutesStoreStats {
 
 			State:     stateToString(status.State),
 			UpdatedAt: status.LastRefresh,
+
+			Refresh: makeRefreshStats(status),
 		}
 
 		rsStats = append(rsStats, serverStats)
@@ -181,6 +280,13 @@ func (self *RoutesStore) Stats() RoutesStoreStats {
 	return storeStats
 }
 
+// Check if any source was not refreshed yet
+func (self *RoutesStore) IsLoading() bool {
+	self.RLock()
+	defer self.RUnlock()
+	return anyStoreStatusLoading(self.statusMap)
+}
+
 // Provide cache status
 func (self *RoutesStore) CachedAt() time.Time {
 	return self.lastRefresh
@@ -208,8 +314,9 @@ func routeToLookupRoute(
 		Neighbour:   neighbour,
 
 		Routeserver: api.Routeserver{
-			Id:   source.Id,
-			Name: source.Name,
+			Id:         source.Id,
+			Name:       source.Name,
+			LookupMode: source.getLookupMode(),
 		},
 
 		State: state,
@@ -222,6 +329,13 @@ func routeToLookupRoute(
 		Age:       route.Age,
 		Type:      route.Type,
 		Primary:   route.Primary,
+
+		Blackholed: route.Blackholed,
+		Bogon:      route.Bogon,
+
+		AsPathLength:  route.AsPathLength,
+		AsPathTooLong: route.AsPathTooLong,
+		PrependCount:  route.PrependCount,
 	}
 
 	return lookup
@@ -263,6 +377,175 @@ func filterRoutesByNeighbourIds(
 	return results
 }
 
+// Get all imported routes of a source
+func (self *RoutesStore) ImportedRoutesAt(sourceId string) api.Routes {
+	self.RLock()
+	defer self.RUnlock()
+
+	routes, ok := self.routesMap[sourceId]
+	if !ok {
+		return api.Routes{}
+	}
+	return routes.Imported
+}
+
+// Routes difference: Get all routes which are not
+// present in the other set. Routes are identified by
+// network and gateway.
+func routesDifference(routes, other api.Routes) api.Routes {
+	present := make(map[string]bool, len(other))
+	for _, route := range other {
+		present[route.Network+"@"+route.Gateway] = true
+	}
+
+	results := api.Routes{}
+	for _, route := range routes {
+		if present[route.Network+"@"+route.Gateway] {
+			continue
+		}
+		results = append(results, route)
+	}
+
+	return results
+}
+
+// Get the routes received from a neighbour and the
+// routes accepted. Received routes are all imported
+// and filtered routes.
+func (self *RoutesStore) NeighbourRoutesAt(
+	sourceId string,
+	neighbourId string,
+) (api.Routes, api.Routes) {
+	self.RLock()
+	routes, ok := self.routesMap[sourceId]
+	self.RUnlock()
+
+	received := api.Routes{}
+	accepted := api.Routes{}
+	if !ok {
+		return received, accepted
+	}
+
+	for _, route := range routes.Imported {
+		if route.NeighbourId == neighbourId {
+			received = append(received, route)
+			accepted = append(accepted, route)
+		}
+	}
+	for _, route := range routes.Filtered {
+		if route.NeighbourId == neighbourId {
+			received = append(received, route)
+		}
+	}
+
+	return received, accepted
+}
+
+// Get all routes of a source carrying any of
+// the labeled communities.
+func (self *RoutesStore) LabeledRoutesAt(
+	sourceId string,
+	labels BgpCommunities,
+) (api.Routes, api.Routes) {
+	self.RLock()
+	routes, ok := self.routesMap[sourceId]
+	self.RUnlock()
+
+	if !ok {
+		return api.Routes{}, api.Routes{}
+	}
+
+	imported := filterRoutesByLabeledCommunities(routes.Imported, labels, true)
+	filtered := filterRoutesByLabeledCommunities(routes.Filtered, labels, true)
+
+	return imported, filtered
+}
+
+// Get the routes of a neighbour filtered since the previous
+// refresh and the routes no longer filtered, compared with
+// the filtered routes at the returned time of the previous
+// refresh. Without a previous refresh, all filtered routes
+// of the neighbour are new.
+func (self *RoutesStore) NeighbourRoutesDiffAt(
+	sourceId string,
+	neighbourId string,
+) (api.Routes, api.Routes, time.Time) {
+	self.RLock()
+	routes, ok := self.routesMap[sourceId]
+	previous := self.previousFiltered[sourceId]
+	previousAt := self.previousFilteredAt[sourceId]
+	self.RUnlock()
+
+	if !ok {
+		return api.Routes{}, api.Routes{}, previousAt
+	}
+
+	filtered := neighbourRoutes(routes.Filtered, neighbourId)
+	filteredBefore := neighbourRoutes(previous, neighbourId)
+
+	newlyFiltered := routesDifference(filtered, filteredBefore)
+	noLongerFiltered := routesDifference(filteredBefore, filtered)
+	sort.Sort(newlyFiltered)
+	sort.Sort(noLongerFiltered)
+
+	return newlyFiltered, noLongerFiltered, previousAt
+}
+
+// Get the routes received from a neighbour
+func neighbourRoutes(routes api.Routes, neighbourId string) api.Routes {
+	results := api.Routes{}
+	for _, route := range routes {
+		if route.NeighbourId == neighbourId {
+			results = append(results, route)
+		}
+	}
+	return results
+}
+
+// Keep the filtered routes of a source before they are
+// replaced, the caller must hold the lock.
+func (self *RoutesStore) updatePreviousFiltered(sourceId string) {
+	if self.previousFiltered == nil {
+		self.previousFiltered = make(map[string]api.Routes)
+		self.previousFilteredAt = make(map[string]time.Time)
+	}
+	status := self.statusMap[sourceId]
+	if status.LastSuccessfulRefresh.IsZero() {
+		return
+	}
+	self.previousFiltered[sourceId] = self.routesMap[sourceId].Filtered
+	self.previousFilteredAt[sourceId] = status.LastSuccessfulRefresh
+}
+
+// Get the routes of a source for lookups: Depending
+// on the lookup mode, the routes are retrieved from
+// the store or from the source. When the source fails,
+// the stored routes are used.
+func (self *RoutesStore) lookupRoutesAt(
+	sourceId string,
+) (*SourceConfig, *api.RoutesResponse) {
+	self.RLock()
+	source := self.configMap[sourceId]
+	routes := self.routesMap[sourceId]
+	self.RUnlock()
+
+	if source.getLookupMode() != LOOKUP_MODE_LIVE {
+		return source, routes
+	}
+
+	live, err := source.getInstance().AllRoutes()
+	if err != nil {
+		log.Println(
+			"Live lookup failed for:", source.Name,
+			"(", source.Id, ")", "with:", err,
+			"- using the stored routes")
+		return source, routes
+	}
+	live = annotateRoutesResponse(source, live)
+
+	return source, live
+}
+
 // Single RS lookup by neighbour id
 func (self *RoutesStore) LookupNeighboursPrefixesAt(
 	sourceId string,
@@ -271,10 +554,7 @@ func (self *RoutesStore) LookupNeighboursPrefixesAt(
 	response := make(chan api.LookupRoutes)
 
 	go func() {
-		self.RLock()
-		source := self.configMap[sourceId]
-		routes := self.routesMap[sourceId]
-		self.RUnlock()
+		source, routes := self.lookupRoutesAt(sourceId)
 
 		filtered := filterRoutesByNeighbourIds(
 			source,
@@ -305,10 +585,7 @@ func (self *RoutesStore) LookupPrefixAt(
 	response := make(chan api.LookupRoutes)
 
 	go func() {
-		self.RLock()
-		config := self.configMap[sourceId]
-		routes := self.routesMap[sourceId]
-		self.RUnlock()
+		config, routes := self.lookupRoutesAt(sourceId)
 
 		filtered := filterRoutesByPrefix(
 			config,
@@ -338,12 +615,11 @@ func (self *RoutesStore) LookupPrefix(prefix string) api.LookupRoutes {
 	prefix = strings.ToLower(prefix)
 
 	// Dispatch
-	self.RLock()
-	for sourceId, _ := range self.routesMap {
+	sourceIds, _ := self.lookupSourceIds()
+	for _, sourceId := range sourceIds {
 		res := self.LookupPrefixAt(sourceId, prefix)
 		responses = append(responses, res)
 	}
-	self.RUnlock()
 
 	// Collect
 	for _, response := range responses {
@@ -355,6 +631,151 @@ func (self *RoutesStore) LookupPrefix(prefix string) api.LookupRoutes {
 	return result
 }
 
+// Lookup a prefix in all sources and pass the results
+// to the callback per source, ordered by source id.
+// All sources are queried concurrently. Returning false
+// from the callback stops the lookup.
+func (self *RoutesStore) LookupPrefixEach(
+	prefix string,
+	fn func(sourceId string, routes api.LookupRoutes) bool,
+) {
+	prefix = strings.ToLower(prefix)
+
+	sourceIds, _ := self.lookupSourceIds()
+	sort.Strings(sourceIds)
+
+	// Dispatch
+	responses := make([]chan api.LookupRoutes, 0, len(sourceIds))
+	for _, sourceId := range sourceIds {
+		responses = append(responses, self.LookupPrefixAt(sourceId, prefix))
+	}
+
+	// Collect, the remaining responses are drained
+	// if the lookup is stopped.
+	stopped := false
+	for i, response := range responses {
+		routes := <-response
+		close(response)
+		if stopped {
+			continue
+		}
+		stopped = !fn(sourceIds[i], routes)
+	}
+}
+
+// A network of an accepted route, parsed
+// for destination lookups
+type destinationNetwork struct {
+	network *net.IPNet
+	length  int
+	route   *api.Route
+}
+
+// Parse the networks of the routes
+func makeDestinationNetworks(routes api.Routes) []*destinationNetwork {
+	networks := make([]*destinationNetwork, 0, len(routes))
+	for _, route := range routes {
+		_, network, err := net.ParseCIDR(route.Network)
+		if err != nil {
+			continue
+		}
+		length, _ := network.Mask.Size()
+		networks = append(networks, &destinationNetwork{
+			network: network,
+			length:  length,
+			route:   route,
+		})
+	}
+	return networks
+}
+
+// Parse the networks of the refreshed routes of a
+// source, the caller must hold the lock.
+func (self *RoutesStore) updateDestinationNetworks(
+	sourceId string,
+	routes *api.RoutesResponse,
+) {
+	if self.destinationNetworks == nil {
+		self.destinationNetworks = make(map[string][]*destinationNetwork)
+	}
+	self.destinationNetworks[sourceId] = makeDestinationNetworks(
+		routes.Imported)
+}
+
+// Get the networks of a source for destination lookups.
+// In live lookup mode, the networks of the routes from
+// the source are parsed.
+func (self *RoutesStore) lookupDestinationNetworksAt(
+	sourceId string,
+) (*SourceConfig, []*destinationNetwork) {
+	self.RLock()
+	source := self.configMap[sourceId]
+	networks, ok := self.destinationNetworks[sourceId]
+	self.RUnlock()
+
+	if ok && source.getLookupMode() != LOOKUP_MODE_LIVE {
+		return source, networks
+	}
+
+	source, routes := self.lookupRoutesAt(sourceId)
+	return source, makeDestinationNetworks(routes.Imported)
+}
+
+// Find the most specific route covering the address
+func longestMatchRoute(
+	networks []*destinationNetwork,
+	ip net.IP,
+) *api.Route {
+	var match *destinationNetwork
+	for _, network := range networks {
+		if !network.network.Contains(ip) {
+			continue
+		}
+		if match == nil || network.length > match.length {
+			match = network
+		}
+	}
+	if match == nil {
+		return nil
+	}
+	return match.route
+}
+
+// Lookup the covering route for a destination in
+// all sources. At most one accepted route per source
+// is returned. All sources are queried concurrently.
+func (self *RoutesStore) LookupDestination(ip net.IP) api.LookupRoutes {
+	sourceIds, _ := self.lookupSourceIds()
+
+	matches := make(api.LookupRoutes, len(sourceIds))
+	wg := sync.WaitGroup{}
+	for i, sourceId := range sourceIds {
+		wg.Add(1)
+		go func(i int, sourceId string) {
+			defer wg.Done()
+			source, networks := self.lookupDestinationNetworksAt(sourceId)
+			route := longestMatchRoute(networks, ip)
+			if route == nil {
+				return
+			}
+			matches[i] = routeToLookupRoute(source, "imported", route)
+		}(i, sourceId)
+	}
+	wg.Wait()
+
+	result := api.LookupRoutes{}
+	for _, match := range matches {
+		if match != nil {
+			result = append(result, match)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Routeserver.Id < result[j].Routeserver.Id
+	})
+
+	return result
+}
+
 func (self *RoutesStore) LookupPrefixForNeighbours(
 	neighbours api.NeighboursLookupResults,
 ) api.LookupRoutes {
@@ -362,8 +783,17 @@ func (self *RoutesStore) LookupPrefixForNeighbours(
 	result := api.LookupRoutes{}
 	responses := []chan api.LookupRoutes{}
 
+	sourceIds, _ := self.lookupSourceIds()
+	queried := make(map[string]bool, len(sourceIds))
+	for _, sourceId := range sourceIds {
+		queried[sourceId] = true
+	}
+
 	// Dispatch
 	for sourceId, locals := range neighbours {
+		if !queried[sourceId] {
+			continue
+		}
 		lookupNeighbourIds := []string{}
 		for _, n := range locals {
 			lookupNeighbourIds = append(lookupNeighbourIds, n.Id)
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"net"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"encoding/json"
 	"io/ioutil"
@@ -97,6 +101,7 @@ func makeTestRoutesStore() *RoutesStore {
 		routesMap: routesMap,
 		statusMap: statusMap,
 		configMap: configMap,
+		payloads:  make(map[string]*routesPayload),
 	}
 
 	return store
@@ -125,6 +130,7 @@ func TestRoutesStoreStats(t *testing.T) {
 }
 
 func TestLookupPrefixAt(t *testing.T) {
+	defer saveTestGlobals()()
 	startTestNeighboursStore()
 	store := makeTestRoutesStore()
 
@@ -146,6 +152,7 @@ func TestLookupPrefixAt(t *testing.T) {
 }
 
 func TestLookupPrefix(t *testing.T) {
+	defer saveTestGlobals()()
 	startTestNeighboursStore()
 	store := makeTestRoutesStore()
 	query := "193.200."
@@ -169,6 +176,7 @@ func TestLookupPrefix(t *testing.T) {
 }
 
 func TestLookupNeighboursPrefixesAt(t *testing.T) {
+	defer saveTestGlobals()()
 	startTestNeighboursStore()
 	store := makeTestRoutesStore()
 
@@ -191,6 +199,7 @@ func TestLookupNeighboursPrefixesAt(t *testing.T) {
 }
 
 func TestLookupPrefixForNeighbours(t *testing.T) {
+	defer saveTestGlobals()()
 	// Construct a neighbours lookup result
 	neighbours := api.NeighboursLookupResults{
 		"rs1": api.Neighbours{
@@ -222,3 +231,494 @@ func TestLookupPrefixForNeighbours(t *testing.T) {
 
 	testCheckPrefixesPresence(presence, resultset, t)
 }
+
+func TestRoutesDifference(t *testing.T) {
+	received := api.Routes{
+		&api.Route{Network: "10.0.0.0/8", Gateway: "192.168.1.1"},
+		&api.Route{Network: "10.0.0.0/8", Gateway: "192.168.1.2"},
+		&api.Route{Network: "172.16.0.0/12", Gateway: "192.168.1.1"},
+	}
+	accepted := api.Routes{
+		&api.Route{Network: "10.0.0.0/8", Gateway: "192.168.1.1"},
+	}
+
+	diff := routesDifference(received, accepted)
+	if len(diff) != 2 {
+		t.Error("Expected 2 routes in difference, got:", len(diff))
+	}
+	for _, route := range diff {
+		if route.Network == "10.0.0.0/8" && route.Gateway == "192.168.1.1" {
+			t.Error("Accepted route should not be in difference")
+		}
+	}
+}
+
+func TestNeighbourRoutesDiffAt(t *testing.T) {
+	store := makeTestRoutesStore()
+
+	// The only route of this neighbour was filtered
+	received, accepted := store.NeighbourRoutesAt("rs1", "ID7254_AS31334")
+	if len(received) != 1 || len(accepted) != 0 {
+		t.Error("Unexpected received / accepted:", len(received), len(accepted))
+	}
+
+	// Without a previous refresh, the filtered route is new
+	filtered, unfiltered, since := store.NeighbourRoutesDiffAt(
+		"rs1", "ID7254_AS31334")
+	if len(filtered) != 1 || len(unfiltered) != 0 || !since.IsZero() {
+		t.Error("Unexpected diff:", len(filtered), len(unfiltered), since)
+		return
+	}
+	if filtered[0].Network != "42.23.0.0/16" {
+		t.Error("Unexpected filtered route:", filtered[0].Network)
+	}
+
+	// Refresh: The filtered route is accepted, and an
+	// accepted route of the neighbour is filtered.
+	refreshedAt := time.Now().Add(-5 * time.Minute)
+	store.statusMap["rs1"] = StoreStatus{
+		State:                 STATE_READY,
+		LastRefresh:           refreshedAt,
+		LastSuccessfulRefresh: refreshedAt,
+	}
+	store.updatePreviousFiltered("rs1")
+	store.routesMap["rs1"] = &api.RoutesResponse{
+		Imported: api.Routes{
+			&api.Route{
+				Network:     "42.23.0.0/16",
+				Gateway:     "193.42.155.51",
+				NeighbourId: "ID7254_AS31334",
+			},
+		},
+		Filtered: api.Routes{
+			&api.Route{
+				Network:     "23.42.0.0/16",
+				Gateway:     "193.42.155.51",
+				NeighbourId: "ID7254_AS31334",
+			},
+		},
+	}
+
+	filtered, unfiltered, since = store.NeighbourRoutesDiffAt(
+		"rs1", "ID7254_AS31334")
+	if !since.Equal(refreshedAt) {
+		t.Error("Expected the diff since the previous refresh, got:", since)
+	}
+	if len(filtered) != 1 || filtered[0].Network != "23.42.0.0/16" {
+		t.Error("Unexpected newly filtered routes:", filtered)
+	}
+	if len(unfiltered) != 1 || unfiltered[0].Network != "42.23.0.0/16" {
+		t.Error("Unexpected no longer filtered routes:", unfiltered)
+	}
+
+	// All routes of this neighbour were accepted
+	filtered, unfiltered, _ = store.NeighbourRoutesDiffAt(
+		"rs1", "ID163_AS31078")
+	if len(filtered) != 0 || len(unfiltered) != 0 {
+		t.Error("Expected an empty diff, got:", len(filtered), len(unfiltered))
+	}
+
+	// Unknown source
+	filtered, unfiltered, _ = store.NeighbourRoutesDiffAt(
+		"rs23", "ID163_AS31078")
+	if len(filtered) != 0 || len(unfiltered) != 0 {
+		t.Error("Expected empty difference for unknown source")
+	}
+}
+
+func TestRoutesStoreRefreshProgress(t *testing.T) {
+	store := makeTestRoutesStore()
+
+	// Mid refresh
+	store.statusMap["rs1"] = StoreStatus{
+		State:               STATE_READY,
+		LastRefreshDuration: 42 * time.Second,
+	}.refreshing()
+
+	stats := store.Stats()
+	refresh := stats.RouteServers[0].Refresh
+	if !refresh.Refreshing {
+		t.Error("Expected source to be refreshing")
+	}
+	if refresh.StartedAt == nil || refresh.StartedAt.IsZero() {
+		t.Error("Expected refresh start time")
+	}
+	if refresh.LastRefreshDuration != 42 {
+		t.Error("Expected last refresh duration to be kept, got:",
+			refresh.LastRefreshDuration)
+	}
+	if stats.RouteServers[0].State != "UPDATING" {
+		t.Error("Unexpected state:", stats.RouteServers[0].State)
+	}
+
+	// Refresh done
+	store.statusMap["rs1"] = StoreStatus{
+		State:               STATE_READY,
+		LastRefresh:         time.Now(),
+		LastRefreshDuration: store.statusMap["rs1"].refreshDuration(),
+	}
+
+	refresh = store.Stats().RouteServers[0].Refresh
+	if refresh.Refreshing {
+		t.Error("Expected refresh to be done")
+	}
+	if refresh.StartedAt != nil {
+		t.Error("Expected no refresh start time")
+	}
+}
+
+func TestLookupDestination(t *testing.T) {
+	defer saveTestGlobals()()
+	startTestNeighboursStore()
+
+	store := &RoutesStore{
+		routesMap: map[string]*api.RoutesResponse{
+			"rs1": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Id: "r1", Network: "10.0.0.0/8"},
+					&api.Route{Id: "r2", Network: "10.23.0.0/16"},
+					&api.Route{Id: "r3", Network: "192.168.0.0/16"},
+				},
+			},
+			"rs2": &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{Id: "r4", Network: "10.0.0.0/8"},
+					&api.Route{Id: "r5", Network: "10.23.42.0/24"},
+				},
+			},
+		},
+		statusMap: map[string]StoreStatus{},
+		configMap: map[string]*SourceConfig{
+			"rs1": &SourceConfig{Id: "rs1", Name: "rs1.test"},
+			"rs2": &SourceConfig{Id: "rs2", Name: "rs2.test"},
+		},
+	}
+
+	routes := store.LookupDestination(net.ParseIP("10.23.42.1"))
+	if len(routes) != 2 {
+		t.Fatal("Expected one route per source, got:", len(routes))
+	}
+	if routes[0].Routeserver.Id != "rs1" || routes[0].Network != "10.23.0.0/16" {
+		t.Error("Unexpected covering route for rs1:",
+			routes[0].Routeserver.Id, routes[0].Network)
+	}
+	if routes[1].Routeserver.Id != "rs2" || routes[1].Network != "10.23.42.0/24" {
+		t.Error("Unexpected covering route for rs2:",
+			routes[1].Routeserver.Id, routes[1].Network)
+	}
+
+	// Only rs1 has a covering route
+	routes = store.LookupDestination(net.ParseIP("192.168.1.1"))
+	if len(routes) != 1 || routes[0].Id != "r3" {
+		t.Error("Expected only r3 to cover the destination")
+	}
+
+	// No covering route
+	routes = store.LookupDestination(net.ParseIP("172.16.0.1"))
+	if len(routes) != 0 {
+		t.Error("Expected no covering routes, got:", len(routes))
+	}
+
+	// The networks parsed on refresh are used
+	store.updateDestinationNetworks("rs2", &api.RoutesResponse{
+		Imported: api.Routes{
+			&api.Route{Id: "r6", Network: "172.16.0.0/12"},
+		},
+	})
+	routes = store.LookupDestination(net.ParseIP("172.16.0.1"))
+	if len(routes) != 1 || routes[0].Id != "r6" {
+		t.Error("Expected the parsed networks to be used")
+	}
+
+	// Only the lookup sources are queried
+	store.maxLookupSources = 1
+	routes = store.LookupDestination(net.ParseIP("10.23.42.1"))
+	if len(routes) != 1 || routes[0].Routeserver.Id != "rs1" {
+		t.Error("Expected only rs1 to be queried, got:", routes)
+	}
+}
+
+func TestApiLookupDestinationInvalid(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/v1/lookup/destination?q=foo", nil)
+	_, err := apiLookupDestinationGlobal(req, nil)
+	if _, ok := err.(*InvalidDestinationError); !ok {
+		t.Error("Expected InvalidDestinationError, got:", err)
+	}
+	if _, status := apiErrorResponse("unknown", err); status != 400 {
+		t.Error("Expected status 400, got:", status)
+	}
+}
+
+// A source serving routes live
+type liveRoutesSource struct {
+	expireCountingSource
+	routes *api.RoutesResponse
+}
+
+func (self *liveRoutesSource) AllRoutes() (*api.RoutesResponse, error) {
+	if self.routes == nil {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return self.routes, nil
+}
+
+func TestLookupRoutesAtLiveError(t *testing.T) {
+	store := makeTestRoutesStore()
+	store.configMap["rs1"].LookupMode = LOOKUP_MODE_LIVE
+	store.configMap["rs1"].instance = &liveRoutesSource{}
+
+	// The stored routes are used, when the source fails
+	_, routes := store.lookupRoutesAt("rs1")
+	if routes != store.routesMap["rs1"] {
+		t.Error("Expected the stored routes")
+	}
+}
+
+func TestLookupPrefixLookupModes(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+	startTestNeighboursStore()
+
+	store := makeTestRoutesStore()
+
+	// Add a live source, the store is not populated
+	store.configMap["rs2"] = &SourceConfig{
+		Id:         "rs2",
+		Name:       "rs2.test",
+		LookupMode: LOOKUP_MODE_LIVE,
+		instance: &liveRoutesSource{
+			routes: &api.RoutesResponse{
+				Imported: api.Routes{
+					&api.Route{
+						Id:          "live_route",
+						NeighbourId: "ID2233_AS4223",
+						Network:     "193.200.230.0/24",
+					},
+				},
+			},
+		},
+	}
+	store.routesMap["rs2"] = &api.RoutesResponse{}
+
+	routes := store.LookupPrefix("193.200.230.0/24")
+
+	fromStore := 0
+	fromLive := 0
+	for _, r := range routes {
+		switch r.Routeserver.Id {
+		case "rs1":
+			if r.Routeserver.LookupMode != LOOKUP_MODE_STORE {
+				t.Error("Unexpected lookup mode for rs1:", r.Routeserver.LookupMode)
+			}
+			fromStore++
+		case "rs2":
+			if r.Routeserver.LookupMode != LOOKUP_MODE_LIVE {
+				t.Error("Unexpected lookup mode for rs2:", r.Routeserver.LookupMode)
+			}
+			if r.Id != "live_route" {
+				t.Error("Unexpected live route:", r.Id)
+			}
+			fromLive++
+		}
+	}
+
+	if fromStore == 0 {
+		t.Error("Expected routes from the store")
+	}
+	if fromLive != 1 {
+		t.Error("Expected 1 live route, got:", fromLive)
+	}
+}
+
+// A source unreachable for the first attempts
+type startingRoutesSource struct {
+	liveRoutesSource
+	failures int
+}
+
+func (self *startingRoutesSource) AllRoutes() (*api.RoutesResponse, error) {
+	if self.failures > 0 {
+		self.failures--
+		return nil, fmt.Errorf("connection refused")
+	}
+	return self.routes, nil
+}
+
+func TestRoutesStoreStartupRetries(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	source := &startingRoutesSource{
+		liveRoutesSource: liveRoutesSource{
+			routes: loadTestRoutesResponse(),
+		},
+		failures: 1,
+	}
+	store := NewRoutesStore(&Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:                "rs1",
+				StartupRetries:    2,
+				StartupRetryDelay: time.Millisecond,
+				instance:          source,
+			},
+		},
+	})
+
+	store.update()
+	if store.SourceStatus("rs1").State != STATE_READY {
+		t.Error("Expected source to be ready after retry, got:",
+			stateToString(store.SourceStatus("rs1").State))
+	}
+
+	// Only the initial refresh is retried
+	source.failures = 1
+	store.update()
+	if store.SourceStatus("rs1").State != STATE_ERROR {
+		t.Error("Expected source to fail without retry, got:",
+			stateToString(store.SourceStatus("rs1").State))
+	}
+}
+
+func TestRoutesStoreLoading(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	store := NewRoutesStore(&Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id: "rs1",
+				instance: &liveRoutesSource{
+					routes: &api.RoutesResponse{},
+				},
+			},
+		},
+	})
+	if !store.IsLoading() {
+		t.Error("Expected a never refreshed store to be loading")
+	}
+
+	store.update()
+	if store.IsLoading() {
+		t.Error("Expected a refreshed store not to be loading")
+	}
+}
+
+func TestRoutesStoreMinRoutes(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	source := &liveRoutesSource{
+		routes: loadTestRoutesResponse(),
+	}
+	sourceConfig := &SourceConfig{
+		Id:        "rs1",
+		MinRoutes: 5,
+		instance:  source,
+	}
+	store := NewRoutesStore(&Config{
+		Sources: []*SourceConfig{sourceConfig},
+	})
+
+	store.update()
+	if store.SourceStatus("rs1").BelowMinRoutes {
+		t.Error("Expected routes not to be below the threshold")
+	}
+	previous := countRoutes(store.routesMap["rs1"])
+
+	// The routes are replaced, but flagged
+	source.routes = &api.RoutesResponse{
+		Imported: api.Routes{&api.Route{Id: "r1"}},
+	}
+	store.update()
+	status := store.SourceStatus("rs1")
+	if !status.BelowMinRoutes || status.State != STATE_READY {
+		t.Error("Expected routes to be flagged below the threshold")
+	}
+	if countRoutes(store.routesMap["rs1"]) != 1 {
+		t.Error("Expected routes to be replaced")
+	}
+	if !makeRefreshStats(status).BelowMinRoutes {
+		t.Error("Expected flag in refresh stats")
+	}
+
+	// Keep the previous routes
+	source.routes = loadTestRoutesResponse()
+	store.update()
+	sourceConfig.MinRoutesKeepPrevious = true
+	lastRefresh := store.SourceStatus("rs1").LastRefresh
+
+	source.routes = &api.RoutesResponse{}
+	store.update()
+	status = store.SourceStatus("rs1")
+	if !status.BelowMinRoutes {
+		t.Error("Expected routes to be flagged below the threshold")
+	}
+	if countRoutes(store.routesMap["rs1"]) != previous {
+		t.Error("Expected previous routes to be kept, got:",
+			countRoutes(store.routesMap["rs1"]))
+	}
+	if status.LastRefresh != lastRefresh {
+		t.Error("Expected the last refresh to be kept")
+	}
+}
+
+func TestRoutesStoreStaleGracePeriod(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{}
+
+	source := &startingRoutesSource{
+		liveRoutesSource: liveRoutesSource{
+			routes: loadTestRoutesResponse(),
+		},
+	}
+	store := NewRoutesStore(&Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{
+				Id:               "rs1",
+				StaleGracePeriod: 10 * time.Minute,
+				instance:         source,
+			},
+		},
+	})
+	store.update()
+	count := countRoutes(store.routesMap["rs1"])
+
+	// Within the grace period the routes are served stale
+	source.failures = 1
+	store.update()
+	status := store.SourceStatus("rs1")
+	if !status.Stale || !status.IsAvailable() {
+		t.Error("Expected stale routes to be available")
+	}
+	if countRoutes(store.routesMap["rs1"]) != count {
+		t.Error("Expected the previous routes to be kept")
+	}
+	payload, err := store.AllRoutesPayloadAt("rs1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(payload.Json), `"stale":true`) {
+		t.Error("Expected the routes to be flagged stale")
+	}
+
+	// After the grace period the routes are cleared
+	status.LastSuccessfulRefresh = time.Now().Add(-time.Hour)
+	store.statusMap["rs1"] = status
+	source.failures = 1
+	store.update()
+	status = store.SourceStatus("rs1")
+	if status.Stale || status.IsAvailable() {
+		t.Error("Expected routes not to be available after grace period")
+	}
+	if countRoutes(store.routesMap["rs1"]) != 0 {
+		t.Error("Expected routes to be cleared")
+	}
+
+	// A successful refresh recovers the source
+	store.update()
+	if store.SourceStatus("rs1").Stale ||
+		countRoutes(store.routesMap["rs1"]) != count {
+		t.Error("Expected routes to be refreshed")
+	}
+}
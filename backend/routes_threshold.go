@@ -0,0 +1,28 @@
+package main
+
+/*
+Minimum routes threshold
+
+A refresh bringing in fewer routes than expected for
+a source likely is an incident. The store may keep the
+previous routes, instead of serving a half-empty table.
+*/
+
+import (
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Count the imported and filtered routes
+func countRoutes(routes *api.RoutesResponse) int {
+	if routes == nil {
+		return 0
+	}
+	return len(routes.Imported) + len(routes.Filtered)
+}
+
+// Check if the routes are below the minimum
+// routes threshold of the source. A threshold
+// of 0 disables the check.
+func (self *SourceConfig) belowMinRoutes(routes *api.RoutesResponse) bool {
+	return self.MinRoutes > 0 && countRoutes(routes) < self.MinRoutes
+}
@@ -0,0 +1,131 @@
+package main
+
+/*
+RPKI origin validation summary
+
+The validation state of a route is derived from the
+large communities configured in the [rpki] section.
+The summary is recomputed when the routes store
+is refreshed.
+*/
+
+import (
+	"strconv"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+// Helper: Check if the community matches the
+// configured community. The last component may
+// be a range (start, end) or open ended (start, *).
+func rpkiCommunityMatch(com api.Community, config []string) bool {
+	if len(config) < 3 || len(com) != 3 {
+		return false
+	}
+	for i := 0; i < 2; i++ {
+		if strconv.Itoa(com[i]) != config[i] {
+			return false
+		}
+	}
+
+	start, err := strconv.Atoi(config[2])
+	if err != nil {
+		return false
+	}
+	if len(config) == 3 {
+		return com[2] == start
+	}
+	if config[3] == "*" {
+		return com[2] >= start
+	}
+	end, err := strconv.Atoi(config[3])
+	if err != nil {
+		return false
+	}
+	return com[2] >= start && com[2] <= end
+}
+
+// Get the validation state of the route. An empty
+// string is returned if the route is not tagged.
+func rpkiRouteState(config RpkiConfig, route *api.Route) string {
+	for _, com := range route.Bgp.LargeCommunities {
+		switch {
+		case rpkiCommunityMatch(com, config.Valid):
+			return api.RPKI_STATE_VALID
+		case rpkiCommunityMatch(com, config.Unknown):
+			return api.RPKI_STATE_UNKNOWN
+		case rpkiCommunityMatch(com, config.NotChecked):
+			return api.RPKI_STATE_NOT_CHECKED
+		case rpkiCommunityMatch(com, config.Invalid):
+			return api.RPKI_STATE_INVALID
+		}
+	}
+	return ""
+}
+
+// Count the validation states of imported
+// and filtered routes.
+func makeRpkiSummary(
+	config RpkiConfig,
+	routes *api.RoutesResponse,
+) *api.RpkiSummary {
+	summary := &api.RpkiSummary{}
+	if routes == nil {
+		return summary
+	}
+	for _, rs := range []api.Routes{routes.Imported, routes.Filtered} {
+		for _, route := range rs {
+			summary.Add(rpkiRouteState(config, route))
+		}
+	}
+	summary.UpdatePercentages()
+	return summary
+}
+
+// Get the RPKI summary of a source
+func (self *RoutesStore) RpkiSummaryAt(sourceId string) *api.RpkiSummary {
+	self.RLock()
+	defer self.RUnlock()
+
+	summary, ok := self.rpkiSummaries[sourceId]
+	if !ok {
+		return &api.RpkiSummary{}
+	}
+	return summary
+}
+
+// Get the RPKI summaries of all sources and
+// the network wide summary.
+func (self *RoutesStore) RpkiSummaries() (
+	map[string]*api.RpkiSummary,
+	*api.RpkiSummary,
+) {
+	self.RLock()
+	defer self.RUnlock()
+
+	sources := make(map[string]*api.RpkiSummary, len(self.rpkiSummaries))
+	total := &api.RpkiSummary{}
+	for sourceId, summary := range self.rpkiSummaries {
+		sources[sourceId] = summary
+		total.Merge(summary)
+	}
+	total.UpdatePercentages()
+
+	return sources, total
+}
+
+// Recompute the summary of a source, the caller
+// must hold the lock.
+func (self *RoutesStore) updateRpkiSummary(
+	sourceId string,
+	routes *api.RoutesResponse,
+) {
+	if AliceConfig == nil || !AliceConfig.Ui.Rpki.Enabled {
+		return
+	}
+	if self.rpkiSummaries == nil {
+		self.rpkiSummaries = make(map[string]*api.RpkiSummary)
+	}
+	self.rpkiSummaries[sourceId] = makeRpkiSummary(
+		AliceConfig.Ui.Rpki, routes)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+)
+
+func makeTestRpkiConfig() RpkiConfig {
+	return RpkiConfig{
+		Enabled:    true,
+		Valid:      []string{"9033", "1000", "1"},
+		Unknown:    []string{"9033", "1000", "2"},
+		NotChecked: []string{"9033", "1000", "3"},
+		Invalid:    []string{"9033", "1000", "4", "*"},
+	}
+}
+
+func makeTestRpkiRoute(state int) *api.Route {
+	route := &api.Route{}
+	if state > 0 {
+		route.Bgp.LargeCommunities = api.Communities{
+			api.Community{9033, 23, 42},
+			api.Community{9033, 1000, state},
+		}
+	}
+	return route
+}
+
+func TestRpkiCommunityMatch(t *testing.T) {
+	com := api.Community{9033, 1000, 5}
+	if !rpkiCommunityMatch(com, []string{"9033", "1000", "4", "*"}) {
+		t.Error("Expected open range to match")
+	}
+	if !rpkiCommunityMatch(com, []string{"9033", "1000", "4", "6"}) {
+		t.Error("Expected range to match")
+	}
+	if rpkiCommunityMatch(com, []string{"9033", "1000", "6", "*"}) {
+		t.Error("Expected range not to match")
+	}
+	if rpkiCommunityMatch(com, []string{"9033", "1000", "4"}) {
+		t.Error("Expected community not to match")
+	}
+}
+
+func TestMakeRpkiSummary(t *testing.T) {
+	routes := &api.RoutesResponse{
+		Imported: api.Routes{
+			makeTestRpkiRoute(1),
+			makeTestRpkiRoute(1),
+			makeTestRpkiRoute(1),
+			makeTestRpkiRoute(1),
+			makeTestRpkiRoute(2),
+			makeTestRpkiRoute(0),
+		},
+		Filtered: api.Routes{
+			makeTestRpkiRoute(4),
+			makeTestRpkiRoute(7),
+		},
+	}
+
+	summary := makeRpkiSummary(makeTestRpkiConfig(), routes)
+	if summary.Total != 8 ||
+		summary.Valid != 4 ||
+		summary.Invalid != 2 ||
+		summary.Unknown != 1 ||
+		summary.NotChecked != 0 ||
+		summary.Untagged != 1 {
+		t.Error("Unexpected counts:", summary)
+	}
+	if summary.ValidPercent != 50.0 ||
+		summary.InvalidPercent != 25.0 ||
+		summary.UnknownPercent != 12.5 ||
+		summary.NotCheckedPercent != 0 {
+		t.Error("Unexpected percentages:", summary)
+	}
+}
+
+func TestRpkiSummaryRefresh(t *testing.T) {
+	defer saveTestGlobals()()
+	AliceConfig = &Config{
+		Ui: UiConfig{
+			Rpki: makeTestRpkiConfig(),
+		},
+	}
+
+	store := makeTestRoutesStore()
+	store.configMap["rs1"].instance = &liveRoutesSource{
+		routes: &api.RoutesResponse{
+			Imported: api.Routes{
+				makeTestRpkiRoute(1),
+				makeTestRpkiRoute(4),
+			},
+		},
+	}
+	store.configMap["rs2"] = &SourceConfig{
+		Id: "rs2",
+		instance: &liveRoutesSource{
+			routes: &api.RoutesResponse{
+				Imported: api.Routes{
+					makeTestRpkiRoute(1),
+					makeTestRpkiRoute(3),
+				},
+			},
+		},
+	}
+	store.routesMap["rs2"] = &api.RoutesResponse{}
+
+	// Not yet computed
+	if store.RpkiSummaryAt("rs1").Total != 0 {
+		t.Error("Expected empty summary before refresh")
+	}
+
+	store.update()
+
+	summary := store.RpkiSummaryAt("rs1")
+	if summary.Total != 2 || summary.Valid != 1 || summary.Invalid != 1 {
+		t.Error("Unexpected summary:", summary)
+	}
+
+	sources, total := store.RpkiSummaries()
+	if len(sources) != 2 {
+		t.Error("Expected 2 sources, got:", len(sources))
+	}
+	if total.Total != 4 ||
+		total.Valid != 2 ||
+		total.NotChecked != 1 ||
+		total.ValidPercent != 50.0 {
+		t.Error("Unexpected network wide summary:", total)
+	}
+}
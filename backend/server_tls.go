@@ -0,0 +1,158 @@
+package main
+
+/*
+Serve the api and the frontend over TLS.
+
+The certificate is reloaded on SIGHUP, so renewed
+certificates are used without a restart.
+*/
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Keep the server certificate and reload it on demand
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	cert *tls.Certificate
+	sync.RWMutex
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	reloader := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Load the certificate and key. The current certificate
+// is kept, if loading fails.
+func (self *certReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(self.certFile, self.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load tls certificate: %s", err)
+	}
+
+	self.Lock()
+	self.cert = &cert
+	self.Unlock()
+
+	return nil
+}
+
+// Get the current certificate for a handshake
+func (self *certReloader) GetCertificate(
+	_ *tls.ClientHelloInfo,
+) (*tls.Certificate, error) {
+	self.RLock()
+	defer self.RUnlock()
+	return self.cert, nil
+}
+
+// Reload the certificate when receiving SIGHUP
+func (self *certReloader) watchSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		if err := self.Reload(); err != nil {
+			log.Println("Reloading the tls certificate failed:", err)
+			continue
+		}
+		log.Println("Reloaded tls certificate:", self.certFile)
+	}
+}
+
+// Make the TLS configuration of the server. Without a
+// certificate and key, the server uses plain HTTP and
+// no TLS configuration is returned.
+func makeServerTLSConfig(
+	config ServerConfig,
+) (*tls.Config, *certReloader, error) {
+	if config.TLSCert == "" && config.TLSKey == "" {
+		if config.TLSClientCA != "" {
+			return nil, nil, fmt.Errorf(
+				"tls_client_ca requires tls_cert and tls_key")
+		}
+		return nil, nil, nil
+	}
+	if config.TLSCert == "" || config.TLSKey == "" {
+		return nil, nil, fmt.Errorf(
+			"tls_cert and tls_key must be configured together")
+	}
+
+	reloader, err := newCertReloader(config.TLSCert, config.TLSKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	// Require client certificates signed by the CA
+	if config.TLSClientCA != "" {
+		pem, err := ioutil.ReadFile(config.TLSClientCA)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"could not load tls client ca: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf(
+				"no certificates found in tls client ca: %s",
+				config.TLSClientCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, reloader, nil
+}
+
+// Make the http server, serving over TLS if configured
+func makeServer(
+	config ServerConfig,
+	handler http.Handler,
+) (*http.Server, *certReloader, error) {
+	tlsConfig, reloader, err := makeServerTLSConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	server := &http.Server{
+		Addr:      config.Listen,
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+	return server, reloader, nil
+}
+
+// Start the http server
+func listenAndServe(config ServerConfig, handler http.Handler) error {
+	server, reloader, err := makeServer(config, handler)
+	if err != nil {
+		return err
+	}
+	if reloader == nil {
+		return server.ListenAndServe()
+	}
+
+	go reloader.watchSignals()
+	log.Println("Serving over TLS with certificate:", config.TLSCert)
+	return server.ListenAndServeTLS("", "")
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Write a self signed certificate and key for localhost
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(
+		rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "alice.crt")
+	keyFile := filepath.Join(dir, "alice.key")
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(
+		&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestServeTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alice-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	handler := http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Write([]byte("ok"))
+	})
+	server, reloader, err := makeServer(ServerConfig{
+		Listen:  "127.0.0.1:0",
+		TLSCert: certFile,
+		TLSKey:  keyFile,
+	}, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloader == nil || server.TLSConfig == nil {
+		t.Fatal("Expected server to be configured for TLS")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.ServeTLS(listener, "", "")
+	defer server.Close()
+
+	// Trust the self signed certificate
+	pemData, _ := ioutil.ReadFile(certFile)
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(pemData)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots},
+		},
+	}
+
+	res, err := client.Get("https://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.TLS == nil {
+		t.Error("Expected the response to be served over TLS")
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	if string(body) != "ok" {
+		t.Error("Unexpected response:", string(body))
+	}
+
+	// Reload a renewed certificate
+	previous, _ := reloader.GetCertificate(nil)
+	writeTestCertificate(t, dir)
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	current, _ := reloader.GetCertificate(nil)
+	if current == previous {
+		t.Error("Expected the certificate to be reloaded")
+	}
+}
+
+func TestServerTLSConfigErrors(t *testing.T) {
+	// Plain http without certificate
+	tlsConfig, reloader, err := makeServerTLSConfig(ServerConfig{})
+	if tlsConfig != nil || reloader != nil || err != nil {
+		t.Error("Expected plain http, got:", tlsConfig, err)
+	}
+
+	// Key is missing
+	_, _, err = makeServerTLSConfig(ServerConfig{
+		TLSCert: "/etc/alice-lg/tls/alice.crt",
+	})
+	if err == nil || !strings.Contains(err.Error(), "tls_key") {
+		t.Error("Expected missing key to be reported, got:", err)
+	}
+
+	// Files do not exist
+	_, _, err = makeServerTLSConfig(ServerConfig{
+		TLSCert: "does-not-exist.crt",
+		TLSKey:  "does-not-exist.key",
+	})
+	if err == nil ||
+		!strings.Contains(err.Error(), "could not load tls certificate") {
+		t.Error("Expected unreadable certificate to be reported, got:", err)
+	}
+}
+
+func TestServerTLSClientCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alice-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	tlsConfig, _, err := makeServerTLSConfig(ServerConfig{
+		TLSCert:     certFile,
+		TLSKey:      keyFile,
+		TLSClientCA: certFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Error("Expected client certificates to be required")
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Close the sources when the server is stopped,
+// releasing shared connections to the backends.
+func HandleShutdown(config *Config) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		log.Println("Received", sig, "- shutting down")
+
+		config.CloseSources()
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// A source counting calls to Close
+type closeCountingSource struct {
+	expireCountingSource
+	closed int
+	err    error
+}
+
+func (self *closeCountingSource) Close() error {
+	self.closed++
+	return self.err
+}
+
+func TestConfigCloseSources(t *testing.T) {
+	rs1 := &closeCountingSource{}
+	rs2 := &closeCountingSource{err: errors.New("connection lost")}
+	rs3 := &expireCountingSource{} // Nothing to close
+	config := &Config{
+		Sources: []*SourceConfig{
+			&SourceConfig{Id: "rs1", Name: "rs1", instance: rs1},
+			&SourceConfig{Id: "rs2", Name: "rs2", instance: rs2},
+			&SourceConfig{Id: "rs3", Name: "rs3", instance: rs3},
+		},
+	}
+
+	// A failing source does not prevent closing the others
+	config.CloseSources()
+	if rs1.closed != 1 || rs2.closed != 1 {
+		t.Error("Expected Close to be called on each source")
+	}
+	for _, source := range config.Sources {
+		if source.instance != nil {
+			t.Error("Expected the instance to be reset:", source.Id)
+		}
+	}
+
+	// Closed instances are not closed again
+	config.CloseSources()
+	if rs1.closed != 1 {
+		t.Error("Expected Close to be called once, got:", rs1.closed)
+	}
+}
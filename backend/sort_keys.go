@@ -0,0 +1,77 @@
+package main
+
+/*
+Sort keys
+
+The sort query parameter is a comma separated list
+of keys, each optionally suffixed with an order:
+
+    sort=state,asn:desc
+
+Keys are applied in order, later keys break ties.
+*/
+
+import (
+	"strings"
+)
+
+const (
+	SORT_ORDER_ASC  = "asc"
+	SORT_ORDER_DESC = "desc"
+)
+
+type sortKey struct {
+	name       string
+	descending bool
+}
+
+// Parse the list of sort keys. Only keys
+// accepted by known are valid.
+func parseSortKeys(
+	value string,
+	known func(name string) bool,
+) ([]sortKey, error) {
+	keys := []sortKey{}
+	for _, token := range strings.Split(value, ",") {
+		token = strings.TrimSpace(strings.ToLower(token))
+		if token == "" {
+			continue
+		}
+
+		name, order := token, SORT_ORDER_ASC
+		if i := strings.Index(token, ":"); i >= 0 {
+			name, order = token[:i], token[i+1:]
+		}
+
+		if !known(name) {
+			return nil, &InvalidSortError{Sort: token}
+		}
+		if order != SORT_ORDER_ASC && order != SORT_ORDER_DESC {
+			return nil, &InvalidSortError{Sort: token}
+		}
+
+		keys = append(keys, sortKey{
+			name:       name,
+			descending: order == SORT_ORDER_DESC,
+		})
+	}
+	return keys, nil
+}
+
+// Helper: Apply the order of the key to a comparison
+func (self sortKey) apply(c int) int {
+	if self.descending {
+		return -c
+	}
+	return c
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
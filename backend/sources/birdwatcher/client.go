@@ -6,18 +6,48 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/alice-lg/alice-lg/backend/sources"
+)
+
+// States of the connection, named like the
+// connectivity states of the gRPC sources
+const (
+	CONNECTION_STATE_IDLE              = "IDLE"
+	CONNECTION_STATE_READY             = "READY"
+	CONNECTION_STATE_TRANSIENT_FAILURE = "TRANSIENT_FAILURE"
 )
 
 type ClientResponse map[string]interface{}
 
 type Client struct {
 	Api string
+
+	transport *http.Transport
+
+	// Diagnostics of the requests
+	sync.Mutex
+	state      string
+	reconnects int
+	lastError  error
 }
 
 func NewClient(api string) *Client {
+	// Connections resolve the host when dialing
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		DialContext:     sources.DialContext,
+		MaxIdleConns:    100,
+		IdleConnTimeout: 90 * time.Second,
+	}
+
 	client := &Client{
-		Api: api,
+		Api:       api,
+		transport: transport,
+		state:     CONNECTION_STATE_IDLE,
 	}
 	return client
 }
@@ -26,6 +56,12 @@ func NewClient(api string) *Client {
 func (self *Client) Get(client *http.Client, url string) (ClientResponse, error) {
 	res, err := client.Get(url)
 	if err != nil {
+		// Drop kept alive connections, so the next
+		// request reconnects and resolves the host again.
+		if self.transport != nil {
+			self.transport.CloseIdleConnections()
+		}
+		self.recordResult(err, true)
 		return ClientResponse{}, err
 	}
 
@@ -33,6 +69,7 @@ func (self *Client) Get(client *http.Client, url string) (ClientResponse, error)
 	defer res.Body.Close()
 	payload, err := ioutil.ReadAll(res.Body)
 	if err != nil {
+		self.recordResult(err, false)
 		return ClientResponse{}, err
 	}
 
@@ -40,15 +77,51 @@ func (self *Client) Get(client *http.Client, url string) (ClientResponse, error)
 	result := make(ClientResponse)
 	err = json.Unmarshal(payload, &result)
 	if err != nil {
+		self.recordResult(err, false)
 		return ClientResponse{}, err
 	}
 
+	self.recordResult(nil, false)
 	return result, nil
 }
 
+// Update the diagnostics with the result of a request.
+// The last error is kept after a successful request.
+func (self *Client) recordResult(err error, reconnect bool) {
+	self.Lock()
+	defer self.Unlock()
+
+	if reconnect {
+		self.reconnects++
+	}
+	if err == nil {
+		self.state = CONNECTION_STATE_READY
+		return
+	}
+	self.state = CONNECTION_STATE_TRANSIENT_FAILURE
+	self.lastError = err
+}
+
+// Get the diagnostics of the connection
+func (self *Client) Status() *api.ConnectionStatus {
+	self.Lock()
+	defer self.Unlock()
+
+	status := &api.ConnectionStatus{
+		State:      self.state,
+		Reconnects: self.reconnects,
+	}
+	if self.lastError != nil {
+		status.LastError = self.lastError.Error()
+	}
+	return status
+}
+
 // Make API request, parse response and return map or error
 func (self *Client) GetJson(endpoint string) (ClientResponse, error) {
-	client := &http.Client{}
+	client := &http.Client{
+		Transport: self.transport,
+	}
 
 	return self.Get(client, self.Api + endpoint)
 }
@@ -56,7 +129,8 @@ func (self *Client) GetJson(endpoint string) (ClientResponse, error) {
 // Make API request, parse response and return map or error
 func (self *Client) GetJsonTimeout(timeout time.Duration, endpoint string) (ClientResponse, error) {
 	client := &http.Client{
-		Timeout: timeout,
+		Transport: self.transport,
+		Timeout:   timeout,
 	}
 
 	return self.Get(client, self.Api + endpoint)
@@ -0,0 +1,103 @@
+package birdwatcher
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/sources"
+)
+
+// Start a server responding with its name
+func startNamedServer(t *testing.T, address, name string) *httptest.Server {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Skip("Could not listen:", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			fmt.Fprintf(res, `{"server": "%s"}`, name)
+		}))
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	return server
+}
+
+func TestClientReconnectAfterFailover(t *testing.T) {
+	primary := startNamedServer(t, "127.0.0.1:0", "primary")
+	port := strconv.Itoa(primary.Listener.Addr().(*net.TCPAddr).Port)
+	secondary := startNamedServer(t, "127.0.0.2:"+port, "secondary")
+	defer secondary.Close()
+
+	resolved := "127.0.0.1"
+	lookupHost := sources.LookupHost
+	sources.LookupHost = func(_ context.Context, _ string) ([]string, error) {
+		return []string{resolved}, nil
+	}
+	defer func() { sources.LookupHost = lookupHost }()
+
+	client := NewClient("http://rs.example.net:" + port)
+	res, err := client.GetJson("/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["server"] != "primary" {
+		t.Error("Expected response from primary, got:", res)
+	}
+
+	// Failover: The primary goes away and the
+	// name resolves to the secondary.
+	primary.Close()
+	resolved = "127.0.0.2"
+
+	res, err = client.GetJson("/status")
+	if err != nil {
+		// The kept alive connection may fail once
+		res, err = client.GetJson("/status")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["server"] != "secondary" {
+		t.Error("Expected response from secondary, got:", res)
+	}
+}
+
+func TestClientConnectionStatus(t *testing.T) {
+	server := startNamedServer(t, "127.0.0.1:0", "rs1")
+	client := NewClient(server.URL)
+
+	status := client.Status()
+	if status.State != CONNECTION_STATE_IDLE {
+		t.Error("Expected idle connection, got:", status.State)
+	}
+
+	if _, err := client.GetJson("/status"); err != nil {
+		t.Fatal(err)
+	}
+	status = client.Status()
+	if status.State != CONNECTION_STATE_READY {
+		t.Error("Expected ready connection, got:", status.State)
+	}
+
+	// Requests to an unreachable birdwatcher fail
+	server.Close()
+	if _, err := client.GetJson("/status"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	status = client.Status()
+	if status.State != CONNECTION_STATE_TRANSIENT_FAILURE {
+		t.Error("Expected failed connection, got:", status.State)
+	}
+	if status.Reconnects != 1 {
+		t.Error("Expected 1 reconnect, got:", status.Reconnects)
+	}
+	if status.LastError == "" {
+		t.Error("Expected the last error to be recorded")
+	}
+}
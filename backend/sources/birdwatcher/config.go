@@ -1,5 +1,9 @@
 package birdwatcher
 
+import (
+	"fmt"
+)
+
 type Config struct {
 	Id   string
 	Name string
@@ -16,3 +20,16 @@ type Config struct {
 	PipeProtocolPrefix      string `ini:"pipe_protocol_prefix"`
 	NeighborsRefreshTimeout int    `ini:"neighbors_refresh_timeout"`
 }
+
+// Check the configuration for missing or invalid settings
+func (self Config) Verify() error {
+	if self.Api == "" {
+		return fmt.Errorf("missing api url")
+	}
+	if self.Type != "single_table" && self.Type != "multi_table" {
+		return fmt.Errorf(
+			"unknown birdwatcher type: %s (valid types are: %s)",
+			self.Type, "single_table, multi_table")
+	}
+	return nil
+}
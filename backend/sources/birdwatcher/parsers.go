@@ -174,13 +174,15 @@ func parseNeighbours(bird ClientResponse, config Config) (api.Neighbours, error)
 			}
 		}
 
+		stateRaw := mustString(protocol["state"], "unknown")
+
 		neighbour := &api.Neighbour{
 			Id: protocolId,
 
-			Address: mustString(protocol["neighbor_address"], "error"),
-			Asn:     mustInt(protocol["neighbor_as"], 0),
-			State: strings.ToLower(
-				mustString(protocol["state"], "unknown")),
+			Address:     mustString(protocol["neighbor_address"], "error"),
+			Asn:         mustInt(protocol["neighbor_as"], 0),
+			State:       strings.ToLower(stateRaw),
+			StateRaw:    stateRaw,
 			Description: mustString(protocol["description"], "no description"),
 
 			RoutesReceived:  mustInt(routesReceived, 0),
@@ -320,7 +322,7 @@ func parseRoutesData(birdRoutes []interface{}, config Config) api.Routes {
 			NeighbourId: mustString(rdata["from_protocol"], "unknown neighbour"),
 
 			Network:   mustString(rdata["network"], "unknown net"),
-			Interface: mustString(rdata["interface"], "unknown interface"),
+			Interface: mustString(rdata["interface"], ""),
 			Gateway:   mustString(rdata["gateway"], "unknown gateway"),
 			Metric:    mustInt(rdata["metric"], -1),
 			Primary:   mustBool(rdata["primary"], false),
@@ -92,6 +92,31 @@ func Test_NeighboursParsing(t *testing.T) {
 	}
 }
 
+func Test_NeighboursParsingStateRaw(t *testing.T) {
+	config := Config{Timezone: "UTC"}
+	bird, _ := parseTestResponse(API_RESPONSE_NEIGHBOURS)
+
+	protocols := bird["protocols"].(map[string]interface{})
+	protocol := protocols["ID103_AS25074_194.9.117.1"].(map[string]interface{})
+	protocol["state"] = "Start"
+
+	neighbours, err := parseNeighbours(bird, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	neighbour := neighbours[0]
+	if neighbour.Id != "ID103_AS25074_194.9.117.1" {
+		t.Fatal("unexpected neighbour:", neighbour.Id)
+	}
+	if neighbour.State != "start" {
+		t.Error("expected normalized state start, got:", neighbour.State)
+	}
+	if neighbour.StateRaw != "Start" {
+		t.Error("expected raw state Start, got:", neighbour.StateRaw)
+	}
+}
+
 func Test_RoutesParsing(t *testing.T) {
 	config := Config{Timezone: "UTC"} // Or ""
 	bird, _ := parseTestResponse(API_RESPONSE_ROUTES)
@@ -105,9 +130,30 @@ func Test_RoutesParsing(t *testing.T) {
 		t.Error("Expected parsed routes to be 1, not:", len(routes))
 	}
 
+	if routes[0].Interface != "eno7" {
+		t.Error("Expected interface eno7, got:", routes[0].Interface)
+	}
+
 	// TODO: addo more tests
 }
 
+func Test_RoutesParsingWithoutInterface(t *testing.T) {
+	config := Config{Timezone: "UTC"}
+	bird, _ := parseTestResponse(`{"routes":[{"network":"193.200.230.0/24","gateway":"194.9.117.4","bgp":{}}]}`)
+
+	routes, err := parseRoutes(bird, config)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Expected parsed routes to be 1, not:", len(routes))
+	}
+
+	if routes[0].Interface != "" {
+		t.Error("Expected blank interface, got:", routes[0].Interface)
+	}
+}
+
 func Test_ParseServerTime(t *testing.T) {
 
 	res, err := parseServerTime(
@@ -146,3 +192,30 @@ func Test_ParseServerTime(t *testing.T) {
 		t.Error("Expected", expected, ", got:", res)
 	}
 }
+
+func Test_ParseBirdwatcherStatus(t *testing.T) {
+	bird, _ := parseTestResponse(`{"status": {
+		"current_server": "2017-05-22 10:22:39",
+		"last_reboot": "2017-05-10 14:47:27",
+		"last_reconfig": "2017-05-17 03:20:28",
+		"message": "Daemon is up and running",
+		"router_id": "194.9.117.253",
+		"version": "1.6.3"}}`)
+	config := Config{
+		Timezone:        "UTC",
+		ServerTimeShort: "2006-01-02 15:04:05",
+		ServerTimeExt:   "2006-01-02 15:04:05",
+	}
+
+	status, err := parseBirdwatcherStatus(bird, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.RouterId != "194.9.117.253" {
+		t.Error("Unexpected router id:", status.RouterId)
+	}
+	// Bird does not report the local AS
+	if status.LocalAs != 0 {
+		t.Error("Expected local AS to be blank, got:", status.LocalAs)
+	}
+}
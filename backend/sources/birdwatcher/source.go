@@ -188,6 +188,11 @@ func (self *GenericBirdwatcher) fetchProtocolsShort() (*api.ApiStatus, map[strin
 	return &apiStatus, bird, nil
 }
 
+// Get the diagnostics of the connection to the birdwatcher
+func (self *GenericBirdwatcher) ConnectionStatus() *api.ConnectionStatus {
+	return self.client.Status()
+}
+
 func (self *GenericBirdwatcher) ExpireCaches() int {
 	count := self.routesRequiredCache.Expire()
 	count += self.routesNotExportedCache.Expire()
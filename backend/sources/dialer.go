@@ -0,0 +1,63 @@
+package sources
+
+/*
+Connections to the source backends are established
+by resolving the host on every dial: When a connection
+is re-established, e.g. after a DNS based failover,
+the current address of the host is used.
+*/
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Resolve the addresses of a host
+var LookupHost = func(ctx context.Context, host string) ([]string, error) {
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+// Dial a connection to an address, resolving the host
+func DialContext(
+	ctx context.Context,
+	network string,
+	address string,
+) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for host: %s", host)
+	}
+
+	// Try all addresses
+	dialer := net.Dialer{}
+	for _, addr := range addrs {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+
+	return nil, err
+}
+
+// Dial a tcp connection with a timeout
+func DialTimeout(address string, timeout time.Duration) (net.Conn, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return DialContext(ctx, "tcp", address)
+}
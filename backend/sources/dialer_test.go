@@ -0,0 +1,52 @@
+package sources
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDialReResolvesHost(t *testing.T) {
+	// Two servers with the same port on different addresses
+	primary, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Close()
+	port := strconv.Itoa(primary.Addr().(*net.TCPAddr).Port)
+
+	secondary, err := net.Listen("tcp", "127.0.0.2:"+port)
+	if err != nil {
+		t.Skip("Could not listen on second loopback address:", err)
+	}
+	defer secondary.Close()
+
+	resolved := "127.0.0.1"
+	lookupHost := LookupHost
+	LookupHost = func(_ context.Context, host string) ([]string, error) {
+		return []string{resolved}, nil
+	}
+	defer func() { LookupHost = lookupHost }()
+
+	conn, err := DialTimeout("rs.example.net:"+port, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.RemoteAddr().String() != "127.0.0.1:"+port {
+		t.Error("Expected connection to primary, got:", conn.RemoteAddr())
+	}
+	conn.Close()
+
+	// Failover: The name now resolves to the secondary
+	resolved = "127.0.0.2"
+	conn, err = DialTimeout("rs.example.net:"+port, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.RemoteAddr().String() != "127.0.0.2:"+port {
+		t.Error("Expected reconnect to secondary, got:", conn.RemoteAddr())
+	}
+	conn.Close()
+}
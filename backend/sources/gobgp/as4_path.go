@@ -0,0 +1,24 @@
+package gobgp
+
+// The AS_TRANS placeholder used by 2-octet speakers
+// in place of ASNs not representable in 16 bit.
+const AS_TRANS = 23456
+
+// Reconstruct the AS path from the AS_PATH and AS4_PATH
+// attributes as described in RFC 6793, Section 4.2.3:
+// If the AS4_PATH is longer than the AS_PATH it is ignored.
+// Otherwise the leading ASNs of the AS_PATH, which were
+// prepended by 2-octet speakers, are kept and the remainder
+// is taken from the AS4_PATH.
+func mergeAs4Path(asPath []int, as4Path []int) []int {
+	if len(as4Path) == 0 || len(as4Path) > len(asPath) {
+		return asPath
+	}
+
+	n := len(asPath) - len(as4Path)
+	merged := make([]int, 0, len(asPath))
+	merged = append(merged, asPath[:n]...)
+	merged = append(merged, as4Path...)
+
+	return merged
+}
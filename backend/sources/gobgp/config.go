@@ -1,5 +1,20 @@
 package gobgp
 
+import (
+	"fmt"
+	"time"
+)
+
+// Connection defaults, when not configured
+const (
+	DEFAULT_DIAL_TIMEOUT = 10 * time.Second
+
+	// The keepalive is disabled by default: GoBGP (as any
+	// gRPC server) closes connections pinging more often
+	// than its enforcement policy allows (5 minutes).
+	DEFAULT_KEEPALIVE_INTERVAL = 0
+)
+
 type Config struct {
 	Id   string
 	Name string
@@ -8,4 +23,38 @@ type Config struct {
 	Insecure      bool   `ini:"insecure"`
 	TLSCert       string `ini:"tls_crt"`
 	TLSCommonName string `ini:"tls_common_name"`
+
+	// Connection timeouts in seconds
+	DialTimeout       int `ini:"dial_timeout"`
+	KeepaliveInterval int `ini:"keepalive_interval"`
+
+	ReconstructAs4Path bool `ini:"reconstruct_as4_path"`
+}
+
+// Get the timeout for establishing a connection
+func (self Config) GetDialTimeout() time.Duration {
+	if self.DialTimeout <= 0 {
+		return DEFAULT_DIAL_TIMEOUT
+	}
+	return time.Duration(self.DialTimeout) * time.Second
+}
+
+// Get the interval of keepalive pings on an idle
+// connection. A zero interval disables the keepalive.
+func (self Config) GetKeepaliveInterval() time.Duration {
+	if self.KeepaliveInterval <= 0 {
+		return DEFAULT_KEEPALIVE_INTERVAL
+	}
+	return time.Duration(self.KeepaliveInterval) * time.Second
+}
+
+// Check the configuration for missing or invalid settings
+func (self Config) Verify() error {
+	if self.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	if !self.Insecure && self.TLSCert == "" {
+		return fmt.Errorf("missing tls_crt, required unless insecure")
+	}
+	return nil
 }
@@ -0,0 +1,42 @@
+package gobgp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-ini/ini"
+)
+
+func TestConfigConnectionSettings(t *testing.T) {
+	parsed, err := ini.Load([]byte(`
+host = rs1.example.net:50051
+insecure = true
+dial_timeout = 3
+keepalive_interval = 300
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{}
+	if err := parsed.Section("").MapTo(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.GetDialTimeout() != 3*time.Second {
+		t.Error("Unexpected dial timeout:", config.GetDialTimeout())
+	}
+	if config.GetKeepaliveInterval() != 300*time.Second {
+		t.Error("Unexpected keepalive interval:",
+			config.GetKeepaliveInterval())
+	}
+
+	// Defaults
+	config = Config{}
+	if config.GetDialTimeout() != DEFAULT_DIAL_TIMEOUT {
+		t.Error("Expected default dial timeout, got:",
+			config.GetDialTimeout())
+	}
+	if config.GetKeepaliveInterval() != 0 {
+		t.Error("Expected keepalive to be disabled by default")
+	}
+}
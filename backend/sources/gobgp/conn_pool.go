@@ -0,0 +1,239 @@
+package gobgp
+
+/*
+gRPC connection pool
+
+Sources targeting the same gRPC endpoint with the same
+TLS settings share a single client connection.
+Connections are reference counted and closed, when the
+last source using the connection is closed.
+
+The state of each connection is watched for diagnostics.
+*/
+
+import (
+	"github.com/alice-lg/alice-lg/backend/api"
+	"github.com/alice-lg/alice-lg/backend/sources"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+type connPoolEntry struct {
+	conn *grpc.ClientConn
+	refs int
+
+	// Diagnostics
+	reconnects int
+	lastError  error
+}
+
+type connPool struct {
+	sync.Mutex
+	conns map[string]*connPoolEntry
+}
+
+// The pool is shared by all gobgp sources
+var sharedConnPool = newConnPool()
+
+func newConnPool() *connPool {
+	return &connPool{
+		conns: make(map[string]*connPoolEntry),
+	}
+}
+
+// Connections are identified by the endpoint, the TLS
+// and the connection settings.
+func connPoolKey(config Config) string {
+	return fmt.Sprintf(
+		"%s|%t|%s|%s|%s|%s",
+		config.Host,
+		config.Insecure,
+		config.TLSCert,
+		config.TLSCommonName,
+		config.GetDialTimeout(),
+		config.GetKeepaliveInterval())
+}
+
+// Make a dialer resolving the host on every (re)connect.
+// The connection attempt is aborted after the timeout.
+func timeoutDialer(
+	timeout time.Duration,
+) func(string, time.Duration) (net.Conn, error) {
+	return func(address string, t time.Duration) (net.Conn, error) {
+		if t <= 0 || t > timeout {
+			t = timeout
+		}
+		return sources.DialTimeout(address, t)
+	}
+}
+
+// Get the keepalive parameters of the connection
+func keepaliveParams(config Config) (keepalive.ClientParameters, bool) {
+	interval := config.GetKeepaliveInterval()
+	if interval <= 0 {
+		return keepalive.ClientParameters{}, false
+	}
+	return keepalive.ClientParameters{
+		Time:                interval,
+		PermitWithoutStream: true,
+	}, true
+}
+
+// Make the options for dialing a client connection
+func dialOptions(config Config) ([]grpc.DialOption, error) {
+	dialOpts := make([]grpc.DialOption, 0)
+	if config.Insecure {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	} else {
+		creds, err := credentials.NewClientTLSFromFile(
+			config.TLSCert, config.TLSCommonName)
+		if err != nil {
+			return nil, fmt.Errorf("could not load tls cert: %s", err)
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+	}
+
+	dialOpts = append(dialOpts, grpc.WithDialer(
+		timeoutDialer(config.GetDialTimeout())))
+
+	if params, ok := keepaliveParams(config); ok {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(params))
+	}
+
+	return dialOpts, nil
+}
+
+// Dial a new client connection
+func dialConn(config Config) (*grpc.ClientConn, error) {
+	dialOpts, err := dialOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Dial(config.Host, dialOpts...)
+}
+
+// Acquire a connection: Reuse an existing connection
+// or dial a new one.
+func (self *connPool) Acquire(config Config) (*grpc.ClientConn, error) {
+	self.Lock()
+	defer self.Unlock()
+
+	key := connPoolKey(config)
+	entry, ok := self.conns[key]
+	if ok {
+		entry.refs++
+		return entry.conn, nil
+	}
+
+	conn, err := dialConn(config)
+	if err != nil {
+		return nil, err
+	}
+
+	entry = &connPoolEntry{
+		conn: conn,
+		refs: 1,
+	}
+	self.conns[key] = entry
+
+	go self.watch(entry)
+
+	return conn, nil
+}
+
+// Watch the connectivity state of a connection and
+// count reconnects, until the connection is closed.
+func (self *connPool) watch(entry *connPoolEntry) {
+	state := entry.conn.GetState()
+	wasReady := state == connectivity.Ready
+	for state != connectivity.Shutdown {
+		entry.conn.WaitForStateChange(context.Background(), state)
+		state = entry.conn.GetState()
+		if state != connectivity.Ready {
+			continue
+		}
+		if wasReady {
+			self.Lock()
+			entry.reconnects++
+			self.Unlock()
+		}
+		wasReady = true
+	}
+}
+
+// Release a connection. The connection is closed
+// when there are no more references.
+func (self *connPool) Release(config Config) error {
+	self.Lock()
+	defer self.Unlock()
+
+	key := connPoolKey(config)
+	entry, ok := self.conns[key]
+	if !ok {
+		return nil
+	}
+
+	entry.refs--
+	if entry.refs > 0 {
+		return nil
+	}
+
+	delete(self.conns, key)
+	return entry.conn.Close()
+}
+
+// Count references of a connection
+func (self *connPool) Refs(config Config) int {
+	self.Lock()
+	defer self.Unlock()
+
+	entry, ok := self.conns[connPoolKey(config)]
+	if !ok {
+		return 0
+	}
+	return entry.refs
+}
+
+// Remember the last error of a request using
+// the connection.
+func (self *connPool) RecordError(config Config, err error) {
+	self.Lock()
+	defer self.Unlock()
+
+	entry, ok := self.conns[connPoolKey(config)]
+	if !ok {
+		return
+	}
+	entry.lastError = err
+}
+
+// Get the diagnostics of a connection
+func (self *connPool) Status(config Config) *api.ConnectionStatus {
+	self.Lock()
+	defer self.Unlock()
+
+	entry, ok := self.conns[connPoolKey(config)]
+	if !ok {
+		return &api.ConnectionStatus{
+			State: connectivity.Shutdown.String(),
+		}
+	}
+
+	status := &api.ConnectionStatus{
+		State:      entry.conn.GetState().String(),
+		Reconnects: entry.reconnects,
+	}
+	if entry.lastError != nil {
+		status.LastError = entry.lastError.Error()
+	}
+	return status
+}
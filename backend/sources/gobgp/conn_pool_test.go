@@ -0,0 +1,226 @@
+package gobgp
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConnPoolSharedConnection(t *testing.T) {
+	pool := newConnPool()
+	config := Config{
+		Id:       "rs1",
+		Host:     "localhost:50051",
+		Insecure: true,
+	}
+	other := Config{
+		Id:       "rs2",
+		Host:     "localhost:50051",
+		Insecure: true,
+	}
+
+	conn1, err := pool.Acquire(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn2, err := pool.Acquire(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conn1 != conn2 {
+		t.Error("Expected sources with the same endpoint to share a connection")
+	}
+	if pool.Refs(config) != 2 {
+		t.Error("Expected 2 references, got:", pool.Refs(config))
+	}
+
+	// A different endpoint uses a new connection
+	conn3, err := pool.Acquire(Config{
+		Id:       "rs3",
+		Host:     "localhost:50052",
+		Insecure: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn3 == conn1 {
+		t.Error("Expected a new connection for a different endpoint")
+	}
+
+	// Release connections
+	if err := pool.Release(config); err != nil {
+		t.Error(err)
+	}
+	if pool.Refs(other) != 1 {
+		t.Error("Expected 1 reference, got:", pool.Refs(other))
+	}
+	if err := pool.Release(other); err != nil {
+		t.Error(err)
+	}
+	if pool.Refs(other) != 0 {
+		t.Error("Expected connection to be released")
+	}
+}
+
+func TestConnectionStatusFailedConnection(t *testing.T) {
+	config := Config{
+		Id:       "rs-unreachable",
+		Host:     "127.0.0.1:1",
+		Insecure: true,
+	}
+	source := NewGoBGP(config)
+
+	_, err := source.Status()
+	if err == nil {
+		t.Fatal("Expected status request to fail")
+	}
+
+	status := source.ConnectionStatus()
+	if status.State == "READY" {
+		t.Error("Expected connection not to be ready")
+	}
+	if status.LastError == "" {
+		t.Error("Expected last error to be reported")
+	}
+
+	source.Close()
+	status = source.ConnectionStatus()
+	if status.State != "SHUTDOWN" {
+		t.Error("Expected closed connection to be shut down, got:", status.State)
+	}
+}
+
+func TestConnectionStatusRecordsErrors(t *testing.T) {
+	config := Config{
+		Id:       "rs-unreachable",
+		Host:     "127.0.0.1:1",
+		Insecure: true,
+	}
+	source := NewGoBGP(config)
+	defer source.Close()
+
+	calls := map[string]func() error{
+		"Neighbours": func() error {
+			_, err := source.Neighbours()
+			return err
+		},
+		"NeighboursStatus": func() error {
+			_, err := source.NeighboursStatus()
+			return err
+		},
+		"Routes": func() error {
+			_, err := source.Routes("peer1")
+			return err
+		},
+		"AllRoutes": func() error {
+			_, err := source.AllRoutes()
+			return err
+		},
+	}
+	for name, call := range calls {
+		sharedConnPool.RecordError(config, nil)
+		if err := call(); err == nil {
+			t.Error("Expected request to fail:", name)
+		}
+		if sharedConnPool.Status(config).LastError == "" {
+			t.Error("Expected the error to be recorded:", name)
+		}
+	}
+}
+
+func TestDialOptions(t *testing.T) {
+	config := Config{
+		Host:     "localhost:50051",
+		Insecure: true,
+	}
+
+	// Insecure and dialer
+	opts, err := dialOptions(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 2 {
+		t.Error("Expected 2 dial options, got:", len(opts))
+	}
+	if _, ok := keepaliveParams(config); ok {
+		t.Error("Expected keepalive to be disabled")
+	}
+
+	// Keepalive
+	config.KeepaliveInterval = 60
+	opts, err = dialOptions(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 3 {
+		t.Error("Expected 3 dial options, got:", len(opts))
+	}
+	params, ok := keepaliveParams(config)
+	if !ok {
+		t.Fatal("Expected keepalive to be enabled")
+	}
+	if params.Time != 60*time.Second {
+		t.Error("Unexpected keepalive interval:", params.Time)
+	}
+
+	// Sources with different settings do not share a connection
+	other := config
+	other.DialTimeout = 3
+	if connPoolKey(config) == connPoolKey(other) {
+		t.Error("Expected different connection pool keys")
+	}
+}
+
+func TestTimeoutDialer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	dial := timeoutDialer(time.Second)
+	conn, err := dial(listener.Addr().String(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestGoBGPCloseReleasesOnce(t *testing.T) {
+	config := Config{
+		Id:       "rs1",
+		Host:     "localhost:50053",
+		Insecure: true,
+	}
+	other := Config{
+		Id:       "rs2",
+		Host:     "localhost:50053",
+		Insecure: true,
+	}
+
+	rs1 := NewGoBGP(config)
+	rs2 := NewGoBGP(other)
+	if sharedConnPool.Refs(config) != 2 {
+		t.Fatal("Expected 2 references, got:", sharedConnPool.Refs(config))
+	}
+
+	// Closing a source twice must not release
+	// the connection of the other source.
+	if err := rs1.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := rs1.Close(); err != nil {
+		t.Error(err)
+	}
+	if sharedConnPool.Refs(other) != 1 {
+		t.Error("Expected 1 reference, got:", sharedConnPool.Refs(other))
+	}
+
+	if err := rs2.Close(); err != nil {
+		t.Error(err)
+	}
+	if sharedConnPool.Refs(other) != 0 {
+		t.Error("Expected connection to be released")
+	}
+}
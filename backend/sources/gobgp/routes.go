@@ -53,7 +53,7 @@ func (gobgp *GoBGP) GetNeighbours() ([]*gobgpapi.Peer, error) {
 
 	peerStream, err := gobgp.client.ListPeer(ctx, &gobgpapi.ListPeerRequest{EnableAdvertised: true})
 	if err != nil {
-		return nil, err
+		return nil, gobgp.recordError(err)
 	}
 
 	peers := make([]*gobgpapi.Peer, 0)
@@ -62,6 +62,8 @@ func (gobgp *GoBGP) GetNeighbours() ([]*gobgpapi.Peer, error) {
 		peer, err := peerStream.Recv()
 		if err == io.EOF {
 			break
+		} else if err != nil {
+			return nil, gobgp.recordError(err)
 		}
 		peers = append(peers, peer.Peer)
 	}
@@ -74,7 +76,8 @@ func (gobgp *GoBGP) parsePathIntoRoute(path *gobgpapi.Path, prefix string) (erro
 	route.Id = fmt.Sprintf("%s_%s", path.SourceId, prefix)
 	route.NeighbourId = PeerHashWithASAndAddress(path.SourceAsn, path.NeighborIp)
 	route.Network = prefix
-	route.Interface = "Unknown"
+	// The gobgp API does not provide the ingress interface
+	route.Interface = ""
 	route.Age = time.Now().Sub(time.Unix(path.Age.GetSeconds(), int64(path.Age.GetNanos())))
 	route.Primary = path.Best
 
@@ -87,6 +90,13 @@ func (gobgp *GoBGP) parsePathIntoRoute(path *gobgpapi.Path, prefix string) (erro
 	route.Bgp.LargeCommunities = make(api.Communities, 0)
 	route.Bgp.ExtCommunities = make(api.ExtCommunities, 0)
 
+	route.AttributeCount = len(attrs)
+	for _, attr := range attrs {
+		route.AttributeBytes += attr.Len()
+	}
+
+	as4Path := []int{}
+
 	for _, attr := range attrs {
 		switch attr.(type) {
 		case *bgp.PathAttributeMultiExitDisc:
@@ -116,6 +126,13 @@ func (gobgp *GoBGP) parsePathIntoRoute(path *gobgpapi.Path, prefix string) (erro
 					route.Bgp.AsPath = append(route.Bgp.AsPath, int(as))
 				}
 			}
+		case *bgp.PathAttributeAs4Path:
+			aspath := attr.(*bgp.PathAttributeAs4Path)
+			for _, aspth := range aspath.Value {
+				for _, as := range aspth.AS {
+					as4Path = append(as4Path, int(as))
+				}
+			}
 		case *bgp.PathAttributeCommunities:
 			communities := attr.(*bgp.PathAttributeCommunities)
 			for _, community := range communities.Value {
@@ -138,6 +155,10 @@ func (gobgp *GoBGP) parsePathIntoRoute(path *gobgpapi.Path, prefix string) (erro
 		}
 	}
 
+	if gobgp.config.ReconstructAs4Path {
+		route.Bgp.AsPath = mergeAs4Path(route.Bgp.AsPath, as4Path)
+	}
+
 	route.Metric = (route.Bgp.LocalPref + route.Bgp.Med)
 
 	return nil, &route
@@ -157,7 +178,7 @@ func (gobgp *GoBGP) GetRoutes(peer *gobgpapi.Peer, tableType gobgpapi.TableType,
 		})
 
 		if err != nil {
-			log.Print(err)
+			log.Print(gobgp.recordError(err))
 			continue
 		}
 
@@ -168,7 +189,7 @@ func (gobgp *GoBGP) GetRoutes(peer *gobgpapi.Peer, tableType gobgpapi.TableType,
 				break
 			} else if err != nil {
 				log.Print(err)
-				return err
+				return gobgp.recordError(err)
 			}
 			rib = append(rib, _path.Destination)
 		}
@@ -0,0 +1,126 @@
+package gobgp
+
+import (
+	"testing"
+
+	"github.com/alice-lg/alice-lg/backend/sources/gobgp/apiutil"
+	gobgpapi "github.com/osrg/gobgp/api"
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+)
+
+func TestParsePathIntoRoute(t *testing.T) {
+	path := &gobgpapi.Path{
+		SourceId:   "192.0.2.1",
+		NeighborIp: "192.0.2.1",
+		SourceAsn:  2342,
+		Best:       true,
+		Pattrs: apiutil.MarshalPathAttributes([]bgp.PathAttributeInterface{
+			bgp.NewPathAttributeNextHop("192.0.2.1"),
+		}),
+	}
+
+	gobgp := &GoBGP{}
+	err, route := gobgp.parsePathIntoRoute(path, "10.23.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if route.Gateway != "192.0.2.1" {
+		t.Error("Unexpected gateway:", route.Gateway)
+	}
+
+	// The ingress interface is not provided by gobgp
+	if route.Interface != "" {
+		t.Error("Expected blank interface, got:", route.Interface)
+	}
+}
+
+func TestMergeAs4Path(t *testing.T) {
+	// Two 2-octet speakers prepended to a path with AS_TRANS
+	asPath := []int{2342, 23, AS_TRANS, AS_TRANS}
+	as4Path := []int{4200000001, 4200000002}
+
+	path := mergeAs4Path(asPath, as4Path)
+	expected := []int{2342, 23, 4200000001, 4200000002}
+	if len(path) != len(expected) {
+		t.Fatal("Unexpected path:", path)
+	}
+	for i, as := range expected {
+		if path[i] != as {
+			t.Error("Unexpected path:", path)
+		}
+	}
+
+	// An AS4_PATH longer than the AS_PATH is ignored
+	path = mergeAs4Path([]int{AS_TRANS}, as4Path)
+	if len(path) != 1 || path[0] != AS_TRANS {
+		t.Error("Expected AS4_PATH to be ignored, got:", path)
+	}
+}
+
+func TestParsePathIntoRouteAs4Path(t *testing.T) {
+	path := &gobgpapi.Path{
+		SourceId:   "192.0.2.1",
+		NeighborIp: "192.0.2.1",
+		SourceAsn:  2342,
+		Pattrs: apiutil.MarshalPathAttributes([]bgp.PathAttributeInterface{
+			bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
+				bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ,
+					[]uint32{2342, AS_TRANS}),
+			}),
+			bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{
+				bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ,
+					[]uint32{4200000001}),
+			}),
+		}),
+	}
+
+	// Without reconstruction the AS_PATH is used as is
+	gobgp := &GoBGP{}
+	err, route := gobgp.parsePathIntoRoute(path, "10.23.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(route.Bgp.AsPath) != 2 || route.Bgp.AsPath[1] != AS_TRANS {
+		t.Error("Unexpected as path:", route.Bgp.AsPath)
+	}
+
+	gobgp = &GoBGP{config: Config{ReconstructAs4Path: true}}
+	err, route = gobgp.parsePathIntoRoute(path, "10.23.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(route.Bgp.AsPath) != 2 ||
+		route.Bgp.AsPath[0] != 2342 ||
+		route.Bgp.AsPath[1] != 4200000001 {
+		t.Error("Unexpected as path:", route.Bgp.AsPath)
+	}
+}
+
+func TestParsePathIntoRouteAttributes(t *testing.T) {
+	attrs := []bgp.PathAttributeInterface{
+		bgp.NewPathAttributeNextHop("192.0.2.1"),
+		bgp.NewPathAttributeCommunities([]uint32{0xffff0001, 0x5c1e0017}),
+		bgp.NewPathAttributeLocalPref(100),
+	}
+	path := &gobgpapi.Path{
+		SourceId:   "192.0.2.1",
+		NeighborIp: "192.0.2.1",
+		SourceAsn:  2342,
+		Pattrs:     apiutil.MarshalPathAttributes(attrs),
+	}
+
+	gobgp := &GoBGP{}
+	err, route := gobgp.parsePathIntoRoute(path, "10.23.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if route.AttributeCount != 3 {
+		t.Error("Expected 3 attributes, got:", route.AttributeCount)
+	}
+	// NEXT_HOP: 3 + 4, COMMUNITIES: 3 + 8, LOCAL_PREF: 3 + 4
+	if route.AttributeBytes != 25 {
+		t.Error("Expected 25 bytes, got:", route.AttributeBytes)
+	}
+}
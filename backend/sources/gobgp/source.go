@@ -4,14 +4,11 @@ import (
 	api "github.com/alice-lg/alice-lg/backend/api"
 	"github.com/alice-lg/alice-lg/backend/caches"
 	gobgpapi "github.com/osrg/gobgp/api"
-	"google.golang.org/grpc/credentials"
-
-	"google.golang.org/grpc"
-
 	"context"
 	"fmt"
 	"io"
 	"log"
+	"sync"
 	"time"
 )
 
@@ -19,6 +16,10 @@ type GoBGP struct {
 	config Config
 	client gobgpapi.GobgpApiClient
 
+	// The shared connection is released once
+	closeOnce sync.Once
+	closeErr  error
+
 	// Caches: Neighbors
 	neighborsCache *caches.NeighborsCache
 
@@ -31,18 +32,7 @@ type GoBGP struct {
 
 func NewGoBGP(config Config) *GoBGP {
 
-	dialOpts := make([]grpc.DialOption, 0)
-	if config.Insecure {
-		dialOpts = append(dialOpts, grpc.WithInsecure())
-	} else {
-		creds, err := credentials.NewClientTLSFromFile(config.TLSCert, config.TLSCommonName)
-		if err != nil {
-			log.Fatalf("could not load tls cert: %s", err)
-		}
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
-	}
-
-	conn, err := grpc.Dial(config.Host, dialOpts...)
+	conn, err := sharedConnPool.Acquire(config)
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
@@ -80,6 +70,29 @@ func NewGoBGP(config Config) *GoBGP {
 	}
 }
 
+// Close the source and release the shared connection.
+// Closing the source again has no effect.
+func (gobgp *GoBGP) Close() error {
+	gobgp.closeOnce.Do(func() {
+		gobgp.closeErr = sharedConnPool.Release(gobgp.config)
+	})
+	return gobgp.closeErr
+}
+
+// Get the diagnostics of the gRPC connection
+func (gobgp *GoBGP) ConnectionStatus() *api.ConnectionStatus {
+	return sharedConnPool.Status(gobgp.config)
+}
+
+// Remember the error of a request in the diagnostics
+// of the connection and pass it on
+func (gobgp *GoBGP) recordError(err error) error {
+	if err != nil {
+		sharedConnPool.RecordError(gobgp.config, err)
+	}
+	return err
+}
+
 func (gobgp *GoBGP) ExpireCaches() int {
 	count := gobgp.routesRequiredCache.Expire()
 	count += gobgp.routesNotExportedCache.Expire()
@@ -96,23 +109,20 @@ func (gobgp *GoBGP) NeighboursStatus() (*api.NeighboursStatusResponse, error) {
 
 	resp, err := gobgp.client.ListPeer(ctx, &gobgpapi.ListPeerRequest{})
 	if err != nil {
-		return nil, err
+		return nil, gobgp.recordError(err)
 	}
 	for {
 		_resp, err := resp.Recv()
 		if err == io.EOF {
 			break
+		} else if err != nil {
+			return nil, gobgp.recordError(err)
 		}
 
 		ns := api.NeighbourStatus{}
 		ns.Id = PeerHash(_resp.Peer)
 
-		switch _resp.Peer.State.SessionState {
-		case gobgpapi.PeerState_ESTABLISHED:
-			ns.State = "up"
-		default:
-			ns.State = "down"
-		}
+		ns.State, _ = parseSessionState(_resp.Peer.State.SessionState)
 
 		if _resp.Peer.Timers.State.Uptime != nil {
 			ns.Since = time.Now().Sub(time.Unix(_resp.Peer.Timers.State.Uptime.Seconds, int64(_resp.Peer.Timers.State.Uptime.Nanos)))
@@ -128,15 +138,27 @@ func (gobgp *GoBGP) Status() (*api.StatusResponse, error) {
 
 	resp, err := gobgp.client.GetBgp(ctx, &gobgpapi.GetBgpRequest{})
 	if err != nil {
-		return nil, err
+		return nil, gobgp.recordError(err)
 	}
 
 	response := api.StatusResponse{}
-	response.Status.RouterId = resp.Global.RouterId
-	response.Status.Backend = "gobgp"
+	response.Status = parseGlobalStatus(resp.Global)
 	return &response, nil
 }
 
+// Get the router id and local AS from the global config
+func parseGlobalStatus(global *gobgpapi.Global) api.Status {
+	status := api.Status{
+		Backend: "gobgp",
+	}
+	if global == nil {
+		return status
+	}
+	status.RouterId = global.RouterId
+	status.LocalAs = int(global.As)
+	return status
+}
+
 func (gobgp *GoBGP) Neighbours() (*api.NeighboursResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -146,24 +168,22 @@ func (gobgp *GoBGP) Neighbours() (*api.NeighboursResponse, error) {
 
 	resp, err := gobgp.client.ListPeer(ctx, &gobgpapi.ListPeerRequest{EnableAdvertised: true})
 	if err != nil {
-		return nil, err
+		return nil, gobgp.recordError(err)
 	}
 	for {
 		_resp, err := resp.Recv()
 		if err == io.EOF {
 			break
+		} else if err != nil {
+			return nil, gobgp.recordError(err)
 		}
 
 		neigh := api.Neighbour{}
 
 		neigh.Address = _resp.Peer.State.NeighborAddress
 		neigh.Asn = int(_resp.Peer.State.PeerAs)
-		switch _resp.Peer.State.SessionState {
-		case gobgpapi.PeerState_ESTABLISHED:
-			neigh.State = "up"
-		default:
-			neigh.State = "down"
-		}
+		neigh.State, neigh.StateRaw = parseSessionState(
+			_resp.Peer.State.SessionState)
 		neigh.Description = _resp.Peer.Conf.Description
 
 		neigh.Id = PeerHash(_resp.Peer)
@@ -0,0 +1,29 @@
+package gobgp
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/api"
+)
+
+func TestParseGlobalStatus(t *testing.T) {
+	status := parseGlobalStatus(&gobgpapi.Global{
+		As:       64512,
+		RouterId: "192.0.2.1",
+	})
+	if status.RouterId != "192.0.2.1" {
+		t.Error("Unexpected router id:", status.RouterId)
+	}
+	if status.LocalAs != 64512 {
+		t.Error("Unexpected local AS:", status.LocalAs)
+	}
+	if status.Backend != "gobgp" {
+		t.Error("Unexpected backend:", status.Backend)
+	}
+
+	// Without global config the fields are left blank
+	status = parseGlobalStatus(nil)
+	if status.RouterId != "" || status.LocalAs != 0 {
+		t.Error("Expected blank status, got:", status)
+	}
+}
@@ -22,3 +22,12 @@ func PeerHashWithASAndAddress(asn uint32, address string) string {
 	sum := h.Sum(nil)
 	return fmt.Sprintf("%x", sum[0:5])
 }
+
+// Map the session state to the neighbour state,
+// the raw state is returned as well.
+func parseSessionState(state api.PeerState_SessionState) (string, string) {
+	if state == api.PeerState_ESTABLISHED {
+		return "up", state.String()
+	}
+	return "down", state.String()
+}
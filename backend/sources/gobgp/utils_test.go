@@ -0,0 +1,19 @@
+package gobgp
+
+import (
+	"testing"
+
+	api "github.com/osrg/gobgp/api"
+)
+
+func TestParseSessionState(t *testing.T) {
+	state, raw := parseSessionState(api.PeerState_ESTABLISHED)
+	if state != "up" || raw != "ESTABLISHED" {
+		t.Error("unexpected state:", state, raw)
+	}
+
+	state, raw = parseSessionState(api.PeerState_OPENCONFIRM)
+	if state != "down" || raw != "OPENCONFIRM" {
+		t.Error("unexpected state:", state, raw)
+	}
+}
@@ -15,3 +15,9 @@ type Source interface {
 	RoutesNotExported(neighbourId string) (*api.RoutesResponse, error)
 	AllRoutes() (*api.RoutesResponse, error)
 }
+
+// Sources maintaining a persistent connection to the
+// backend can provide connection diagnostics.
+type ConnectionStatusSource interface {
+	ConnectionStatus() *api.ConnectionStatus
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-ini/ini"
+)
+
+// Resolve a path relative to the location
+// of the main configuration file.
+func resolveConfigPath(path string, configFile string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(configFile), path)
+}
+
+// Load additional source definitions from all *.conf
+// files in a directory and append them to the sources.
+// The files are loaded ordered by filename.
+func loadSourcesDir(
+	dir string,
+	sources []*SourceConfig,
+) ([]*SourceConfig, error) {
+	return loadSourcesGlobs([]string{filepath.Join(dir, "*.conf")}, sources)
+}
+
+// Get the included source files from the [include] section.
+// The patterns are resolved relative to the configuration file.
+func getSourcesIncludes(config *ini.File, configFile string) []string {
+	patterns := config.Section("include").Key("sources").Strings(",")
+	for i, pattern := range patterns {
+		patterns[i] = resolveConfigPath(pattern, configFile)
+	}
+	return patterns
+}
+
+// Load additional source definitions from all files
+// matching the glob patterns and append them to the
+// sources. The files matching a pattern are loaded
+// ordered by filename.
+func loadSourcesGlobs(
+	patterns []string,
+	sources []*SourceConfig,
+) ([]*SourceConfig, error) {
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", pattern, err)
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			sources, err = loadSourcesFile(file, sources)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return sources, nil
+}
+
+// Load the source definitions of a file and
+// append them to the sources.
+func loadSourcesFile(
+	file string,
+	sources []*SourceConfig,
+) ([]*SourceConfig, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDuplicateSourceIds(data); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+
+	parsed, err := ini.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	expandConfigEnv(parsed)
+
+	fileSources, err := getSources(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+
+	return mergeSources(sources, fileSources, file)
+}
+
+// Append sources to a list of sources. The order of the
+// appended sources continues the order of the list.
+// A source id must not be defined more than once.
+func mergeSources(
+	sources []*SourceConfig,
+	additional []*SourceConfig,
+	origin string,
+) ([]*SourceConfig, error) {
+	ids := make(map[string]bool)
+	for _, source := range sources {
+		ids[source.Id] = true
+	}
+
+	merged := append([]*SourceConfig{}, sources...)
+	for _, source := range additional {
+		if ids[source.Id] {
+			return nil, fmt.Errorf(
+				"%s: duplicate source id: %s", origin, source.Id)
+		}
+		ids[source.Id] = true
+
+		source.Order = len(merged)
+		merged = append(merged, source)
+	}
+
+	return merged, nil
+}
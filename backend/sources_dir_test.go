@@ -0,0 +1,168 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func writeTestSourceFile(t *testing.T, dir, name, id string) {
+	content := "[source." + id + "]\n" +
+		"name = " + id + "\n" +
+		"[source." + id + ".birdwatcher]\n" +
+		"api = http://" + id + ":29184/\n" +
+		"type = multi_table\n"
+
+	err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSourcesDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alice-sources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestSourceFile(t, dir, "20-rs2.conf", "rs2")
+	writeTestSourceFile(t, dir, "10-rs1.conf", "rs1")
+	writeTestSourceFile(t, dir, "30-ignored.txt", "rs3")
+
+	main := []*SourceConfig{
+		&SourceConfig{Id: "rs0", Order: 0},
+	}
+
+	sources, err := loadSourcesDir(dir, main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 3 {
+		t.Fatal("expected 3 sources, got:", len(sources))
+	}
+
+	for i, id := range []string{"rs0", "rs1", "rs2"} {
+		if sources[i].Id != id {
+			t.Error("expected source", id, "at", i, "got:", sources[i].Id)
+		}
+		if sources[i].Order != i {
+			t.Error("unexpected order for", id, ":", sources[i].Order)
+		}
+	}
+
+	if sources[1].Type != SOURCE_BIRDWATCHER {
+		t.Error("expected birdwatcher source, got:", sources[1].Type)
+	}
+}
+
+func TestLoadSourcesDirDuplicateId(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alice-sources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestSourceFile(t, dir, "10-rs1.conf", "rs1")
+	writeTestSourceFile(t, dir, "20-rs1.conf", "rs1")
+
+	_, err = loadSourcesDir(dir, []*SourceConfig{})
+	if err == nil {
+		t.Fatal("expected duplicate source id to be rejected")
+	}
+	if !strings.Contains(err.Error(), "duplicate source id: rs1") ||
+		!strings.Contains(err.Error(), "20-rs1.conf") {
+		t.Error("unexpected error:", err)
+	}
+
+	// Duplicates with the main config are rejected as well
+	dir2, err := ioutil.TempDir("", "alice-sources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir2)
+	writeTestSourceFile(t, dir2, "rs0.conf", "rs0")
+
+	_, err = loadSourcesDir(dir2, []*SourceConfig{
+		&SourceConfig{Id: "rs0"},
+	})
+	if err == nil {
+		t.Error("expected duplicate of main config source to be rejected")
+	}
+}
+
+func TestResolveConfigPath(t *testing.T) {
+	dir := resolveConfigPath("sources.d", "/etc/alice-lg/alice.conf")
+	if dir != "/etc/alice-lg/sources.d" {
+		t.Error("unexpected sources dir:", dir)
+	}
+	dir = resolveConfigPath("/srv/sources", "/etc/alice-lg/alice.conf")
+	if dir != "/srv/sources" {
+		t.Error("unexpected sources dir:", dir)
+	}
+}
+
+func TestLoadSourcesIncludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alice-sources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "conf.d"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestSourceFile(t, dir, "conf.d/rs2.conf", "rs2")
+	writeTestSourceFile(t, dir, "conf.d/rs1.conf", "rs1")
+	writeTestSourceFile(t, dir, "rs3.ini", "rs3")
+
+	configFile := filepath.Join(dir, "alice.conf")
+	parsed, err := ini.Load([]byte(
+		"[include]\nsources = conf.d/*.conf, rs3.ini\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns := getSourcesIncludes(parsed, configFile)
+	if len(patterns) != 2 ||
+		patterns[0] != filepath.Join(dir, "conf.d/*.conf") {
+		t.Fatal("unexpected include patterns:", patterns)
+	}
+
+	sources, err := loadSourcesGlobs(patterns, []*SourceConfig{
+		&SourceConfig{Id: "rs0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 4 {
+		t.Fatal("expected 4 sources, got:", len(sources))
+	}
+	for i, id := range []string{"rs0", "rs1", "rs2", "rs3"} {
+		if sources[i].Id != id || sources[i].Order != i {
+			t.Error("expected source", id, "at", i, "got:", sources[i].Id)
+		}
+	}
+
+	// Source ids must be unique across the included files
+	writeTestSourceFile(t, dir, "conf.d/rs3.conf", "rs3")
+	_, err = loadSourcesGlobs(patterns, []*SourceConfig{})
+	if err == nil {
+		t.Fatal("expected duplicate source id to be rejected")
+	}
+	if !strings.Contains(err.Error(), "duplicate source id: rs3") ||
+		!strings.Contains(err.Error(), "rs3.ini") {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestLoadSourcesIncludesInvalidPattern(t *testing.T) {
+	_, err := loadSourcesGlobs([]string{"[.conf"}, []*SourceConfig{})
+	if err == nil {
+		t.Error("expected invalid pattern to be rejected")
+	}
+}
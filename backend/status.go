@@ -7,6 +7,9 @@ type AppStatus struct {
 	Version    string               `json:"version"`
 	Routes     RoutesStoreStats     `json:"routes"`
 	Neighbours NeighboursStoreStats `json:"neighbours"`
+
+	// Hash of the source configuration, if enabled
+	ConfigHash string `json:"config_hash,omitempty"`
 }
 
 // Get application status, perform health checks
@@ -27,5 +30,8 @@ func NewAppStatus() (*AppStatus, error) {
 		Routes:     routesStatus,
 		Neighbours: neighboursStatus,
 	}
+	if AliceConfig != nil && AliceConfig.Server.IncludeConfigHash {
+		status.ConfigHash = AliceConfig.SourcesHash
+	}
 	return status, nil
 }
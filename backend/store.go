@@ -15,6 +15,106 @@ type StoreStatus struct {
 	LastRefresh time.Time
 	LastError   error
 	State       int
+
+	// Refresh progress
+	RefreshStartedAt    time.Time
+	LastRefreshDuration time.Duration
+
+	// Refreshing is suspended
+	Maintenance bool
+
+	// The last refresh returned fewer routes
+	// than the threshold of the source
+	BelowMinRoutes bool
+
+	// The refresh failed and the data of the last
+	// successful refresh is served
+	LastSuccessfulRefresh time.Time
+	Stale                 bool
+}
+
+// Begin a refresh: The last refresh and its
+// duration are kept, so an ETA can be estimated.
+func (status StoreStatus) refreshing() StoreStatus {
+	return StoreStatus{
+		State:                 STATE_UPDATING,
+		LastRefresh:           status.LastRefresh,
+		RefreshStartedAt:      time.Now(),
+		LastRefreshDuration:   status.LastRefreshDuration,
+		LastSuccessfulRefresh: status.LastSuccessfulRefresh,
+		Stale:                 status.Stale,
+	}
+}
+
+// Fail a refresh: Within the stale grace period of the
+// source, the data of the last successful refresh is
+// served and flagged stale.
+func (status StoreStatus) failed(
+	err error,
+	source *SourceConfig,
+) StoreStatus {
+	lastSuccess := status.LastSuccessfulRefresh
+	stale := source.StaleGracePeriod > 0 &&
+		!lastSuccess.IsZero() &&
+		time.Since(lastSuccess) <= source.StaleGracePeriod
+
+	return StoreStatus{
+		State:                 STATE_ERROR,
+		LastError:             err,
+		LastRefresh:           time.Now(),
+		LastRefreshDuration:   status.refreshDuration(),
+		LastSuccessfulRefresh: lastSuccess,
+		Stale:                 stale,
+	}
+}
+
+// Check if the data of a failed source expired and
+// should be cleared
+func (status StoreStatus) staleExpired(source *SourceConfig) bool {
+	return status.State == STATE_ERROR &&
+		source.StaleGracePeriod > 0 &&
+		!status.Stale
+}
+
+// The data of the source can be served: The source is
+// ready or serves stale data after a failed refresh.
+func (status StoreStatus) IsAvailable() bool {
+	return status.State == STATE_READY || status.Stale
+}
+
+// Suspend refreshing: The data and state of
+// the last refresh are kept.
+func (status StoreStatus) maintenance() StoreStatus {
+	status.Maintenance = true
+	return status
+}
+
+// No refresh of the source completed yet
+func (status StoreStatus) IsLoading() bool {
+	return status.LastRefresh.IsZero()
+}
+
+// Check if any of the sources was not refreshed yet
+func anyStoreStatusLoading(statusMap map[string]StoreStatus) bool {
+	for _, status := range statusMap {
+		if status.IsLoading() {
+			return true
+		}
+	}
+	return false
+}
+
+// The refresh is in progress
+func (status StoreStatus) IsRefreshing() bool {
+	return status.State == STATE_UPDATING
+}
+
+// Get the duration of the refresh, ending now
+func (status StoreStatus) refreshDuration() time.Duration {
+	if status.RefreshStartedAt.IsZero() {
+		return 0
+	}
+	return time.Since(status.RefreshStartedAt)
 }
 
 // Helper: stateToString
@@ -31,3 +131,29 @@ func stateToString(state int) string {
 	}
 	return "INVALID"
 }
+
+// Retry a failed fetch from a source with exponential
+// backoff: The delay is doubled after each attempt.
+func retryWithBackoff(
+	retries int,
+	delay time.Duration,
+	fetch func() error,
+) error {
+	err := fetch()
+	for i := 0; err != nil && i < retries; i++ {
+		time.Sleep(delay)
+		delay *= 2
+		err = fetch()
+	}
+	return err
+}
+
+// Get the number of retries for fetching from a source:
+// Only the initial refresh of a source is retried,
+// so a source still starting up is not marked failed.
+func (status StoreStatus) fetchRetries(source *SourceConfig) int {
+	if status.State != STATE_INIT {
+		return 0
+	}
+	return source.StartupRetries
+}
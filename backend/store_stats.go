@@ -5,6 +5,31 @@ import (
 	"time"
 )
 
+// Refresh progress of a source. The duration of
+// the last refresh can be used to estimate an ETA.
+type RefreshStats struct {
+	Refreshing          bool       `json:"refreshing"`
+	StartedAt           *time.Time `json:"started_at"`
+	LastRefreshDuration float64    `json:"last_refresh_duration"` // seconds
+	Maintenance         bool       `json:"maintenance"`
+	BelowMinRoutes      bool       `json:"below_min_routes"`
+}
+
+// Make refresh stats from a store status
+func makeRefreshStats(status StoreStatus) RefreshStats {
+	stats := RefreshStats{
+		Refreshing:          status.IsRefreshing(),
+		LastRefreshDuration: status.LastRefreshDuration.Seconds(),
+		Maintenance:         status.Maintenance,
+		BelowMinRoutes:      status.BelowMinRoutes,
+	}
+	if stats.Refreshing {
+		startedAt := status.RefreshStartedAt
+		stats.StartedAt = &startedAt
+	}
+	return stats
+}
+
 // Routes Store
 
 type RoutesStats struct {
@@ -18,6 +43,8 @@ type RouteServerRoutesStats struct {
 
 	State     string    `json:"state"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	Refresh RefreshStats `json:"refresh"`
 }
 
 type RoutesStoreStats struct {
@@ -49,14 +76,18 @@ func (stats RoutesStoreStats) Log() {
 // Neighbours Store
 
 type RouteServerNeighboursStats struct {
-	Name       string    `json:"name"`
-	State      string    `json:"state"`
-	Neighbours int       `json:"neighbours"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	Name         string    `json:"name"`
+	State        string    `json:"state"`
+	Neighbours   int       `json:"neighbours"`
+	NeighboursUp int       `json:"neighbours_up"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Refresh RefreshStats `json:"refresh"`
 }
 
 type NeighboursStoreStats struct {
-	TotalNeighbours int `json:"total_neighbours"`
+	TotalNeighbours   int `json:"total_neighbours"`
+	TotalNeighboursUp int `json:"total_neighbours_up"`
 
 	RouteServers []RouteServerNeighboursStats `json:"route_servers"`
 }
@@ -66,13 +97,17 @@ func (stats NeighboursStoreStats) Log() {
 	log.Println("Neighbours store:")
 
 	log.Println("    Neighbours:",
-		stats.TotalNeighbours)
+		stats.TotalNeighbours,
+		"Up:",
+		stats.TotalNeighboursUp)
 
 	for _, rs := range stats.RouteServers {
 		log.Println("      -", rs.Name)
 		log.Println("        State:", rs.State)
 		log.Println("        UpdatedAt:", rs.UpdatedAt)
 		log.Println("        Neighbours:",
-			rs.Neighbours)
+			rs.Neighbours,
+			"Up:",
+			rs.NeighboursUp)
 	}
 }
@@ -32,6 +32,15 @@ func MemberOf(list []string, key string) bool {
 	return false
 }
 
+func MemberOfInt(list []int, key int) bool {
+	for _, v := range list {
+		if v == key {
+			return true
+		}
+	}
+	return false
+}
+
 /*
  Check if something could be a prefix
 */
@@ -24,9 +24,20 @@ import {PrimaryIndicator,
         RejectCandidateIndicator} from './flags'
 
 
-// Helper: Lookup value in route path
+// Helper: Lookup value in route path,
+// unknown paths are rendered blank.
 export const _lookup = (r, path) => {
-  return path.split(".").reduce((acc, elem) => acc[elem], r);
+  const value = path.split(".").reduce((acc, elem) => {
+    if (acc === undefined || acc === null) {
+      return undefined;
+    }
+    return acc[elem];
+  }, r);
+
+  if (value === undefined || value === null) {
+    return "";
+  }
+  return value;
 }
 
 
@@ -64,6 +64,17 @@ class Details extends React.Component {
           <td><b>{rsStatus.message}</b></td>
         </tr>
 
+        {rsStatus.router_id && rsStatus.router_id != "unknown" &&
+          <tr>
+            <td><i className="fa fa-id-card-o"></i></td>
+            <td>Router ID: <b>{rsStatus.router_id}</b></td>
+          </tr>}
+        {rsStatus.local_as > 0 &&
+          <tr>
+            <td><i className="fa fa-sitemap"></i></td>
+            <td>Local AS: <b>AS{rsStatus.local_as}</b></td>
+          </tr>}
+
         {cacheStatus}
         </tbody>
       </table>